@@ -0,0 +1,310 @@
+package beads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// BDStore is the Store backed by the bd CLI. RepoPath is the directory where
+// bd commands are executed (typically the monorepo root). CachePath, when
+// set, persists probed capabilities across invocations. Idempotent commands
+// are retried under Retry, or util.DefaultRetry when it is zero.
+type BDStore struct {
+	RepoPath  string
+	CachePath string
+	Retry     util.RetryPolicy
+}
+
+// runIdempotent runs a bd command that is safe to repeat, retrying lock
+// contention and timeouts. Create, delete, and comment writes use
+// util.RunInDir directly so a retry can never duplicate them.
+func (s BDStore) runIdempotent(ctx context.Context, args ...string) (util.CmdResult, error) {
+	policy := s.Retry
+	if policy.Attempts == 0 {
+		policy = util.DefaultRetry()
+	}
+	return util.RunRetry(ctx, policy, s.RepoPath, "bd", args...)
+}
+
+// bdFailed wraps a bd failure, adding ErrNotFound when bd reported a missing
+// bead so callers need not inspect stderr.
+func bdFailed(op string, err error) error {
+	if errors.Is(err, util.ErrNotFound) {
+		return fmt.Errorf("%s: %w: %w", op, ErrNotFound, err)
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// Init initializes the beads store in the repository.
+func (s BDStore) Init(ctx context.Context) error {
+	_, err := util.RunInDir(ctx, s.RepoPath, "bd", "init")
+	if err != nil {
+		return fmt.Errorf("bd init: %w", err)
+	}
+	return nil
+}
+
+// Create creates a new bead with the given parameters.
+func (s BDStore) Create(ctx context.Context, req CreateRequest) (Issue, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return Issue{}, fmt.Errorf("title is required")
+	}
+	args := []string{"create", "--json"}
+	if strings.TrimSpace(req.Type) != "" {
+		args = append(args, "-t", req.Type)
+	}
+	if strings.TrimSpace(req.Priority) != "" {
+		args = append(args, "-p", req.Priority)
+	}
+	if strings.TrimSpace(req.Description) != "" {
+		args = append(args, "--description", req.Description)
+	}
+	for _, dep := range req.Deps {
+		if strings.TrimSpace(dep) == "" {
+			continue
+		}
+		args = append(args, "--deps", dep)
+	}
+	args = append(args, req.Title)
+	res, err := util.RunInDir(ctx, s.RepoPath, "bd", args...)
+	if err != nil {
+		return Issue{}, fmt.Errorf("bd create: %w", err)
+	}
+	issue, err := parseIssue(res.Stdout)
+	if err != nil {
+		return Issue{}, fmt.Errorf("parsing bd create response: %w", err)
+	}
+	if strings.TrimSpace(req.Status) != "" {
+		updated, err := s.Update(ctx, issue.ID, UpdateRequest{Status: req.Status})
+		if err != nil {
+			return Issue{}, fmt.Errorf("setting status on %s: %w", issue.ID, err)
+		}
+		return updated, nil
+	}
+	return issue, nil
+}
+
+// Update changes the status and/or description of an existing bead.
+// Description changes fail with a *CapabilityError wrapping
+// ErrUpdateDescriptionUnsupported when the probed bd lacks --description.
+func (s BDStore) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	if strings.TrimSpace(req.Description) != "" {
+		caps, err := s.Capabilities(ctx)
+		if err != nil {
+			return Issue{}, err
+		}
+		if err := caps.Require(CapUpdateDescription); err != nil {
+			return Issue{}, err
+		}
+	}
+	args := []string{"update", id, "--json"}
+	if strings.TrimSpace(req.Status) != "" {
+		args = append(args, "--status", req.Status)
+	}
+	if strings.TrimSpace(req.Description) != "" {
+		args = append(args, "--description", req.Description)
+	}
+	res, err := s.runIdempotent(ctx, args...)
+	if err != nil {
+		return Issue{}, bdFailed(fmt.Sprintf("bd update %s", id), err)
+	}
+	issue, err := parseIssue(res.Stdout)
+	if err != nil {
+		return Issue{}, fmt.Errorf("parsing bd update response: %w", err)
+	}
+	return issue, nil
+}
+
+// Close closes a bead with configurable options.
+func (s BDStore) Close(ctx context.Context, id string, opts CloseOptions) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	args := []string{"close", id, "--json"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	if strings.TrimSpace(opts.Reason) != "" {
+		args = append(args, "--reason", opts.Reason)
+	}
+	res, err := s.runIdempotent(ctx, args...)
+	if err != nil {
+		return Issue{}, bdFailed(fmt.Sprintf("bd close %s", id), err)
+	}
+	issue, err := parseIssue(res.Stdout)
+	if err != nil {
+		return Issue{}, fmt.Errorf("parsing bd close response: %w", err)
+	}
+	return issue, nil
+}
+
+// Show retrieves a single bead by ID.
+func (s BDStore) Show(ctx context.Context, id string) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	res, err := s.runIdempotent(ctx, "show", id, "--json")
+	if err != nil {
+		return Issue{}, bdFailed(fmt.Sprintf("bd show %s", id), err)
+	}
+	issue, err := parseIssue(res.Stdout)
+	if err != nil {
+		return Issue{}, fmt.Errorf("parsing bd show response: %w", err)
+	}
+	return issue, nil
+}
+
+// List returns all beads in the repository.
+func (s BDStore) List(ctx context.Context) ([]Issue, error) {
+	res, err := s.runIdempotent(ctx, "list", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("bd list: %w", err)
+	}
+	issues, err := parseIssues(res.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bd list response: %w", err)
+	}
+	return issues, nil
+}
+
+// Ready returns beads that are ready to be worked on (no blocking dependencies).
+func (s BDStore) Ready(ctx context.Context) ([]Issue, error) {
+	res, err := s.runIdempotent(ctx, "ready", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("bd ready: %w", err)
+	}
+	issues, err := parseIssues(res.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bd ready response: %w", err)
+	}
+	return issues, nil
+}
+
+// DepAdd adds a dependency relationship between beads.
+func (s BDStore) DepAdd(ctx context.Context, id, dep string) error {
+	if strings.TrimSpace(id) == "" || strings.TrimSpace(dep) == "" {
+		return fmt.Errorf("id and dep required")
+	}
+	_, err := s.runIdempotent(ctx, "dep", "add", id, dep)
+	if err != nil {
+		return bdFailed(fmt.Sprintf("bd dep add %s %s", id, dep), err)
+	}
+	return nil
+}
+
+// AddComment appends a comment with `bd comments add`.
+func (s BDStore) AddComment(ctx context.Context, id, author, body string) (Comment, error) {
+	if err := s.requireComments(ctx); err != nil {
+		return Comment{}, err
+	}
+	args := []string{"comments", "add", id, body, "--json"}
+	if strings.TrimSpace(author) != "" {
+		args = append(args, "--author", author)
+	}
+	res, err := util.RunInDir(ctx, s.RepoPath, "bd", args...)
+	if err != nil {
+		return Comment{}, fmt.Errorf("bd comments add %s: %w", id, err)
+	}
+	comments, err := parseComments(res.Stdout)
+	if err != nil || len(comments) == 0 {
+		return Comment{IssueID: id, Author: author, Body: body}, nil
+	}
+	return comments[len(comments)-1], nil
+}
+
+// Comments lists a bead's thread with `bd comments`.
+func (s BDStore) Comments(ctx context.Context, id string) ([]Comment, error) {
+	if err := s.requireComments(ctx); err != nil {
+		return nil, err
+	}
+	res, err := s.runIdempotent(ctx, "comments", id, "--json")
+	if err != nil {
+		return nil, bdFailed(fmt.Sprintf("bd comments %s", id), err)
+	}
+	comments, err := parseComments(res.Stdout)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bd comments response: %w", err)
+	}
+	return comments, nil
+}
+
+func (s BDStore) requireComments(ctx context.Context) error {
+	caps, err := s.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+	return caps.Require(CapComments)
+}
+
+// bdComment mirrors bd's comment JSON, which uses numeric IDs and "text".
+type bdComment struct {
+	ID        json.Number `json:"id"`
+	IssueID   string      `json:"issue_id"`
+	Author    string      `json:"author"`
+	Text      string      `json:"text"`
+	CreatedAt string      `json:"created_at"`
+}
+
+func parseComments(raw string) ([]Comment, error) {
+	trim := strings.TrimSpace(raw)
+	if trim == "" || trim == "null" {
+		return nil, nil
+	}
+	var list []bdComment
+	if strings.HasPrefix(trim, "{") {
+		var one bdComment
+		if err := json.Unmarshal([]byte(trim), &one); err != nil {
+			return nil, err
+		}
+		list = []bdComment{one}
+	} else if err := json.Unmarshal([]byte(trim), &list); err != nil {
+		return nil, err
+	}
+	out := make([]Comment, 0, len(list))
+	for _, c := range list {
+		out = append(out, Comment{ID: c.ID.String(), IssueID: c.IssueID, Author: c.Author, Body: c.Text, CreatedAt: c.CreatedAt})
+	}
+	return out, nil
+}
+
+// Delete removes beads with the given IDs. Use DryRun to preview the operation.
+func (s BDStore) Delete(ctx context.Context, ids []string, opts DeleteOptions) (string, error) {
+	args := []string{"delete"}
+	for _, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			continue
+		}
+		args = append(args, id)
+	}
+	if len(args) == 1 {
+		return "", fmt.Errorf("id is required")
+	}
+	if opts.Cascade {
+		args = append(args, "--cascade")
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if opts.Hard {
+		args = append(args, "--hard")
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	if strings.TrimSpace(opts.Reason) != "" {
+		args = append(args, "--reason", opts.Reason)
+	}
+	res, err := util.RunInDir(ctx, s.RepoPath, "bd", args...)
+	if err != nil {
+		return "", fmt.Errorf("bd delete: %w", err)
+	}
+	return res.Stdout, nil
+}
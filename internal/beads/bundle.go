@@ -0,0 +1,185 @@
+package beads
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BundleFormat identifies a bead export bundle in its header record.
+const BundleFormat = "microforge-beads"
+
+// BundleVersion is the bundle layout written by WriteBundle.
+const BundleVersion = 1
+
+// BundleHeader describes where a bundle came from.
+type BundleHeader struct {
+	Format     string `json:"format"`
+	Version    int    `json:"version"`
+	Rig        string `json:"rig,omitempty"`
+	Selector   string `json:"selector,omitempty"`
+	ExportedAt string `json:"exported_at,omitempty"`
+}
+
+// BundleFile is a mail file that belongs to a bead, stored relative to the
+// owning cell's worktree.
+type BundleFile struct {
+	Bead    string `json:"bead"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Bundle is a self-contained set of beads and their mail files that can be
+// moved between rigs. On disk it is JSONL: a header record followed by one
+// record per issue and per file.
+type Bundle struct {
+	Header BundleHeader
+	Issues []Issue
+	Files  []BundleFile
+}
+
+type bundleRecord struct {
+	Header *BundleHeader `json:"header,omitempty"`
+	Issue  *Issue        `json:"issue,omitempty"`
+	File   *BundleFile   `json:"file,omitempty"`
+}
+
+// WriteBundle encodes b as JSONL.
+func WriteBundle(w io.Writer, b Bundle) error {
+	enc := json.NewEncoder(w)
+	header := b.Header
+	header.Format = BundleFormat
+	header.Version = BundleVersion
+	if err := enc.Encode(bundleRecord{Header: &header}); err != nil {
+		return err
+	}
+	for i := range b.Issues {
+		if err := enc.Encode(bundleRecord{Issue: &b.Issues[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range b.Files {
+		if err := enc.Encode(bundleRecord{File: &b.Files[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadBundle decodes a bundle written by WriteBundle.
+func ReadBundle(r io.Reader) (Bundle, error) {
+	var b Bundle
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var rec bundleRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return Bundle{}, fmt.Errorf("bundle line %d: %w", line, err)
+		}
+		switch {
+		case rec.Header != nil:
+			b.Header = *rec.Header
+		case rec.Issue != nil:
+			b.Issues = append(b.Issues, *rec.Issue)
+		case rec.File != nil:
+			b.Files = append(b.Files, *rec.File)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Bundle{}, err
+	}
+	if b.Header.Format != BundleFormat {
+		return Bundle{}, fmt.Errorf("not a bead bundle (format %q)", b.Header.Format)
+	}
+	if b.Header.Version > BundleVersion {
+		return Bundle{}, fmt.Errorf("bundle version %d is newer than supported version %d", b.Header.Version, BundleVersion)
+	}
+	return b, nil
+}
+
+// Closure returns the seed beads plus every bead that hangs off them through
+// parent or related edges (an epic's tasks, a task's assignments, an
+// assignment's mail), transitively. Events are left out. The result keeps
+// listing order.
+func Closure(issues []Issue, seeds []string) []Issue {
+	g := NewGraph(issues)
+	selected := map[string]bool{}
+	queue := make([]string, 0, len(seeds))
+	for _, id := range seeds {
+		if _, ok := g.Issue(id); ok && !selected[id] {
+			selected[id] = true
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.Incoming(id, EdgeParent, EdgeRelated) {
+			from, ok := g.Issue(edge.From)
+			if !ok || selected[from.ID] || strings.EqualFold(from.Type, "event") {
+				continue
+			}
+			selected[from.ID] = true
+			queue = append(queue, from.ID)
+		}
+	}
+	var out []Issue
+	for _, issue := range issues {
+		if selected[issue.ID] {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// RewriteRefs replaces references to old bead IDs with their new IDs in an
+// issue's title, front-matter, and deps. Body text is left alone. Deps whose
+// target is not in ids are returned unchanged.
+func RewriteRefs(issue Issue, ids map[string]string) Issue {
+	if len(ids) == 0 {
+		return issue
+	}
+	re := refPattern(ids)
+	replace := func(s string) string {
+		return re.ReplaceAllStringFunc(s, func(m string) string { return ids[m] })
+	}
+	issue.Title = replace(issue.Title)
+	if body := StripMeta(issue.Description); body != issue.Description {
+		front := issue.Description[:len(issue.Description)-len(body)]
+		issue.Description = replace(front) + body
+	}
+	deps := make([]string, 0, len(issue.Deps))
+	for _, dep := range issue.Deps {
+		kind, id := ParseDep(dep)
+		if next, ok := ids[id]; ok {
+			if strings.Contains(dep, ":") {
+				dep = FormatDep(kind, next)
+			} else {
+				dep = next
+			}
+		}
+		deps = append(deps, dep)
+	}
+	issue.Deps = deps
+	return issue
+}
+
+func refPattern(ids map[string]string) *regexp.Regexp {
+	keys := make([]string, 0, len(ids))
+	for id := range ids {
+		keys = append(keys, regexp.QuoteMeta(id))
+	}
+	// Longest first so "mf-1" never shadows "mf-10".
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	return regexp.MustCompile(`\b(?:` + strings.Join(keys, "|") + `)\b`)
+}
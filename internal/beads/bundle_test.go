@@ -0,0 +1,39 @@
+package beads
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBundleRoundTripAndRewrite(t *testing.T) {
+	issue := Issue{
+		ID:          "mf-10",
+		Title:       "Assignment mf-1",
+		Type:        "assignment",
+		Description: RenderMeta(Meta{Inbox: "mail/inbox/mf-1.md", DependsOn: "mf-10"}) + "\n\nmf-1 stays in the body",
+		Deps:        []string{"related:mf-1", "mf-2"},
+	}
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, Bundle{Issues: []Issue{issue}, Files: []BundleFile{{Bead: "mf-10", Path: "mail/inbox/mf-1.md", Content: "hi"}}}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	b, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(b.Issues) != 1 || len(b.Files) != 1 || b.Header.Version != BundleVersion {
+		t.Fatalf("unexpected bundle: %+v", b)
+	}
+
+	got := RewriteRefs(b.Issues[0], map[string]string{"mf-1": "x-7", "mf-10": "x-8"})
+	meta := ParseMeta(got.Description)
+	if got.Title != "Assignment x-7" || meta.Inbox != "mail/inbox/x-7.md" || meta.DependsOn != "x-8" {
+		t.Fatalf("refs not rewritten: %+v", got)
+	}
+	if StripMeta(got.Description) != StripMeta(issue.Description) {
+		t.Fatalf("body should be untouched: %q", got.Description)
+	}
+	if got.Deps[0] != "related:x-7" || got.Deps[1] != "mf-2" {
+		t.Fatalf("deps not rewritten: %v", got.Deps)
+	}
+}
@@ -0,0 +1,355 @@
+package beads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// Capability names accepted by Capabilities.Require.
+const (
+	CapUpdateDescription = "update_description"
+	CapComments          = "comments"
+)
+
+// List output shapes reported in Capabilities.ListShape.
+const (
+	ListShapeArray  = "array"
+	ListShapeObject = "object"
+)
+
+// builtinTypes are the issue types bd accepts without types.custom.
+var builtinTypes = []string{"bug", "chore", "epic", "feature", "task"}
+
+// Capabilities describes what a backend supports. Callers pick code paths
+// from it instead of trying an operation and parsing the failure.
+// CustomTypes is nil when the backend accepts any type.
+type Capabilities struct {
+	Backend           string   `json:"backend"`
+	Version           string   `json:"version,omitempty"`
+	UpdateDescription bool     `json:"update_description"`
+	Comments          bool     `json:"comments"`
+	CustomTypes       []string `json:"custom_types,omitempty"`
+	ListShape         string   `json:"list_shape,omitempty"`
+	ProbedAt          string   `json:"probed_at,omitempty"`
+}
+
+// Prober is implemented by stores that can report their capabilities.
+// Capabilities may answer from a cache; Probe always asks the backend.
+type Prober interface {
+	Capabilities(ctx context.Context) (Capabilities, error)
+	Probe(ctx context.Context) (Capabilities, error)
+}
+
+// CapabilityError reports that the backend lacks a capability an operation
+// needs. It unwraps to the matching sentinel so errors.Is keeps working.
+type CapabilityError struct {
+	Backend    string
+	Version    string
+	Capability string
+}
+
+func (e *CapabilityError) Error() string {
+	backend := e.Backend
+	if e.Version != "" {
+		backend += " " + e.Version
+	}
+	switch e.Capability {
+	case CapUpdateDescription:
+		return fmt.Sprintf("%s cannot update bead descriptions (bd update has no --description); upgrade bd or switch the rig to the jsonl backend", backend)
+	case CapComments:
+		return fmt.Sprintf("%s has no comment threads (bd comments is missing); upgrade bd", backend)
+	default:
+		return fmt.Sprintf("%s does not support %s", backend, e.Capability)
+	}
+}
+
+func (e *CapabilityError) Unwrap() error {
+	switch e.Capability {
+	case CapUpdateDescription:
+		return ErrUpdateDescriptionUnsupported
+	case CapComments:
+		return ErrCommentsUnsupported
+	default:
+		return nil
+	}
+}
+
+// Require returns a *CapabilityError when the named capability is missing.
+func (c Capabilities) Require(name string) error {
+	ok := false
+	switch name {
+	case CapUpdateDescription:
+		ok = c.UpdateDescription
+	case CapComments:
+		ok = c.Comments
+	}
+	if ok {
+		return nil
+	}
+	return &CapabilityError{Backend: c.Backend, Version: c.Version, Capability: name}
+}
+
+// SupportsType reports whether beads of type t can be created.
+func (c Capabilities) SupportsType(t string) bool {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" || c.CustomTypes == nil {
+		return true
+	}
+	for _, known := range builtinTypes {
+		if t == known {
+			return true
+		}
+	}
+	for _, known := range c.CustomTypes {
+		if t == strings.ToLower(known) {
+			return true
+		}
+	}
+	return false
+}
+
+// capsMemo holds the first successful Capabilities answer for a Client.
+type capsMemo struct {
+	sync.Mutex
+	caps *Capabilities
+}
+
+// MemoizeCapabilities returns c with Capabilities answered from its first
+// successful probe. Commands build one Client per invocation, so creates and
+// other capability checks reach the backend and types.custom once per command.
+func (c Client) MemoizeCapabilities() Client {
+	c.caps = &capsMemo{}
+	return c
+}
+
+// Capabilities reports what the backend supports.
+func (c Client) Capabilities(ctx context.Context) (Capabilities, error) {
+	if c.caps == nil {
+		return storeCapabilities(ctx, c.store(), false)
+	}
+	c.caps.Lock()
+	defer c.caps.Unlock()
+	if c.caps.caps != nil {
+		return *c.caps.caps, nil
+	}
+	caps, err := storeCapabilities(ctx, c.store(), false)
+	if err == nil {
+		c.caps.caps = &caps
+	}
+	return caps, err
+}
+
+// ProbeCapabilities re-probes the backend, bypassing any cache.
+func (c Client) ProbeCapabilities(ctx context.Context) (Capabilities, error) {
+	caps, err := storeCapabilities(ctx, c.store(), true)
+	if err == nil && c.caps != nil {
+		c.caps.Lock()
+		c.caps.caps = &caps
+		c.caps.Unlock()
+	}
+	return caps, err
+}
+
+// Require returns an error when the backend lacks the named capability or
+// cannot be probed.
+func (c Client) Require(ctx context.Context, name string) error {
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		return err
+	}
+	return caps.Require(name)
+}
+
+func storeCapabilities(ctx context.Context, s Store, refresh bool) (Capabilities, error) {
+	p, ok := s.(Prober)
+	if !ok {
+		_, comments := s.(Commenter)
+		return Capabilities{Backend: fmt.Sprintf("%T", s), UpdateDescription: true, Comments: comments}, nil
+	}
+	if refresh {
+		return p.Probe(ctx)
+	}
+	return p.Capabilities(ctx)
+}
+
+// Capabilities reports the FileStore's fixed feature set.
+func (s *FileStore) Capabilities(ctx context.Context) (Capabilities, error) {
+	return Capabilities{Backend: BackendJSONL, UpdateDescription: true, Comments: true, ListShape: ListShapeArray}, nil
+}
+
+// Probe is Capabilities; there is nothing to probe.
+func (s *FileStore) Probe(ctx context.Context) (Capabilities, error) {
+	return s.Capabilities(ctx)
+}
+
+// bdProbes memoizes probe results per repo for the life of the process.
+var bdProbes = struct {
+	sync.Mutex
+	m map[string]Capabilities
+}{m: map[string]Capabilities{}}
+
+// Capabilities returns the probed bd feature set. Results are memoized per
+// repo and, when CachePath is set, cached on disk until bd's version
+// changes. Custom types are always re-read from .beads/config.yaml.
+func (s BDStore) Capabilities(ctx context.Context) (Capabilities, error) {
+	bdProbes.Lock()
+	caps, ok := bdProbes.m[s.RepoPath]
+	bdProbes.Unlock()
+	if !ok {
+		version, err := s.version(ctx)
+		if err != nil {
+			return Capabilities{Backend: BackendBD}, err
+		}
+		if cached, err := loadCapabilities(s.CachePath); err == nil && cached.Version == version {
+			caps = cached
+		} else if caps, err = s.probe(ctx, version); err != nil {
+			return caps, err
+		}
+		bdProbes.Lock()
+		bdProbes.m[s.RepoPath] = caps
+		bdProbes.Unlock()
+	}
+	caps.CustomTypes = readCustomTypes(s.RepoPath)
+	return caps, nil
+}
+
+// Probe runs every bd probe and refreshes both caches.
+func (s BDStore) Probe(ctx context.Context) (Capabilities, error) {
+	version, err := s.version(ctx)
+	if err != nil {
+		return Capabilities{Backend: BackendBD}, err
+	}
+	caps, err := s.probe(ctx, version)
+	if err != nil {
+		return caps, err
+	}
+	bdProbes.Lock()
+	bdProbes.m[s.RepoPath] = caps
+	bdProbes.Unlock()
+	caps.CustomTypes = readCustomTypes(s.RepoPath)
+	return caps, nil
+}
+
+func (s BDStore) version(ctx context.Context) (string, error) {
+	res, err := s.runIdempotent(ctx, "version")
+	if err != nil {
+		return "", fmt.Errorf("probing bd version: %w", err)
+	}
+	return parseBDVersion(res.Stdout), nil
+}
+
+// probe asks bd for its flags and list shape. A help command that fails
+// counts as the feature being absent.
+func (s BDStore) probe(ctx context.Context, version string) (Capabilities, error) {
+	caps := Capabilities{Backend: BackendBD, Version: version, ProbedAt: time.Now().UTC().Format(time.RFC3339)}
+	if res, err := util.RunInDir(ctx, s.RepoPath, "bd", "update", "--help"); err == nil {
+		caps.UpdateDescription = strings.Contains(res.Stdout+res.Stderr, "--description")
+	}
+	if _, err := util.RunInDir(ctx, s.RepoPath, "bd", "comments", "--help"); err == nil {
+		caps.Comments = true
+	}
+	// One bead is enough to see the shape; bd without --limit falls back to
+	// a full listing.
+	res, err := util.RunInDir(ctx, s.RepoPath, "bd", "list", "--json", "--limit", "1")
+	if errors.Is(err, util.ErrUnsupported) {
+		res, err = util.RunInDir(ctx, s.RepoPath, "bd", "list", "--json")
+	}
+	if err == nil {
+		caps.ListShape = listShape(res.Stdout)
+	}
+	if err := saveCapabilities(s.CachePath, caps); err != nil {
+		return caps, fmt.Errorf("caching bd capabilities: %w", err)
+	}
+	return caps, nil
+}
+
+// parseBDVersion extracts the version token from `bd version` output such as
+// "bd version 0.21.4 (abc123)".
+func parseBDVersion(out string) string {
+	fields := strings.Fields(out)
+	for i, f := range fields {
+		if f == "version" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	if len(fields) > 0 {
+		return fields[len(fields)-1]
+	}
+	return ""
+}
+
+func listShape(out string) string {
+	switch trim := strings.TrimSpace(out); {
+	case strings.HasPrefix(trim, "["):
+		return ListShapeArray
+	case strings.HasPrefix(trim, "{"):
+		return ListShapeObject
+	default:
+		return ""
+	}
+}
+
+// readCustomTypes returns types.custom from .beads/config.yaml, or nil when
+// the file does not configure any.
+func readCustomTypes(repo string) []string {
+	b, err := os.ReadFile(filepath.Join(repo, ".beads", "config.yaml"))
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "types.custom:") {
+			continue
+		}
+		raw := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "types.custom:")), "\"'")
+		types := []string{}
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		sort.Strings(types)
+		return types
+	}
+	return nil
+}
+
+func loadCapabilities(path string) (Capabilities, error) {
+	if strings.TrimSpace(path) == "" {
+		return Capabilities{}, errors.New("no capability cache")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	var caps Capabilities
+	if err := json.Unmarshal(b, &caps); err != nil {
+		return Capabilities{}, err
+	}
+	return caps, nil
+}
+
+func saveCapabilities(path string, caps Capabilities) error {
+	if strings.TrimSpace(path) == "" {
+		return nil
+	}
+	caps.CustomTypes = nil
+	b, err := json.MarshalIndent(caps, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,111 @@
+package beads
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCapabilitiesRequireWrapsSentinels(t *testing.T) {
+	caps := Capabilities{Backend: BackendBD, Version: "0.9.0"}
+	err := caps.Require(CapUpdateDescription)
+	if !errors.Is(err, ErrUpdateDescriptionUnsupported) {
+		t.Fatalf("expected ErrUpdateDescriptionUnsupported, got %v", err)
+	}
+	if !errors.Is(caps.Require(CapComments), ErrCommentsUnsupported) {
+		t.Fatalf("expected ErrCommentsUnsupported")
+	}
+	caps.UpdateDescription = true
+	if err := caps.Require(CapUpdateDescription); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCapabilitiesSupportsType(t *testing.T) {
+	repo := t.TempDir()
+	if got := readCustomTypes(repo); got != nil {
+		t.Fatalf("expected nil types without config, got %v", got)
+	}
+	if err := os.MkdirAll(filepath.Join(repo, ".beads"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".beads", "config.yaml"), []byte("types.custom: \"request,assignment\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	caps := Capabilities{CustomTypes: readCustomTypes(repo)}
+	for typ, want := range map[string]bool{"assignment": true, "request": true, "task": true, "": true, "observation": false} {
+		if got := caps.SupportsType(typ); got != want {
+			t.Fatalf("SupportsType(%q) = %v, want %v", typ, got, want)
+		}
+	}
+}
+
+func TestBDStoreUpdateUsesProbedCapabilities(t *testing.T) {
+	repo := t.TempDir()
+	bdProbes.Lock()
+	bdProbes.m[repo] = Capabilities{Backend: BackendBD, Version: "0.1.0"}
+	bdProbes.Unlock()
+	defer func() {
+		bdProbes.Lock()
+		delete(bdProbes.m, repo)
+		bdProbes.Unlock()
+	}()
+	_, err := BDStore{RepoPath: repo}.Update(nil, "bd-1", UpdateRequest{Description: "x"})
+	var capErr *CapabilityError
+	if !errors.As(err, &capErr) || capErr.Capability != CapUpdateDescription {
+		t.Fatalf("expected capability error, got %v", err)
+	}
+}
+
+func TestParseBDVersionAndListShape(t *testing.T) {
+	if got := parseBDVersion("bd version 0.21.4 (abc123)\n"); got != "0.21.4" {
+		t.Fatalf("version = %q", got)
+	}
+	if listShape(" [{}]") != ListShapeArray || listShape(`{"issues":[]}`) != ListShapeObject || listShape("") != "" {
+		t.Fatalf("unexpected list shapes")
+	}
+}
+
+func TestMemoizedCapabilitiesProbeOnceWithBoundedList(t *testing.T) {
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "calls")
+	script := "#!/bin/sh\necho \"$*\" >> " + calls + "\ncase \"$1\" in version) echo 'bd version 9.9.9';; list) echo '[]';; esac\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	repo := t.TempDir()
+	defer func() {
+		bdProbes.Lock()
+		delete(bdProbes.m, repo)
+		bdProbes.Unlock()
+	}()
+	client := Client{RepoPath: repo, Store: BDStore{RepoPath: repo}}.MemoizeCapabilities()
+	for i := 0; i < 3; i++ {
+		caps, err := client.Capabilities(nil)
+		if err != nil || caps.Version != "9.9.9" || caps.ListShape != ListShapeArray || caps.CustomTypes != nil {
+			t.Fatalf("unexpected capabilities %+v (%v)", caps, err)
+		}
+		if i == 0 {
+			// Later checks in the same command answer from the memo, not config.yaml.
+			if err := os.MkdirAll(filepath.Join(repo, ".beads"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(repo, ".beads", "config.yaml"), []byte("types.custom: \"task\"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if caps, _ := (Client{RepoPath: repo, Store: BDStore{RepoPath: repo}}).Capabilities(nil); len(caps.CustomTypes) != 1 {
+		t.Fatalf("expected an unmemoized client to re-read config.yaml, got %+v", caps)
+	}
+	b, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "version\nupdate --help\ncomments --help\nlist --json --limit 1\n"
+	if string(b) != want {
+		t.Fatalf("expected one bounded probe, got:\n%s", b)
+	}
+}
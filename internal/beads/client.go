@@ -1,5 +1,7 @@
-// Package beads provides a client wrapper for the bd (Beads) CLI issue tracker.
+// Package beads provides a client for the Beads issue tracker.
 // Beads is the task/request/observation tracking system used by Microforge agents.
+// The Client delegates to a Store backend: the bd CLI by default, or an
+// embedded JSONL file store selected from rig.json.
 package beads
 
 import (
@@ -8,14 +10,21 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-
-	"github.com/example/microforge/internal/util"
 )
 
-// Client wraps the bd CLI for issue operations. RepoPath is the directory
-// where bd commands are executed (typically the monorepo root).
+// Client is the entry point for issue operations. RepoPath is the monorepo
+// root; Store selects the backend and defaults to the bd CLI run in RepoPath.
+// When HistoryLog is set, every status change made through the Client is
+// recorded there with Actor as the author. Failures collects best-effort
+// writes (TryUpdate, TryClose) that did not land.
 type Client struct {
-	RepoPath string
+	RepoPath   string
+	Store      Store
+	HistoryLog *HistoryLog
+	Failures   *FailureLog
+	Actor      string
+
+	caps *capsMemo
 }
 
 // DeleteOptions controls the behavior of the Delete operation.
@@ -27,22 +36,25 @@ type DeleteOptions struct {
 	Reason  string
 }
 
-// CloseOptions controls the behavior of the Close operation.
+// CloseOptions controls the behavior of the Close operation. From is the
+// status the caller last read, as in UpdateRequest.
 type CloseOptions struct {
 	Force  bool
 	Reason string
+	From   string
 }
 
 // Issue represents a bead (task, assignment, event, etc.) in the tracker.
 type Issue struct {
-	ID          string
-	Title       string
-	Status      string
-	Type        string
-	Priority    string
-	Description string
-	Deps        []string
-	CreatedAt   string
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Status      string   `json:"status"`
+	Type        string   `json:"type"`
+	Priority    string   `json:"priority,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Deps        []string `json:"deps,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
 }
 
 // CreateRequest contains fields for creating a new bead.
@@ -55,100 +67,88 @@ type CreateRequest struct {
 	Deps        []string
 }
 
-// ErrUpdateDescriptionUnsupported is returned when the bd CLI doesn't support --description flag.
+// ErrUpdateDescriptionUnsupported is wrapped by the *CapabilityError returned
+// when the bd CLI doesn't support --description.
 var ErrUpdateDescriptionUnsupported = errors.New("bd update does not support --description")
 
-// Init initializes the beads store in the repository.
-func (c Client) Init(ctx context.Context) error {
-	_, err := util.RunInDir(ctx, c.RepoPath, "bd", "init")
-	if err != nil {
-		return fmt.Errorf("bd init: %w", err)
+// ErrNotFound is returned when a bead ID does not exist in the store.
+var ErrNotFound = errors.New("issue not found")
+
+func (c Client) store() Store {
+	if c.Store != nil {
+		return c.Store
 	}
-	return nil
+	return BDStore{RepoPath: c.RepoPath}
 }
 
-// Create creates a new bead with the given parameters.
-func (c Client) Create(ctx context.Context, req CreateRequest) (Issue, error) {
-	if strings.TrimSpace(req.Title) == "" {
-		return Issue{}, fmt.Errorf("title is required")
-	}
-	args := []string{"create", "--json"}
-	if strings.TrimSpace(req.Type) != "" {
-		args = append(args, "-t", req.Type)
-	}
-	if strings.TrimSpace(req.Priority) != "" {
-		args = append(args, "-p", req.Priority)
+// Snapshot returns an indexed view of every bead. When the Store caches
+// listings (CachedStore) the shared snapshot is returned; otherwise a fresh
+// one is built from List.
+func (c Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	if cached, ok := c.store().(*CachedStore); ok {
+		return cached.Snapshot(ctx)
+	}
+	issues, err := c.List(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if strings.TrimSpace(req.Description) != "" {
-		args = append(args, "--description", req.Description)
+	return NewSnapshot(issues), nil
+}
+
+// Invalidate drops any cached snapshot so the next read hits the backend.
+func (c Client) Invalidate() {
+	if cached, ok := c.store().(*CachedStore); ok {
+		cached.Invalidate()
 	}
-	for _, dep := range req.Deps {
-		if strings.TrimSpace(dep) == "" {
-			continue
+}
+
+// Init initializes the beads store in the repository.
+func (c Client) Init(ctx context.Context) error {
+	return c.store().Init(ctx)
+}
+
+// Create creates a new bead with the given parameters. Types the backend
+// is not configured for are rejected before the write; use
+// MemoizeCapabilities to check them once per command rather than per create.
+func (c Client) Create(ctx context.Context, req CreateRequest) (Issue, error) {
+	if caps, err := c.Capabilities(ctx); err == nil && !caps.SupportsType(req.Type) {
+		return Issue{}, fmt.Errorf("bead type %q is not configured in %s types.custom; run `mforge migrate beads` to register microforge types", req.Type, caps.Backend)
+	}
+	issue, err := c.store().Create(ctx, req)
+	// Events are created often and never change status, so they have no
+	// history worth keeping.
+	if err == nil && !strings.EqualFold(req.Type, "event") {
+		status := issue.Status
+		if strings.TrimSpace(status) == "" {
+			status = "open"
 		}
-		args = append(args, "--deps", dep)
-	}
-	args = append(args, req.Title)
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", args...)
-	if err != nil {
-		return Issue{}, fmt.Errorf("bd create: %w", err)
-	}
-	issue, err := parseIssue(res.Stdout)
-	if err != nil {
-		return Issue{}, fmt.Errorf("parsing bd create response: %w", err)
+		c.recordTransition(issue.ID, "", status, "created")
 	}
+	return issue, err
+}
+
+// Update applies several field changes to a bead in one backend write.
+func (c Client) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	from := ""
 	if strings.TrimSpace(req.Status) != "" {
-		updated, err := c.UpdateStatus(ctx, issue.ID, req.Status)
-		if err != nil {
-			return Issue{}, fmt.Errorf("setting status on %s: %w", issue.ID, err)
-		}
-		return updated, nil
+		from = c.priorStatus(ctx, id, req.From)
+	}
+	issue, err := c.store().Update(ctx, id, req)
+	if err == nil && strings.TrimSpace(req.Status) != "" {
+		c.recordTransition(id, from, req.Status, req.Reason)
 	}
-	return issue, nil
+	return issue, err
 }
 
 // UpdateStatus changes the status of an existing bead.
 func (c Client) UpdateStatus(ctx context.Context, id, status string) (Issue, error) {
-	if strings.TrimSpace(id) == "" {
-		return Issue{}, fmt.Errorf("id is required")
-	}
-	args := []string{"update", id, "--json"}
-	if strings.TrimSpace(status) != "" {
-		args = append(args, "--status", status)
-	}
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", args...)
-	if err != nil {
-		return Issue{}, fmt.Errorf("bd update %s: %w", id, err)
-	}
-	issue, err := parseIssue(res.Stdout)
-	if err != nil {
-		return Issue{}, fmt.Errorf("parsing bd update response: %w", err)
-	}
-	return issue, nil
+	return c.Update(ctx, id, UpdateRequest{Status: status})
 }
 
-// UpdateDescription updates the description of an existing bead.
-// Returns ErrUpdateDescriptionUnsupported if the bd CLI doesn't support this operation.
+// UpdateDescription updates the description of an existing bead. Callers
+// with a fallback should check Require(ctx, CapUpdateDescription) first.
 func (c Client) UpdateDescription(ctx context.Context, id, description string) (Issue, error) {
-	if strings.TrimSpace(id) == "" {
-		return Issue{}, fmt.Errorf("id is required")
-	}
-	args := []string{"update", id, "--json"}
-	if strings.TrimSpace(description) != "" {
-		args = append(args, "--description", description)
-	}
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", args...)
-	if err != nil {
-		if strings.Contains(err.Error(), "unknown flag") && strings.Contains(err.Error(), "--description") {
-			return Issue{}, ErrUpdateDescriptionUnsupported
-		}
-		return Issue{}, fmt.Errorf("bd update %s description: %w", id, err)
-	}
-	issue, err := parseIssue(res.Stdout)
-	if err != nil {
-		return Issue{}, fmt.Errorf("parsing bd update response: %w", err)
-	}
-	return issue, nil
+	return c.store().Update(ctx, id, UpdateRequest{Description: description})
 }
 
 // Close closes a bead with an optional reason.
@@ -158,79 +158,61 @@ func (c Client) Close(ctx context.Context, id, reason string) (Issue, error) {
 
 // CloseWithOptions closes a bead with configurable options.
 func (c Client) CloseWithOptions(ctx context.Context, id string, opts CloseOptions) (Issue, error) {
-	if strings.TrimSpace(id) == "" {
-		return Issue{}, fmt.Errorf("id is required")
-	}
-	args := []string{"close", id, "--json"}
-	if opts.Force {
-		args = append(args, "--force")
-	}
-	if strings.TrimSpace(opts.Reason) != "" {
-		args = append(args, "--reason", opts.Reason)
-	}
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", args...)
-	if err != nil {
-		return Issue{}, fmt.Errorf("bd close %s: %w", id, err)
-	}
-	issue, err := parseIssue(res.Stdout)
-	if err != nil {
-		return Issue{}, fmt.Errorf("parsing bd close response: %w", err)
+	from := c.priorStatus(ctx, id, opts.From)
+	issue, err := c.store().Close(ctx, id, opts)
+	if err == nil {
+		c.recordTransition(id, from, "closed", opts.Reason)
 	}
-	return issue, nil
+	return issue, err
 }
 
 // Show retrieves a single bead by ID.
 func (c Client) Show(ctx context.Context, id string) (Issue, error) {
-	if strings.TrimSpace(id) == "" {
-		return Issue{}, fmt.Errorf("id is required")
-	}
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", "show", id, "--json")
-	if err != nil {
-		return Issue{}, fmt.Errorf("bd show %s: %w", id, err)
-	}
-	issue, err := parseIssue(res.Stdout)
-	if err != nil {
-		return Issue{}, fmt.Errorf("parsing bd show response: %w", err)
-	}
-	return issue, nil
+	return c.store().Show(ctx, id)
 }
 
 // List returns all beads in the repository.
 func (c Client) List(ctx context.Context) ([]Issue, error) {
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", "list", "--json")
-	if err != nil {
-		return nil, fmt.Errorf("bd list: %w", err)
-	}
-	issues, err := parseIssues(res.Stdout)
-	if err != nil {
-		return nil, fmt.Errorf("parsing bd list response: %w", err)
-	}
-	return issues, nil
+	return c.store().List(ctx)
 }
 
-// Ready returns beads that are ready to be worked on (no blocking dependencies).
+// Ready returns beads that are ready to be worked on: the backend's ready
+// list less any bead the dependency graph still holds back, so review edges
+// block on every backend the way Graph.Blockers says. The graph comes from
+// the store's snapshot, and is skipped for FileStore, whose Ready already
+// applies it.
 func (c Client) Ready(ctx context.Context) ([]Issue, error) {
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", "ready", "--json")
-	if err != nil {
-		return nil, fmt.Errorf("bd ready: %w", err)
+	ready, err := c.store().Ready(ctx)
+	if err != nil || readyAppliesGraph(c.store()) {
+		return ready, err
 	}
-	issues, err := parseIssues(res.Stdout)
+	snap, err := c.Snapshot(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("parsing bd ready response: %w", err)
+		return nil, err
 	}
-	return issues, nil
+	graph := NewGraph(snap.Issues())
+	out := ready[:0:0]
+	for _, issue := range ready {
+		if len(graph.Blockers(issue.ID)) == 0 {
+			out = append(out, issue)
+		}
+	}
+	return out, nil
+}
+
+// readyAppliesGraph reports whether s's Ready already leaves out every bead
+// Graph.Blockers holds back.
+func readyAppliesGraph(s Store) bool {
+	if cached, ok := s.(*CachedStore); ok {
+		s = cached.inner
+	}
+	_, ok := s.(*FileStore)
+	return ok
 }
 
 // DepAdd adds a dependency relationship between beads.
 func (c Client) DepAdd(ctx context.Context, id string, dep string) error {
-	if strings.TrimSpace(id) == "" || strings.TrimSpace(dep) == "" {
-		return fmt.Errorf("id and dep required")
-	}
-	_, err := util.RunInDir(ctx, c.RepoPath, "bd", "dep", "add", id, dep)
-	if err != nil {
-		return fmt.Errorf("bd dep add %s %s: %w", id, dep, err)
-	}
-	return nil
+	return c.store().DepAdd(ctx, id, dep)
 }
 
 // Delete removes beads with the given IDs. Use DryRun to preview the operation.
@@ -238,36 +220,7 @@ func (c Client) Delete(ctx context.Context, ids []string, opts DeleteOptions) (s
 	if len(ids) == 0 {
 		return "", fmt.Errorf("id is required")
 	}
-	args := []string{"delete"}
-	for _, id := range ids {
-		if strings.TrimSpace(id) == "" {
-			continue
-		}
-		args = append(args, id)
-	}
-	if len(args) == 1 {
-		return "", fmt.Errorf("id is required")
-	}
-	if opts.Cascade {
-		args = append(args, "--cascade")
-	}
-	if opts.DryRun {
-		args = append(args, "--dry-run")
-	}
-	if opts.Hard {
-		args = append(args, "--hard")
-	}
-	if opts.Force {
-		args = append(args, "--force")
-	}
-	if strings.TrimSpace(opts.Reason) != "" {
-		args = append(args, "--reason", opts.Reason)
-	}
-	res, err := util.RunInDir(ctx, c.RepoPath, "bd", args...)
-	if err != nil {
-		return "", fmt.Errorf("bd delete: %w", err)
-	}
-	return res.Stdout, nil
+	return c.store().Delete(ctx, ids, opts)
 }
 
 func parseIssue(raw string) (Issue, error) {
@@ -367,6 +320,9 @@ func decodeIssueMap(m map[string]any) Issue {
 	if v, ok := m["created"].(string); ok && issue.CreatedAt == "" {
 		issue.CreatedAt = v
 	}
+	if v, ok := m["updated_at"].(string); ok {
+		issue.UpdatedAt = v
+	}
 	if depsRaw, ok := m["deps"].([]any); ok {
 		for _, depAny := range depsRaw {
 			if depStr, ok := depAny.(string); ok {
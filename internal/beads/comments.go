@@ -0,0 +1,67 @@
+package beads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Comment is one entry in a bead's append-only discussion thread. Author is
+// "cell/role" for agents or "human:<name>" for people.
+type Comment struct {
+	ID        string `json:"id"`
+	IssueID   string `json:"issue_id"`
+	Author    string `json:"author"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Commenter is implemented by stores that keep comment threads.
+type Commenter interface {
+	AddComment(ctx context.Context, id, author, body string) (Comment, error)
+	Comments(ctx context.Context, id string) ([]Comment, error)
+}
+
+// ErrCommentsUnsupported is returned when the backend has no comment API.
+var ErrCommentsUnsupported = errors.New("beads backend does not support comments")
+
+// AddComment appends a comment to a bead's thread.
+func (c Client) AddComment(ctx context.Context, id, author, body string) (Comment, error) {
+	if strings.TrimSpace(id) == "" {
+		return Comment{}, fmt.Errorf("id is required")
+	}
+	if strings.TrimSpace(body) == "" {
+		return Comment{}, fmt.Errorf("comment body is required")
+	}
+	cm, ok := c.store().(Commenter)
+	if !ok {
+		return Comment{}, ErrCommentsUnsupported
+	}
+	return cm.AddComment(ctx, id, author, body)
+}
+
+// Comments returns a bead's thread, oldest first.
+func (c Client) Comments(ctx context.Context, id string) ([]Comment, error) {
+	cm, ok := c.store().(Commenter)
+	if !ok {
+		return nil, ErrCommentsUnsupported
+	}
+	return cm.Comments(ctx, id)
+}
+
+// FormatThread renders comments as markdown for mail and terminal output.
+func FormatThread(comments []Comment) string {
+	var b strings.Builder
+	for i, c := range comments {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		author := strings.TrimSpace(c.Author)
+		if author == "" {
+			author = "unknown"
+		}
+		fmt.Fprintf(&b, "**%s** on %s (%s):\n%s\n", author, c.IssueID, c.CreatedAt, strings.TrimSpace(c.Body))
+	}
+	return b.String()
+}
@@ -0,0 +1,323 @@
+package beads
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// Failed write operations recorded in a FailureLog.
+const (
+	FailedUpdate = "update"
+	FailedClose  = "close"
+)
+
+// MaxReplayAttempts is how many replays a failure gets before it is marked
+// FailureDead and left in the log for an operator instead of retried.
+const MaxReplayAttempts = 5
+
+// FailureDead is the State of a failure that ReplayFailures gave up on.
+const FailureDead = "dead"
+
+// Failure is a best-effort write that failed and was swallowed by its caller.
+// Kind is "lock_contention", "timeout", or empty when unclassified. State is
+// empty while the failure is pending and FailureDead once replay gave up.
+type Failure struct {
+	Op          string `json:"op"`
+	ID          string `json:"id"`
+	Status      string `json:"status,omitempty"`
+	Description string `json:"description,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	Actor       string `json:"actor,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	Error       string `json:"error"`
+	At          string `json:"at"`
+	Attempts    int    `json:"attempts,omitempty"`
+	State       string `json:"state,omitempty"`
+}
+
+// FailureLog is a JSONL file of swallowed write failures. Writers append;
+// ReplayFailures drains it under a lock file next to the log.
+type FailureLog struct {
+	Path string
+}
+
+// ReplayResult counts what ReplayFailures did with each logged failure.
+// DeadLettered counts failures given up on during this replay.
+type ReplayResult struct {
+	Replayed     int
+	Superseded   int
+	Remaining    int
+	DeadLettered int
+}
+
+// Append records a failure.
+func (l *FailureLog) Append(f Failure) error {
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return l.appendLocked([]Failure{f})
+}
+
+// Load returns the logged failures, oldest first.
+func (l *FailureLog) Load() ([]Failure, error) {
+	b, err := os.ReadFile(l.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var out []Failure
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var f Failure
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func (l *FailureLog) lock() (func(), error) {
+	if err := util.EnsureDir(filepath.Dir(l.Path)); err != nil {
+		return nil, err
+	}
+	return util.LockFile(nil, l.Path+".lock")
+}
+
+func (l *FailureLog) appendLocked(list []Failure) error {
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, item := range list {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending returns the failures still awaiting replay, leaving the log as is.
+func (l *FailureLog) pending() ([]Failure, error) {
+	unlock, err := l.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	list, err := l.Load()
+	if err != nil {
+		return nil, err
+	}
+	var out []Failure
+	for _, f := range list {
+		if f.State != FailureDead {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// settle drops the replayed entries from the log and appends again in their
+// place. Entries appended since replayed was read are kept. The log is
+// rewritten to a temp file and renamed over, so a crash never loses it.
+func (l *FailureLog) settle(replayed, again []Failure) error {
+	unlock, err := l.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	current, err := l.Load()
+	if err != nil {
+		return err
+	}
+	done := map[Failure]int{}
+	for _, f := range replayed {
+		done[f]++
+	}
+	var keep []Failure
+	for _, f := range current {
+		if done[f] > 0 {
+			done[f]--
+			continue
+		}
+		keep = append(keep, f)
+	}
+	keep = append(keep, again...)
+	if len(keep) == 0 {
+		if err := os.Remove(l.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, f := range keep {
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+	}
+	return util.AtomicWriteFile(l.Path, buf.Bytes(), 0o644)
+}
+
+// TryUpdate applies req like Update, but a failure is logged for
+// ReplayFailures instead of returned. It reports whether the write landed.
+func (c Client) TryUpdate(ctx context.Context, id string, req UpdateRequest) bool {
+	_, err := c.Update(ctx, id, req)
+	if err != nil {
+		c.logFailure(Failure{Op: FailedUpdate, ID: id, Status: req.Status, Description: req.Description, Reason: req.Reason}, err)
+	}
+	return err == nil
+}
+
+// TryClose closes a bead like Close, logging a failure instead of returning it.
+func (c Client) TryClose(ctx context.Context, id, reason string) bool {
+	_, err := c.Close(ctx, id, reason)
+	if err != nil {
+		c.logFailure(Failure{Op: FailedClose, ID: id, Reason: reason}, err)
+	}
+	return err == nil
+}
+
+// logFailure records err unless replaying could not help: the store reported
+// the bead missing or the backend lacks the capability.
+func (c Client) logFailure(f Failure, err error) {
+	if c.Failures == nil || strings.TrimSpace(f.ID) == "" {
+		return
+	}
+	var capErr *CapabilityError
+	if errors.Is(err, ErrNotFound) || errors.As(err, &capErr) {
+		return
+	}
+	switch {
+	case errors.Is(err, util.ErrLockContention):
+		f.Kind = "lock_contention"
+	case errors.Is(err, util.ErrTimeout):
+		f.Kind = "timeout"
+	}
+	f.Actor = c.Actor
+	f.Error = err.Error()
+	f.At = time.Now().UTC().Format(time.RFC3339)
+	_ = c.Failures.Append(f)
+}
+
+// ReplayFailures re-attempts logged failures. A failure is dropped as
+// superseded when the bead changed after it was logged, is already in the
+// target state, or no longer exists; failures that fail again stay logged
+// until MaxReplayAttempts, when they are marked FailureDead.
+func (c Client) ReplayFailures(ctx context.Context) (ReplayResult, error) {
+	res := ReplayResult{}
+	if c.Failures == nil {
+		return res, nil
+	}
+	list, err := c.Failures.pending()
+	if err != nil {
+		return res, err
+	}
+	if len(list) == 0 {
+		return res, nil
+	}
+	var history map[string][]Transition
+	if c.HistoryLog != nil {
+		history, _ = c.HistoryLog.Load()
+	}
+	var again []Failure
+	for _, f := range latestFailures(list) {
+		issue, err := c.Show(ctx, f.ID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				res.Superseded++
+				continue
+			}
+			again = append(again, retryFailure(f, err, &res))
+			continue
+		}
+		if failureSuperseded(f, issue, history[f.ID]) {
+			res.Superseded++
+			continue
+		}
+		switch f.Op {
+		case FailedClose:
+			_, err = c.Close(ctx, f.ID, f.Reason)
+		default:
+			_, err = c.Update(ctx, f.ID, UpdateRequest{Status: f.Status, Description: f.Description, Reason: f.Reason})
+		}
+		if err != nil {
+			again = append(again, retryFailure(f, err, &res))
+			continue
+		}
+		res.Replayed++
+	}
+	if err := c.Failures.settle(list, again); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// retryFailure counts another failed attempt at f, dead-lettering it once it
+// reaches MaxReplayAttempts.
+func retryFailure(f Failure, err error, res *ReplayResult) Failure {
+	f.Attempts++
+	f.Error = err.Error()
+	if f.Attempts >= MaxReplayAttempts {
+		f.State = FailureDead
+		res.DeadLettered++
+	} else {
+		res.Remaining++
+	}
+	return f
+}
+
+// latestFailures keeps the newest failure per bead and operation.
+func latestFailures(list []Failure) []Failure {
+	idx := map[string]int{}
+	var out []Failure
+	for _, f := range list {
+		key := f.Op + "|" + f.ID
+		if i, ok := idx[key]; ok {
+			f.Attempts = max(f.Attempts, out[i].Attempts)
+			out[i] = f
+			continue
+		}
+		idx[key] = len(out)
+		out = append(out, f)
+	}
+	return out
+}
+
+func failureSuperseded(f Failure, issue Issue, transitions []Transition) bool {
+	if IsClosed(issue.Status) {
+		return true
+	}
+	if f.Op == FailedUpdate && f.Description == "" && strings.EqualFold(issue.Status, f.Status) {
+		return true
+	}
+	at, err := time.Parse(time.RFC3339, f.At)
+	if err != nil {
+		return false
+	}
+	if updated, err := time.Parse(time.RFC3339, issue.UpdatedAt); err == nil && updated.After(at) {
+		return true
+	}
+	for _, t := range transitions {
+		if when, err := time.Parse(time.RFC3339, t.At); err == nil && when.After(at) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package beads
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/example/microforge/internal/util"
+)
+
+type lockedStore struct {
+	Store
+	locked bool
+}
+
+func (s *lockedStore) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	if s.locked {
+		return Issue{}, &util.ExecError{Name: "bd", Args: []string{"update", id}, Stderr: "database is locked", Kind: util.ErrLockContention, Err: errors.New("exit status 1")}
+	}
+	return s.Store.Update(ctx, id, req)
+}
+
+func TestTryUpdateLogsAndReplaysFailures(t *testing.T) {
+	store := &lockedStore{Store: NewFileStore(filepath.Join(t.TempDir(), "beads.jsonl")), locked: true}
+	client := Client{Store: store, Failures: &FailureLog{Path: filepath.Join(t.TempDir(), "failures.jsonl")}}
+	issue, err := client.Create(nil, CreateRequest{Title: "Task", Type: "task"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	other, err := client.Create(nil, CreateRequest{Title: "Other", Type: "task"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if client.TryUpdate(nil, issue.ID, UpdateRequest{Status: "in_progress"}) {
+		t.Fatalf("expected locked update to fail")
+	}
+	client.TryUpdate(nil, other.ID, UpdateRequest{Status: "blocked"})
+	logged, err := client.Failures.Load()
+	if err != nil || len(logged) != 2 || logged[0].Kind != "lock_contention" {
+		t.Fatalf("expected 2 lock_contention failures, got %+v (%v)", logged, err)
+	}
+
+	// Still locked: both stay pending.
+	res, err := client.ReplayFailures(nil)
+	if err != nil || res.Remaining != 2 || res.Replayed != 0 {
+		t.Fatalf("unexpected replay while locked: %+v (%v)", res, err)
+	}
+
+	store.locked = false
+	if _, err := client.Close(nil, other.ID, "done elsewhere"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	res, err = client.ReplayFailures(nil)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if res.Replayed != 1 || res.Superseded != 1 || res.Remaining != 0 {
+		t.Fatalf("unexpected replay result: %+v", res)
+	}
+	got, _ := client.Show(nil, issue.ID)
+	if got.Status != "in_progress" {
+		t.Fatalf("expected replayed status, got %s", got.Status)
+	}
+	if left, _ := client.Failures.Load(); len(left) != 0 {
+		t.Fatalf("expected empty log, got %+v", left)
+	}
+}
+
+type appendingStore struct {
+	lockedStore
+	log *FailureLog
+}
+
+// Update logs a fresh failure while a replay is in flight.
+func (s *appendingStore) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	_ = s.log.Append(Failure{Op: FailedClose, ID: "late", Error: "x"})
+	return s.lockedStore.Update(ctx, id, req)
+}
+
+func TestReplayFailuresDeadLettersAndKeepsConcurrentAppends(t *testing.T) {
+	log := &FailureLog{Path: filepath.Join(t.TempDir(), "failures.jsonl")}
+	store := &appendingStore{lockedStore: lockedStore{Store: NewFileStore(filepath.Join(t.TempDir(), "beads.jsonl")), locked: true}, log: log}
+	client := Client{Store: store, Failures: log}
+	issue, err := client.Create(nil, CreateRequest{Title: "Task", Type: "task"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := log.Append(Failure{Op: FailedUpdate, ID: issue.ID, Status: "in_progress", Error: "locked", At: time.Now().Add(time.Hour).UTC().Format(time.RFC3339)}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	res, err := client.ReplayFailures(nil)
+	if err != nil || res.Remaining != 1 {
+		t.Fatalf("unexpected first replay: %+v (%v)", res, err)
+	}
+	if left, _ := log.Load(); len(left) != 2 || left[0].ID != "late" || left[1].Attempts != 1 {
+		t.Fatalf("expected the late append kept and the retry re-logged, got %+v", left)
+	}
+	for i := 1; i < MaxReplayAttempts; i++ {
+		if res, err = client.ReplayFailures(nil); err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+	}
+	if res.DeadLettered != 1 {
+		t.Fatalf("expected the update dead-lettered, got %+v", res)
+	}
+	left, _ := log.Load()
+	dead := 0
+	for _, f := range left {
+		if f.ID == issue.ID && f.State == FailureDead && f.Attempts == MaxReplayAttempts {
+			dead++
+		}
+	}
+	if dead != 1 {
+		t.Fatalf("expected one dead entry for %s, got %+v", issue.ID, left)
+	}
+	store.locked = false
+	if res, err = client.ReplayFailures(nil); err != nil || res.Replayed != 0 {
+		t.Fatalf("expected dead entries skipped, got %+v (%v)", res, err)
+	}
+}
@@ -0,0 +1,433 @@
+package beads
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// FileStore is a pure-Go Store that keeps every issue as one JSON object per
+// line in a single file. Writers hold a lock file next to the store so
+// concurrent hooks from several agents do not clobber each other.
+type FileStore struct {
+	Path string
+}
+
+// NewFileStore returns a FileStore persisting to path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+type fileRecord struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	Type        string    `json:"type,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Deps        []string  `json:"deps,omitempty"`
+	CreatedAt   string    `json:"created_at"`
+	UpdatedAt   string    `json:"updated_at,omitempty"`
+	ClosedAt    string    `json:"closed_at,omitempty"`
+	CloseReason string    `json:"close_reason,omitempty"`
+	Comments    []Comment `json:"comments,omitempty"`
+}
+
+func (r fileRecord) issue() Issue {
+	return Issue{
+		ID:          r.ID,
+		Title:       r.Title,
+		Status:      r.Status,
+		Type:        r.Type,
+		Priority:    r.Priority,
+		Description: r.Description,
+		Deps:        append([]string(nil), r.Deps...),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// Init creates the store file if it does not exist.
+func (s *FileStore) Init(ctx context.Context) error {
+	if err := util.EnsureDir(filepath.Dir(s.Path)); err != nil {
+		return fmt.Errorf("creating store dir: %w", err)
+	}
+	if _, err := os.Stat(s.Path); err == nil {
+		return nil
+	}
+	return s.mutate(ctx, func([]fileRecord) ([]fileRecord, error) { return nil, nil })
+}
+
+// Create appends a new issue with a random ID.
+func (s *FileStore) Create(ctx context.Context, req CreateRequest) (Issue, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return Issue{}, fmt.Errorf("title is required")
+	}
+	var created fileRecord
+	err := s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		seen := map[string]bool{}
+		for _, r := range recs {
+			seen[r.ID] = true
+		}
+		id, err := newFileID(seen)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		created = fileRecord{
+			ID:          id,
+			Title:       req.Title,
+			Status:      defaultIfBlank(req.Status, "open"),
+			Type:        req.Type,
+			Priority:    req.Priority,
+			Description: req.Description,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		for _, dep := range req.Deps {
+			if strings.TrimSpace(dep) != "" {
+				created.Deps = append(created.Deps, strings.TrimSpace(dep))
+			}
+		}
+		return append(recs, created), nil
+	})
+	if err != nil {
+		return Issue{}, err
+	}
+	return created.issue(), nil
+}
+
+// Update changes the status and/or description of an existing issue.
+func (s *FileStore) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	var updated fileRecord
+	err := s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		i := indexRecord(recs, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if strings.TrimSpace(req.Status) != "" {
+			recs[i].Status = req.Status
+		}
+		if strings.TrimSpace(req.Description) != "" {
+			recs[i].Description = req.Description
+		}
+		recs[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		updated = recs[i]
+		return recs, nil
+	})
+	if err != nil {
+		return Issue{}, err
+	}
+	return updated.issue(), nil
+}
+
+// Close marks an issue closed and records the reason.
+func (s *FileStore) Close(ctx context.Context, id string, opts CloseOptions) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	var closed fileRecord
+	err := s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		i := indexRecord(recs, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		recs[i].Status = "closed"
+		recs[i].ClosedAt = now
+		recs[i].UpdatedAt = now
+		recs[i].CloseReason = opts.Reason
+		closed = recs[i]
+		return recs, nil
+	})
+	if err != nil {
+		return Issue{}, err
+	}
+	return closed.issue(), nil
+}
+
+// Show returns a single issue by ID.
+func (s *FileStore) Show(ctx context.Context, id string) (Issue, error) {
+	if strings.TrimSpace(id) == "" {
+		return Issue{}, fmt.Errorf("id is required")
+	}
+	recs, err := s.load()
+	if err != nil {
+		return Issue{}, err
+	}
+	i := indexRecord(recs, id)
+	if i < 0 {
+		return Issue{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return recs[i].issue(), nil
+}
+
+// List returns every issue in file order.
+func (s *FileStore) List(ctx context.Context) ([]Issue, error) {
+	recs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Issue, 0, len(recs))
+	for _, r := range recs {
+		out = append(out, r.issue())
+	}
+	return out, nil
+}
+
+// Ready returns open issues the dependency graph does not hold back: every
+// target of a blocks or review edge is closed.
+func (s *FileStore) Ready(ctx context.Context) ([]Issue, error) {
+	recs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(recs))
+	for _, r := range recs {
+		issues = append(issues, r.issue())
+	}
+	graph := NewGraph(issues)
+	out := []Issue{}
+	for _, issue := range issues {
+		if issue.Status == "open" && len(graph.Blockers(issue.ID)) == 0 {
+			out = append(out, issue)
+		}
+	}
+	return out, nil
+}
+
+// DepAdd records that id depends on dep.
+func (s *FileStore) DepAdd(ctx context.Context, id, dep string) error {
+	if strings.TrimSpace(id) == "" || strings.TrimSpace(dep) == "" {
+		return fmt.Errorf("id and dep required")
+	}
+	return s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		i := indexRecord(recs, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		for _, existing := range recs[i].Deps {
+			if existing == dep {
+				return recs, nil
+			}
+		}
+		recs[i].Deps = append(recs[i].Deps, dep)
+		recs[i].UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		return recs, nil
+	})
+}
+
+// Delete removes issues from the file. With Cascade, issues that depend on a
+// deleted issue are removed too. Hard is accepted for parity with bd; the file
+// store never keeps tombstones.
+func (s *FileStore) Delete(ctx context.Context, ids []string, opts DeleteOptions) (string, error) {
+	targets := map[string]bool{}
+	for _, id := range ids {
+		if strings.TrimSpace(id) != "" {
+			targets[strings.TrimSpace(id)] = true
+		}
+	}
+	if len(targets) == 0 {
+		return "", fmt.Errorf("id is required")
+	}
+	var removed []string
+	err := s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		for id := range targets {
+			if indexRecord(recs, id) < 0 && !opts.Force {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+		}
+		if opts.Cascade {
+			for changed := true; changed; {
+				changed = false
+				for _, r := range recs {
+					if targets[r.ID] {
+						continue
+					}
+					for _, dep := range r.Deps {
+						if _, id := ParseDep(dep); targets[id] {
+							targets[r.ID] = true
+							changed = true
+							break
+						}
+					}
+				}
+			}
+		}
+		kept := recs[:0]
+		for _, r := range recs {
+			if targets[r.ID] {
+				removed = append(removed, r.ID)
+				continue
+			}
+			kept = append(kept, r)
+		}
+		if opts.DryRun {
+			return nil, errDryRun
+		}
+		return kept, nil
+	})
+	if err != nil && err != errDryRun {
+		return "", err
+	}
+	if opts.DryRun {
+		return fmt.Sprintf("Would delete %d issue(s): %s\n", len(removed), strings.Join(removed, " ")), nil
+	}
+	return fmt.Sprintf("Deleted %d issue(s): %s\n", len(removed), strings.Join(removed, " ")), nil
+}
+
+// AddComment appends a comment to an issue's thread.
+func (s *FileStore) AddComment(ctx context.Context, id, author, body string) (Comment, error) {
+	var added Comment
+	err := s.mutate(ctx, func(recs []fileRecord) ([]fileRecord, error) {
+		i := indexRecord(recs, id)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		added = Comment{
+			ID:        fmt.Sprintf("%s-c%d", recs[i].ID, len(recs[i].Comments)+1),
+			IssueID:   recs[i].ID,
+			Author:    author,
+			Body:      body,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+		recs[i].Comments = append(recs[i].Comments, added)
+		return recs, nil
+	})
+	if err != nil {
+		return Comment{}, err
+	}
+	return added, nil
+}
+
+// Comments returns an issue's thread in the order comments were added.
+func (s *FileStore) Comments(ctx context.Context, id string) ([]Comment, error) {
+	recs, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	i := indexRecord(recs, id)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return append([]Comment(nil), recs[i].Comments...), nil
+}
+
+// Version reports the store file's modification time and size so watchers
+// can skip re-reading an unchanged file.
+func (s *FileStore) Version(ctx context.Context) (string, error) {
+	info, err := os.Stat(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "missing", nil
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// errDryRun aborts a mutation without writing.
+var errDryRun = fmt.Errorf("dry run")
+
+func (s *FileStore) load() ([]fileRecord, error) {
+	b, err := os.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading beads store %s: %w", s.Path, err)
+	}
+	var recs []fileRecord
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var r fileRecord
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil, fmt.Errorf("parsing %s line %d: %w", s.Path, line, err)
+		}
+		recs = append(recs, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading beads store %s: %w", s.Path, err)
+	}
+	return recs, nil
+}
+
+// mutate loads the store under the write lock, applies fn, and atomically
+// rewrites the file with the result.
+func (s *FileStore) mutate(ctx context.Context, fn func([]fileRecord) ([]fileRecord, error)) error {
+	if err := util.EnsureDir(filepath.Dir(s.Path)); err != nil {
+		return fmt.Errorf("creating store dir: %w", err)
+	}
+	unlock, err := util.LockFile(ctx, s.Path+".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	recs, err := s.load()
+	if err != nil {
+		return err
+	}
+	recs, err = fn(recs)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("encoding issue %s: %w", r.ID, err)
+		}
+	}
+	return util.AtomicWriteFile(s.Path, buf.Bytes(), 0o644)
+}
+
+func indexRecord(recs []fileRecord, id string) int {
+	for i := range recs {
+		if recs[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func newFileID(seen map[string]bool) (string, error) {
+	buf := make([]byte, 3)
+	for attempt := 0; attempt < 32; attempt++ {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generating id: %w", err)
+		}
+		id := "mf-" + hex.EncodeToString(buf)
+		if !seen[id] {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("generating id: too many collisions")
+}
+
+func defaultIfBlank(val, def string) string {
+	if strings.TrimSpace(val) == "" {
+		return def
+	}
+	return val
+}
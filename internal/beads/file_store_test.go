@@ -0,0 +1,61 @@
+package beads
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStoreLifecycle(t *testing.T) {
+	client, err := Open(BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := client.Init(nil); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	blocker, err := client.Create(nil, CreateRequest{Title: "Blocker", Type: "task"})
+	if err != nil {
+		t.Fatalf("create blocker: %v", err)
+	}
+	task, err := client.Create(nil, CreateRequest{Title: "Task", Type: "task", Deps: []string{blocker.ID, "related:" + blocker.ID}})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	ready, err := client.Ready(nil)
+	if err != nil {
+		t.Fatalf("ready: %v", err)
+	}
+	if len(ready) != 1 || ready[0].ID != blocker.ID {
+		t.Fatalf("expected only blocker ready, got %+v", ready)
+	}
+	if _, err := client.Close(nil, blocker.ID, "done"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	ready, _ = client.Ready(nil)
+	if len(ready) != 1 || ready[0].ID != task.ID {
+		t.Fatalf("expected task ready after close, got %+v", ready)
+	}
+	if _, err := client.UpdateDescription(nil, task.ID, "---\ncell: alpha\n---"); err != nil {
+		t.Fatalf("update description: %v", err)
+	}
+	shown, err := client.Show(nil, task.ID)
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if ParseMeta(shown.Description).Cell != "alpha" {
+		t.Fatalf("expected description to persist, got %q", shown.Description)
+	}
+	if _, err := client.Delete(nil, []string{blocker.ID}, DeleteOptions{Cascade: true}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := client.Show(nil, task.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected cascade delete, got %v", err)
+	}
+}
+
+func TestOpenRejectsUnknownBackend(t *testing.T) {
+	if _, err := Open("sqlite", "/tmp/repo", ""); err == nil {
+		t.Fatalf("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,358 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EdgeKind classifies a dependency between two beads.
+type EdgeKind string
+
+const (
+	// EdgeBlocks is a hard dependency: the source cannot start until the
+	// target is closed. Bare IDs in Deps are blocks edges.
+	EdgeBlocks EdgeKind = "blocks"
+	// EdgeRelated links beads without ordering them (assignment -> task,
+	// event -> subject).
+	EdgeRelated EdgeKind = "related"
+	// EdgeReview gates the source on a review bead being closed.
+	EdgeReview EdgeKind = "review"
+	// EdgeParent attaches a task to its epic. A related edge pointing at an
+	// epic is treated as a parent edge.
+	EdgeParent EdgeKind = "parent"
+)
+
+// Edge is a typed dependency from one bead to another.
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+}
+
+// ParseDep splits a raw dependency string such as "review:mf-1" into its
+// kind and target. Bare IDs are blocks edges; unknown prefixes are kept as
+// their own kind.
+func ParseDep(dep string) (EdgeKind, string) {
+	dep = strings.TrimSpace(dep)
+	if !strings.Contains(dep, ":") {
+		return EdgeBlocks, dep
+	}
+	parts := strings.SplitN(dep, ":", 2)
+	kind := EdgeKind(strings.ToLower(strings.TrimSpace(parts[0])))
+	if kind == "parent-child" {
+		kind = EdgeParent
+	}
+	return kind, strings.TrimSpace(parts[1])
+}
+
+// FormatDep renders a dependency string accepted by DepAdd and CreateRequest.
+func FormatDep(kind EdgeKind, id string) string {
+	return string(kind) + ":" + id
+}
+
+// HasDep reports whether deps contains an edge of kind to id. Related deps
+// also match parent lookups so callers need not know the target's type.
+func HasDep(deps []string, kind EdgeKind, id string) bool {
+	for _, dep := range deps {
+		k, target := ParseDep(dep)
+		if target != id {
+			continue
+		}
+		if k == kind || (kind == EdgeParent && k == EdgeRelated) {
+			return true
+		}
+	}
+	return false
+}
+
+// DepTargets returns the targets of the deps of the given kind, in order.
+func DepTargets(deps []string, kind EdgeKind) []string {
+	var out []string
+	for _, dep := range deps {
+		if k, target := ParseDep(dep); k == kind && target != "" {
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+// Blocking reports whether edges of kind hold back their source until the
+// target is closed.
+func (k EdgeKind) Blocking() bool {
+	return k == EdgeBlocks || k == EdgeReview
+}
+
+// ordering reports whether edges of kind impose an order that a cycle would
+// make impossible to satisfy.
+func (k EdgeKind) ordering() bool {
+	return k.Blocking() || k == EdgeParent
+}
+
+// ErrCycle is returned when dependencies form a cycle. Path lists the beads
+// in the cycle, starting and ending with the same ID.
+type ErrCycle struct {
+	Path []string
+}
+
+func (e ErrCycle) Error() string {
+	return "dependency cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// Graph is a typed view of the dependencies between a set of beads.
+type Graph struct {
+	issues map[string]Issue
+	order  []string
+	out    map[string][]Edge
+	in     map[string][]Edge
+}
+
+// NewGraph builds a dependency graph from a listing.
+func NewGraph(issues []Issue) *Graph {
+	g := &Graph{
+		issues: make(map[string]Issue, len(issues)),
+		out:    map[string][]Edge{},
+		in:     map[string][]Edge{},
+	}
+	for _, issue := range issues {
+		if _, ok := g.issues[issue.ID]; !ok {
+			g.order = append(g.order, issue.ID)
+		}
+		g.issues[issue.ID] = issue
+	}
+	for _, id := range g.order {
+		for _, dep := range g.issues[id].Deps {
+			g.add(id, dep)
+		}
+	}
+	return g
+}
+
+func (g *Graph) add(from, dep string) Edge {
+	kind, to := ParseDep(dep)
+	if to == "" {
+		return Edge{}
+	}
+	if kind == EdgeRelated {
+		if target, ok := g.issues[to]; ok && strings.EqualFold(target.Type, "epic") {
+			kind = EdgeParent
+		}
+	}
+	edge := Edge{From: from, To: to, Kind: kind}
+	g.out[from] = append(g.out[from], edge)
+	g.in[to] = append(g.in[to], edge)
+	return edge
+}
+
+// Issue returns the bead with the given ID.
+func (g *Graph) Issue(id string) (Issue, bool) {
+	issue, ok := g.issues[id]
+	return issue, ok
+}
+
+// Edges returns the outgoing edges of id, optionally filtered by kind.
+func (g *Graph) Edges(id string, kinds ...EdgeKind) []Edge {
+	return filterEdges(g.out[id], kinds)
+}
+
+// Incoming returns the edges pointing at id, optionally filtered by kind.
+func (g *Graph) Incoming(id string, kinds ...EdgeKind) []Edge {
+	return filterEdges(g.in[id], kinds)
+}
+
+// Blockers returns the beads that currently hold id back: targets of its
+// blocking edges that are not closed. Targets missing from the graph are
+// returned as bare issues with only an ID so callers treat them as unmet.
+func (g *Graph) Blockers(id string) []Issue {
+	var out []Issue
+	for _, edge := range g.out[id] {
+		if !edge.Kind.Blocking() {
+			continue
+		}
+		target, ok := g.issues[edge.To]
+		if !ok {
+			out = append(out, Issue{ID: edge.To})
+			continue
+		}
+		if !IsClosed(target.Status) {
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+// DepsClosed reports whether id has dependencies to wait on and all of them
+// are closed: the targets of every edge except parent edges, which point at
+// an epic that stays open while its children run. Targets missing from the
+// graph count as open.
+func (g *Graph) DepsClosed(id string) bool {
+	waited := false
+	for _, edge := range g.out[id] {
+		if edge.Kind == EdgeParent {
+			continue
+		}
+		waited = true
+		target, ok := g.issues[edge.To]
+		if !ok || !IsClosed(target.Status) {
+			return false
+		}
+	}
+	return waited
+}
+
+// Unblocks returns the beads that wait on id through a blocking edge.
+func (g *Graph) Unblocks(id string) []Issue {
+	var out []Issue
+	for _, edge := range g.in[id] {
+		if !edge.Kind.Blocking() {
+			continue
+		}
+		if issue, ok := g.issues[edge.From]; ok {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// Children returns the beads attached to id through a parent edge, in
+// listing order. Any edge to an epic attaches its source, so tasks that
+// name their epic as a bare ID are children too.
+func (g *Graph) Children(id string) []Issue {
+	var out []Issue
+	seen := map[string]bool{}
+	epic := strings.EqualFold(g.issues[id].Type, "epic")
+	for _, edge := range g.in[id] {
+		if (edge.Kind != EdgeParent && !epic) || seen[edge.From] {
+			continue
+		}
+		if issue, ok := g.issues[edge.From]; ok {
+			seen[edge.From] = true
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// TopoOrder returns ids ordered so every bead comes after the beads it
+// depends on through ordering edges. Only dependencies within ids are
+// considered; ties keep the order of ids.
+func (g *Graph) TopoOrder(ids []string) ([]string, error) {
+	want := make(map[string]int, len(ids))
+	for i, id := range ids {
+		want[id] = i
+	}
+	indegree := make(map[string]int, len(ids))
+	for _, id := range ids {
+		for _, edge := range g.out[id] {
+			if _, ok := want[edge.To]; ok && edge.Kind.ordering() && edge.To != id {
+				indegree[id]++
+			}
+		}
+	}
+	var queue []string
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	out := make([]string, 0, len(ids))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		out = append(out, id)
+		var next []string
+		for _, edge := range g.in[id] {
+			if _, ok := want[edge.From]; !ok || !edge.Kind.ordering() || edge.From == id {
+				continue
+			}
+			indegree[edge.From]--
+			if indegree[edge.From] == 0 {
+				next = append(next, edge.From)
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return want[next[i]] < want[next[j]] })
+		queue = append(queue, next...)
+	}
+	if len(out) != len(ids) {
+		for _, id := range ids {
+			if indegree[id] > 0 {
+				if path := g.cyclePath(id, id); path != nil {
+					return out, ErrCycle{Path: path}
+				}
+			}
+		}
+		return out, fmt.Errorf("dependency cycle among %d bead(s)", len(ids)-len(out))
+	}
+	return out, nil
+}
+
+// CheckDep reports whether adding dep to id would create a cycle through
+// ordering edges. Related edges never form cycles.
+func (g *Graph) CheckDep(id, dep string) error {
+	kind, to := ParseDep(dep)
+	if to == "" {
+		return fmt.Errorf("empty dependency")
+	}
+	if kind == EdgeRelated {
+		if target, ok := g.issues[to]; ok && strings.EqualFold(target.Type, "epic") {
+			kind = EdgeParent
+		}
+	}
+	if !kind.ordering() {
+		return nil
+	}
+	if to == id {
+		return ErrCycle{Path: []string{id, id}}
+	}
+	if path := g.cyclePath(to, id); path != nil {
+		return ErrCycle{Path: append([]string{id}, path...)}
+	}
+	return nil
+}
+
+// cyclePath returns a path of ordering edges from start to goal, or nil.
+func (g *Graph) cyclePath(start, goal string) []string {
+	seen := map[string]bool{}
+	var walk func(id string) []string
+	walk = func(id string) []string {
+		for _, edge := range g.out[id] {
+			if !edge.Kind.ordering() {
+				continue
+			}
+			if edge.To == goal {
+				return []string{id, goal}
+			}
+			if seen[edge.To] {
+				continue
+			}
+			seen[edge.To] = true
+			if rest := walk(edge.To); rest != nil {
+				return append([]string{id}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(start)
+}
+
+// IsClosed reports whether status is a terminal bead status.
+func IsClosed(status string) bool {
+	status = strings.ToLower(strings.TrimSpace(status))
+	return status == "closed" || status == "done"
+}
+
+func filterEdges(edges []Edge, kinds []EdgeKind) []Edge {
+	if len(kinds) == 0 {
+		return append([]Edge(nil), edges...)
+	}
+	var out []Edge
+	for _, edge := range edges {
+		for _, k := range kinds {
+			if edge.Kind == k {
+				out = append(out, edge)
+				break
+			}
+		}
+	}
+	return out
+}
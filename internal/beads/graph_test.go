@@ -0,0 +1,139 @@
+package beads
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestGraphEdgesAndBlockers(t *testing.T) {
+	issues := []Issue{
+		{ID: "epic", Type: "epic", Status: "open"},
+		{ID: "a", Type: "task", Status: "closed", Deps: []string{"related:epic"}},
+		{ID: "b", Type: "task", Status: "blocked", Deps: []string{"related:epic", "a"}},
+		{ID: "c", Type: "task", Status: "open", Deps: []string{"related:epic", "blocks:b"}},
+		{ID: "pr", Type: "pr", Status: "open", Deps: []string{"review:rev", "related:c"}},
+		{ID: "rev", Type: "review", Status: "open"},
+	}
+	g := NewGraph(issues)
+	if kids := g.Children("epic"); len(kids) != 3 {
+		t.Fatalf("expected 3 children, got %+v", kids)
+	}
+	if blockers := g.Blockers("b"); len(blockers) != 0 {
+		t.Fatalf("expected b unblocked, got %+v", blockers)
+	}
+	if blockers := g.Blockers("c"); len(blockers) != 1 || blockers[0].ID != "b" {
+		t.Fatalf("expected c blocked by b, got %+v", blockers)
+	}
+	if blockers := g.Blockers("pr"); len(blockers) != 1 || blockers[0].ID != "rev" {
+		t.Fatalf("expected pr blocked by review, got %+v", blockers)
+	}
+	if unblocks := g.Unblocks("a"); len(unblocks) != 1 || unblocks[0].ID != "b" {
+		t.Fatalf("expected a to unblock b, got %+v", unblocks)
+	}
+	order, err := g.TopoOrder([]string{"c", "b", "a"})
+	if err != nil {
+		t.Fatalf("topo: %v", err)
+	}
+	if order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+
+func TestGraphCheckDepDetectsCycle(t *testing.T) {
+	g := NewGraph([]Issue{
+		{ID: "a", Deps: []string{"b"}},
+		{ID: "b", Deps: []string{"review:c"}},
+		{ID: "c"},
+	})
+	var cycle ErrCycle
+	if err := g.CheckDep("c", "a"); !errors.As(err, &cycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	if len(cycle.Path) != 4 || cycle.Path[0] != "c" || cycle.Path[3] != "c" {
+		t.Fatalf("unexpected cycle path %v", cycle.Path)
+	}
+	if err := g.CheckDep("c", "related:a"); err != nil {
+		t.Fatalf("related edges should not cycle: %v", err)
+	}
+	if err := g.CheckDep("a", "c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGraphEpicChildrenAndDepsClosed(t *testing.T) {
+	issues := []Issue{
+		{ID: "epic", Type: "epic", Status: "open"},
+		{ID: "bare", Type: "task", Status: "open", Deps: []string{"epic"}},
+		{ID: "req", Type: "request", Status: "closed"},
+		{ID: "t1", Type: "task", Status: "blocked", Deps: []string{"related:req", "related:epic"}},
+		{ID: "t2", Type: "task", Status: "blocked", Deps: []string{"related:epic"}},
+		{ID: "t3", Type: "task", Status: "blocked", Deps: []string{"missing"}},
+	}
+	g := NewGraph(issues)
+	if kids := g.Children("epic"); len(kids) != 3 || kids[0].ID != "bare" {
+		t.Fatalf("bare-ID deps on an epic should count as children, got %+v", kids)
+	}
+	if !g.DepsClosed("t1") {
+		t.Fatal("t1 waits only on a closed related bead")
+	}
+	if g.DepsClosed("t2") || g.DepsClosed("t3") {
+		t.Fatal("an epic parent alone or a missing target should not count as closed deps")
+	}
+}
+
+func TestReadyAgreesWithGraphOnReviewEdges(t *testing.T) {
+	client, err := Open(BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev, _ := client.Create(nil, CreateRequest{Title: "Review", Type: "review", Status: "open"})
+	pr, _ := client.Create(nil, CreateRequest{Title: "PR", Type: "pr", Status: "open", Deps: []string{"review:" + rev.ID}})
+	ready, err := client.Ready(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := client.List(nil)
+	g := NewGraph(all)
+	for _, issue := range ready {
+		if issue.ID == pr.ID || len(g.Blockers(issue.ID)) > 0 {
+			t.Fatalf("ready lists %s, which the graph holds back", issue.ID)
+		}
+	}
+	if len(ready) != 1 || ready[0].ID != rev.ID {
+		t.Fatalf("expected only the review ready, got %+v", ready)
+	}
+}
+
+type listCountingStore struct {
+	Store
+	lists int
+}
+
+func (s *listCountingStore) List(ctx context.Context) ([]Issue, error) {
+	s.lists++
+	return s.Store.List(ctx)
+}
+
+func TestReadyFiltersAgainstCachedSnapshot(t *testing.T) {
+	inner := &listCountingStore{Store: NewFileStore(filepath.Join(t.TempDir(), "beads.jsonl"))}
+	client := Client{Store: NewCachedStore(inner)}
+	rev, _ := client.Create(nil, CreateRequest{Title: "Review", Type: "review", Status: "open"})
+	_, _ = client.Create(nil, CreateRequest{Title: "PR", Type: "pr", Status: "open", Deps: []string{"review:" + rev.ID}})
+	if _, err := client.List(nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		ready, err := client.Ready(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ready) != 1 || ready[0].ID != rev.ID {
+			t.Fatalf("expected only the review ready, got %+v", ready)
+		}
+	}
+	if inner.lists != 1 {
+		t.Fatalf("expected Ready to reuse the snapshot, got %d lists", inner.lists)
+	}
+}
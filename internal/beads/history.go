@@ -0,0 +1,259 @@
+package beads
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// Transition is one recorded status change on a bead.
+type Transition struct {
+	ID     string `json:"id"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	At     string `json:"at"`
+}
+
+// DefaultHistoryMaxBytes is the size at which a HistoryLog rotates when
+// MaxBytes is unset.
+const DefaultHistoryMaxBytes = 8 << 20
+
+// HistoryLog is an append-only JSONL file of status transitions. Backends
+// only keep a bead's current status, so Client writes every change it makes
+// here as well. Once the file reaches MaxBytes it is renamed to Path+".1",
+// replacing the previous generation, so the history on disk stays under
+// twice MaxBytes.
+type HistoryLog struct {
+	Path     string
+	MaxBytes int64
+}
+
+// ErrNoHistory is returned when a Client has no history log configured.
+var ErrNoHistory = errors.New("bead history is not recorded for this client")
+
+// Append records a transition.
+func (h *HistoryLog) Append(t Transition) error {
+	if err := util.EnsureDir(filepath.Dir(h.Path)); err != nil {
+		return err
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	limit := h.MaxBytes
+	if limit <= 0 {
+		limit = DefaultHistoryMaxBytes
+	}
+	if err := util.RotateFile(h.Path, limit); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Load returns every transition grouped by bead, oldest first.
+func (h *HistoryLog) Load() (map[string][]Transition, error) {
+	out := map[string][]Transition{}
+	err := h.scan(nil, func(t Transition) {
+		out[t.ID] = append(out[t.ID], t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// For returns the transitions of one bead, oldest first. Lines for other
+// beads are skipped without being decoded.
+func (h *HistoryLog) For(id string) ([]Transition, error) {
+	key, err := json.Marshal(id)
+	if err != nil {
+		return nil, err
+	}
+	prefix := append([]byte(`{"id":`), key...)
+	var out []Transition
+	err = h.scan(prefix, func(t Transition) {
+		if t.ID == id {
+			out = append(out, t)
+		}
+	})
+	return out, err
+}
+
+// scan decodes the rotated generation and then the live log, passing each
+// transition whose line starts with prefix to fn.
+func (h *HistoryLog) scan(prefix []byte, fn func(Transition)) error {
+	for _, path := range []string{h.Path + ".1", h.Path} {
+		f, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for sc.Scan() {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 || !bytes.HasPrefix(line, prefix) {
+				continue
+			}
+			var t Transition
+			if err := json.Unmarshal(line, &t); err != nil {
+				// A torn final line from a crashed writer should not hide the rest.
+				continue
+			}
+			fn(t)
+		}
+		err = sc.Err()
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// History returns the recorded status transitions of one bead.
+func (c Client) History(ctx context.Context, id string) ([]Transition, error) {
+	if c.HistoryLog == nil {
+		return nil, ErrNoHistory
+	}
+	return c.HistoryLog.For(id)
+}
+
+// Histories returns every recorded transition grouped by bead.
+func (c Client) Histories(ctx context.Context) (map[string][]Transition, error) {
+	if c.HistoryLog == nil {
+		return nil, ErrNoHistory
+	}
+	return c.HistoryLog.Load()
+}
+
+// recordTransition appends a status change when a history log is configured.
+// History is best-effort: the backend write already happened.
+func (c Client) recordTransition(id, from, to, reason string) {
+	if c.HistoryLog == nil || strings.TrimSpace(id) == "" || strings.EqualFold(from, to) {
+		return
+	}
+	_ = c.HistoryLog.Append(Transition{
+		ID:     id,
+		From:   from,
+		To:     to,
+		Actor:  c.Actor,
+		Reason: reason,
+		At:     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// priorStatus returns a bead's status before a write so the transition can
+// be recorded: from when the caller passed the issue's status, otherwise a
+// read that a CachedStore answers from its snapshot. It is skipped entirely
+// when history is off.
+func (c Client) priorStatus(ctx context.Context, id, from string) string {
+	if c.HistoryLog == nil {
+		return ""
+	}
+	if from = strings.TrimSpace(from); from != "" {
+		return from
+	}
+	issue, err := c.store().Show(ctx, id)
+	if err != nil {
+		return ""
+	}
+	return issue.Status
+}
+
+// Timeline summarizes a bead's history: when work started, when it closed,
+// and how long it sat in the blocked status.
+type Timeline struct {
+	Created time.Time
+	Started time.Time
+	Closed  time.Time
+	Blocked time.Duration
+}
+
+// NewTimeline folds transitions into a Timeline. Without an in_progress
+// transition the start falls back to creation; Closed is zero unless the
+// last transition closed the bead. A bead still blocked counts up to now.
+func NewTimeline(issue Issue, transitions []Transition, now time.Time) Timeline {
+	tl := Timeline{}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(issue.CreatedAt)); err == nil {
+		tl.Created = t
+	}
+	var blockedSince time.Time
+	for _, tr := range transitions {
+		at, err := time.Parse(time.RFC3339, tr.At)
+		if err != nil {
+			continue
+		}
+		if tl.Created.IsZero() && tr.From == "" {
+			tl.Created = at
+		}
+		if !blockedSince.IsZero() {
+			tl.Blocked += at.Sub(blockedSince)
+			blockedSince = time.Time{}
+		}
+		switch {
+		case strings.EqualFold(tr.To, "in_progress"):
+			if tl.Started.IsZero() {
+				tl.Started = at
+			}
+			tl.Closed = time.Time{}
+		case strings.EqualFold(tr.To, "blocked"):
+			blockedSince = at
+			tl.Closed = time.Time{}
+		case IsClosed(tr.To):
+			tl.Closed = at
+		default:
+			tl.Closed = time.Time{}
+		}
+	}
+	if !blockedSince.IsZero() {
+		tl.Blocked += now.Sub(blockedSince)
+	}
+	if tl.Started.IsZero() {
+		tl.Started = tl.Created
+	}
+	return tl
+}
+
+// Cycle returns the time from start to close, or zero while still open.
+func (t Timeline) Cycle() time.Duration {
+	if t.Closed.IsZero() || t.Started.IsZero() || t.Closed.Before(t.Started) {
+		return 0
+	}
+	return t.Closed.Sub(t.Started)
+}
+
+// FormatTransition renders a transition as one line for terminal output.
+func FormatTransition(t Transition) string {
+	from := t.From
+	if from == "" {
+		from = "(new)"
+	}
+	line := fmt.Sprintf("%s  %s -> %s", t.At, from, t.To)
+	if t.Actor != "" {
+		line += "  by " + t.Actor
+	}
+	if t.Reason != "" {
+		line += "  (" + t.Reason + ")"
+	}
+	return line
+}
@@ -0,0 +1,112 @@
+package beads
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientRecordsStatusHistory(t *testing.T) {
+	client, err := Open(BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	client.HistoryLog = &HistoryLog{Path: filepath.Join(t.TempDir(), "history.jsonl")}
+	client.Actor = "api/builder"
+	issue, err := client.Create(nil, CreateRequest{Title: "Task", Type: "task"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	_, _ = client.Update(nil, issue.ID, UpdateRequest{Status: "blocked", Reason: "waiting on schema"})
+	_, _ = client.UpdateStatus(nil, issue.ID, "blocked")
+	_, _ = client.UpdateDescription(nil, issue.ID, "no status change")
+	_, _ = client.Close(nil, issue.ID, "shipped")
+
+	got, err := client.History(nil, issue.ID)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	want := []Transition{
+		{From: "", To: "open", Reason: "created"},
+		{From: "open", To: "blocked", Reason: "waiting on schema"},
+		{From: "blocked", To: "closed", Reason: "shipped"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d transitions, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].From != want[i].From || got[i].To != want[i].To || got[i].Reason != want[i].Reason || got[i].Actor != "api/builder" {
+			t.Fatalf("transition %d: got %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(m int) string { return base.Add(time.Duration(m) * time.Minute).Format(time.RFC3339) }
+	issue := Issue{ID: "t1", CreatedAt: at(0)}
+	transitions := []Transition{
+		{ID: "t1", To: "open", At: at(0)},
+		{ID: "t1", From: "open", To: "in_progress", At: at(10)},
+		{ID: "t1", From: "in_progress", To: "blocked", At: at(20)},
+		{ID: "t1", From: "blocked", To: "in_progress", At: at(50)},
+		{ID: "t1", From: "in_progress", To: "closed", At: at(70)},
+	}
+	tl := NewTimeline(issue, transitions, base.Add(5*time.Hour))
+	if tl.Cycle() != time.Hour || tl.Blocked != 30*time.Minute {
+		t.Fatalf("unexpected timeline: cycle=%s blocked=%s", tl.Cycle(), tl.Blocked)
+	}
+	open := NewTimeline(issue, transitions[:3], base.Add(time.Hour))
+	if open.Cycle() != 0 || open.Blocked != 40*time.Minute {
+		t.Fatalf("unexpected open timeline: cycle=%s blocked=%s", open.Cycle(), open.Blocked)
+	}
+}
+
+type showCountingStore struct {
+	Store
+	shows int
+}
+
+func (s *showCountingStore) Show(ctx context.Context, id string) (Issue, error) {
+	s.shows++
+	return s.Store.Show(ctx, id)
+}
+
+func TestHistoryRotatesAndReadsOneBead(t *testing.T) {
+	store := &showCountingStore{Store: NewFileStore(filepath.Join(t.TempDir(), "beads.jsonl"))}
+	log := &HistoryLog{Path: filepath.Join(t.TempDir(), "history.jsonl"), MaxBytes: 200}
+	client := Client{Store: store, HistoryLog: log}
+	a, _ := client.Create(nil, CreateRequest{Title: "A", Type: "task"})
+	b, _ := client.Create(nil, CreateRequest{Title: "B", Type: "task"})
+	for _, status := range []string{"in_progress", "blocked", "in_progress"} {
+		if _, err := client.Update(nil, a.ID, UpdateRequest{Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.Update(nil, b.ID, UpdateRequest{Status: "blocked", From: "open"}); err != nil {
+		t.Fatal(err)
+	}
+	if store.shows != 3 {
+		t.Fatalf("expected a Show only for updates without From, got %d", store.shows)
+	}
+	if _, err := os.Stat(log.Path + ".1"); err != nil {
+		t.Fatalf("expected a rotated generation: %v", err)
+	}
+	ev, _ := client.Create(nil, CreateRequest{Title: "status", Type: "event"})
+	if got, _ := client.History(nil, ev.ID); len(got) != 0 {
+		t.Fatalf("expected no history for an event, got %+v", got)
+	}
+	got, err := client.History(nil, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[0].To != "open" || got[3].From != "blocked" {
+		t.Fatalf("expected a's history across both generations, got %+v", got)
+	}
+	got, _ = client.History(nil, b.ID)
+	if len(got) != 2 || got[1].From != "open" || got[1].To != "blocked" {
+		t.Fatalf("unexpected history for b: %+v", got)
+	}
+}
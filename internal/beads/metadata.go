@@ -2,9 +2,17 @@ package beads
 
 import (
 	"bufio"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Meta is the front-matter block at the top of a bead description. Well-known
+// keys are exposed as struct fields; any other key is kept in Extra in the
+// order it appeared so rewriting a description never drops fields added by
+// humans or other tools. Any key may carry a block of `- item` lines; the
+// inline form (`notify: [a, b]`) is a list only for keys in listKeys, so a
+// scalar such as a title may start and end with brackets.
 type Meta struct {
 	Cell       string
 	Role       string
@@ -30,12 +38,92 @@ type Meta struct {
 	MailboxID  string
 	HookID     string
 	ConvoyID   string
+
+	// Extra holds keys Meta has no field for, in their original order.
+	Extra []Field
+
+	// lists holds every value of a well-known key written as a list; the
+	// struct field carries the first one.
+	lists map[string][]string
+
+	// order is the order keys appeared in the parsed description;
+	// RenderMeta writes them back in that order.
+	order []string
+}
+
+// Field is a single front-matter entry. Scalar keys have one value.
+type Field struct {
+	Key    string
+	Values []string
+	List   bool
+}
+
+// knownKeys lists the well-known keys in render order.
+var knownKeys = []struct {
+	key   string
+	field func(*Meta) *string
+}{
+	{"cell", func(m *Meta) *string { return &m.Cell }},
+	{"role", func(m *Meta) *string { return &m.Role }},
+	{"scope", func(m *Meta) *string { return &m.Scope }},
+	{"inbox", func(m *Meta) *string { return &m.Inbox }},
+	{"outbox", func(m *Meta) *string { return &m.Outbox }},
+	{"promise", func(m *Meta) *string { return &m.Promise }},
+	{"turn_id", func(m *Meta) *string { return &m.TurnID }},
+	{"worktree", func(m *Meta) *string { return &m.Worktree }},
+	{"claimed_by", func(m *Meta) *string { return &m.ClaimedBy }},
+	{"claimed_at", func(m *Meta) *string { return &m.ClaimedAt }},
+	{"depends_on", func(m *Meta) *string { return &m.DependsOn }},
+	{"notify", func(m *Meta) *string { return &m.Notify }},
+	{"kind", func(m *Meta) *string { return &m.Kind }},
+	{"title", func(m *Meta) *string { return &m.Title }},
+	{"short_id", func(m *Meta) *string { return &m.ShortID }},
+	{"source_role", func(m *Meta) *string { return &m.SourceRole }},
+	{"severity", func(m *Meta) *string { return &m.Severity }},
+	{"class", func(m *Meta) *string { return &m.Class }},
+	{"agent_id", func(m *Meta) *string { return &m.AgentID }},
+	{"role_id", func(m *Meta) *string { return &m.RoleID }},
+	{"mailbox_id", func(m *Meta) *string { return &m.MailboxID }},
+	{"hook_id", func(m *Meta) *string { return &m.HookID }},
+	{"convoy_id", func(m *Meta) *string { return &m.ConvoyID }},
+}
+
+// listKeys are the keys whose inline `[a, b]` values are lists.
+var listKeys = map[string]bool{
+	"depends_on": true,
+	"files":      true,
+	"labels":     true,
+	"notify":     true,
+	"violations": true,
+}
+
+func knownField(m *Meta, key string) *string {
+	for _, k := range knownKeys {
+		if k.key == key {
+			return k.field(m)
+		}
+	}
+	return nil
 }
 
 func ParseMeta(desc string) Meta {
 	meta := Meta{}
 	scanner := bufio.NewScanner(strings.NewReader(desc))
 	inBlock := false
+	pending := ""
+	var pendingVals []string
+	flush := func() {
+		if pending == "" {
+			return
+		}
+		if len(pendingVals) == 0 {
+			meta.Set(pending, "")
+		} else {
+			meta.SetList(pending, pendingVals)
+		}
+		pending = ""
+		pendingVals = nil
+	}
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "---" {
@@ -48,144 +136,268 @@ func ParseMeta(desc string) Meta {
 		if !inBlock {
 			continue
 		}
+		if pending != "" && strings.HasPrefix(line, "- ") {
+			pendingVals = append(pendingVals, strings.TrimSpace(strings.TrimPrefix(line, "- ")))
+			continue
+		}
+		flush()
 		parts := strings.SplitN(line, ":", 2)
 		if len(parts) != 2 {
 			continue
 		}
 		key := strings.TrimSpace(parts[0])
 		val := strings.TrimSpace(parts[1])
-		switch key {
-		case "cell":
-			meta.Cell = val
-		case "role":
-			meta.Role = val
-		case "scope":
-			meta.Scope = val
-		case "outbox":
-			meta.Outbox = val
-		case "inbox":
-			meta.Inbox = val
-		case "promise":
-			meta.Promise = val
-		case "turn_id":
-			meta.TurnID = val
-		case "worktree":
-			meta.Worktree = val
-		case "claimed_by":
-			meta.ClaimedBy = val
-		case "claimed_at":
-			meta.ClaimedAt = val
-		case "depends_on":
-			meta.DependsOn = val
-		case "notify":
-			meta.Notify = val
-		case "kind":
-			meta.Kind = val
-		case "title":
-			meta.Title = val
-		case "short_id":
-			meta.ShortID = val
-		case "source_role":
-			meta.SourceRole = val
-		case "severity":
-			meta.Severity = val
-		case "class":
-			meta.Class = val
-		case "agent_id":
-			meta.AgentID = val
-		case "role_id":
-			meta.RoleID = val
-		case "mailbox_id":
-			meta.MailboxID = val
-		case "hook_id":
-			meta.HookID = val
-		case "convoy_id":
-			meta.ConvoyID = val
-		case "conflict":
-			if strings.EqualFold(val, "true") || strings.EqualFold(val, "yes") || val == "1" {
-				meta.Conflict = true
-			}
+		if key == "" {
+			continue
 		}
+		meta.noteKey(key)
+		if val == "" {
+			pending = key
+			continue
+		}
+		if listKeys[key] && strings.HasPrefix(val, "[") && strings.HasSuffix(val, "]") {
+			meta.SetList(key, splitInlineList(val))
+			continue
+		}
+		meta.Set(key, val)
 	}
+	flush()
 	return meta
 }
 
+// RenderMeta writes the front-matter block. Keys that were parsed keep
+// their original order; keys added since follow, well-known keys first.
 func RenderMeta(meta Meta) string {
 	lines := []string{"---"}
-	if meta.Cell != "" {
-		lines = append(lines, "cell: "+meta.Cell)
+	done := map[string]bool{}
+	emit := func(key string) {
+		if done[key] {
+			return
+		}
+		done[key] = true
+		if key == "conflict" {
+			if meta.Conflict {
+				lines = append(lines, "conflict: true")
+			}
+			return
+		}
+		if p := knownField(&meta, key); p != nil {
+			// A list is only current while the field still holds its first
+			// value; a field assigned directly replaces it.
+			if vals := meta.lists[key]; len(vals) > 1 && vals[0] == *p {
+				lines = append(lines, renderField(Field{Key: key, Values: vals, List: true})...)
+			} else if *p != "" {
+				lines = append(lines, key+": "+*p)
+			}
+			return
+		}
+		for _, f := range meta.Extra {
+			if f.Key == key {
+				lines = append(lines, renderField(f)...)
+				return
+			}
+		}
+	}
+	for _, key := range meta.order {
+		emit(key)
 	}
-	if meta.Role != "" {
-		lines = append(lines, "role: "+meta.Role)
+	for _, k := range knownKeys {
+		emit(k.key)
 	}
-	if meta.Scope != "" {
-		lines = append(lines, "scope: "+meta.Scope)
+	emit("conflict")
+	for _, f := range meta.Extra {
+		emit(f.Key)
 	}
-	if meta.Inbox != "" {
-		lines = append(lines, "inbox: "+meta.Inbox)
+	lines = append(lines, "---")
+	return strings.Join(lines, "\n")
+}
+
+func (m *Meta) noteKey(key string) {
+	for _, k := range m.order {
+		if k == key {
+			return
+		}
+	}
+	m.order = append(m.order, key)
+}
+
+func renderField(f Field) []string {
+	if !f.List {
+		if len(f.Values) == 0 || f.Values[0] == "" {
+			return []string{f.Key + ":"}
+		}
+		return []string{f.Key + ": " + f.Values[0]}
 	}
-	if meta.Outbox != "" {
-		lines = append(lines, "outbox: "+meta.Outbox)
+	out := []string{f.Key + ":"}
+	for _, v := range f.Values {
+		out = append(out, "  - "+v)
 	}
-	if meta.Promise != "" {
-		lines = append(lines, "promise: "+meta.Promise)
+	return out
+}
+
+func splitInlineList(val string) []string {
+	inner := strings.TrimSpace(val[1 : len(val)-1])
+	if inner == "" {
+		return []string{}
 	}
-	if meta.TurnID != "" {
-		lines = append(lines, "turn_id: "+meta.TurnID)
+	parts := strings.Split(inner, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.Trim(strings.TrimSpace(p), `"'`)
+		if p != "" {
+			out = append(out, p)
+		}
 	}
-	if meta.Worktree != "" {
-		lines = append(lines, "worktree: "+meta.Worktree)
+	return out
+}
+
+// Get returns the first value of key, or "" when it is unset.
+func (m Meta) Get(key string) string {
+	vals := m.List(key)
+	if len(vals) == 0 {
+		return ""
 	}
-	if meta.ClaimedBy != "" {
-		lines = append(lines, "claimed_by: "+meta.ClaimedBy)
+	return vals[0]
+}
+
+// List returns every value of key. Scalar keys yield a single value.
+func (m Meta) List(key string) []string {
+	if key == "conflict" {
+		if m.Conflict {
+			return []string{"true"}
+		}
+		return nil
 	}
-	if meta.ClaimedAt != "" {
-		lines = append(lines, "claimed_at: "+meta.ClaimedAt)
+	if p := knownField(&m, key); p != nil {
+		if vals := m.lists[key]; len(vals) > 0 && vals[0] == *p {
+			return append([]string(nil), vals...)
+		}
+		if *p == "" {
+			return nil
+		}
+		return []string{*p}
 	}
-	if meta.DependsOn != "" {
-		lines = append(lines, "depends_on: "+meta.DependsOn)
+	for _, f := range m.Extra {
+		if f.Key == key {
+			return append([]string(nil), f.Values...)
+		}
 	}
-	if meta.Notify != "" {
-		lines = append(lines, "notify: "+meta.Notify)
+	return nil
+}
+
+// Has reports whether key is present with a non-empty value.
+func (m Meta) Has(key string) bool {
+	return len(m.List(key)) > 0
+}
+
+// Bool interprets key as a boolean (true/yes/1).
+func (m Meta) Bool(key string) bool {
+	v := m.Get(key)
+	return strings.EqualFold(v, "true") || strings.EqualFold(v, "yes") || v == "1"
+}
+
+// Int interprets key as an integer.
+func (m Meta) Int(key string) (int, bool) {
+	n, err := strconv.Atoi(m.Get(key))
+	if err != nil {
+		return 0, false
 	}
-	if meta.Kind != "" {
-		lines = append(lines, "kind: "+meta.Kind)
+	return n, true
+}
+
+// Time interprets key as an RFC3339 timestamp.
+func (m Meta) Time(key string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, m.Get(key))
+	if err != nil {
+		return time.Time{}, false
 	}
-	if meta.Title != "" {
-		lines = append(lines, "title: "+meta.Title)
+	return t, true
+}
+
+// Set stores a scalar value for key, replacing any previous value.
+func (m *Meta) Set(key, value string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
 	}
-	if meta.ShortID != "" {
-		lines = append(lines, "short_id: "+meta.ShortID)
+	if key == "conflict" {
+		m.Conflict = strings.EqualFold(value, "true") || strings.EqualFold(value, "yes") || value == "1"
+		return
 	}
-	if meta.SourceRole != "" {
-		lines = append(lines, "source_role: "+meta.SourceRole)
+	if p := knownField(m, key); p != nil {
+		*p = value
+		m.dropList(key)
+		return
 	}
-	if meta.Severity != "" {
-		lines = append(lines, "severity: "+meta.Severity)
+	m.setExtra(Field{Key: key, Values: []string{value}})
+}
+
+// SetList stores a list value for key, replacing any previous value.
+func (m *Meta) SetList(key string, values []string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
 	}
-	if meta.Class != "" {
-		lines = append(lines, "class: "+meta.Class)
+	values = append([]string(nil), values...)
+	if p := knownField(m, key); p != nil {
+		*p = ""
+		if len(values) > 0 {
+			*p = values[0]
+		}
+		m.dropList(key)
+		if len(values) > 1 {
+			m.lists[key] = values
+		}
+		return
 	}
-	if meta.AgentID != "" {
-		lines = append(lines, "agent_id: "+meta.AgentID)
+	if key == "conflict" {
+		m.Conflict = len(values) > 0 && strings.EqualFold(values[0], "true")
+		return
 	}
-	if meta.RoleID != "" {
-		lines = append(lines, "role_id: "+meta.RoleID)
+	m.setExtra(Field{Key: key, Values: values, List: true})
+}
+
+// Delete removes key.
+func (m *Meta) Delete(key string) {
+	if key == "conflict" {
+		m.Conflict = false
+		return
 	}
-	if meta.MailboxID != "" {
-		lines = append(lines, "mailbox_id: "+meta.MailboxID)
+	if p := knownField(m, key); p != nil {
+		*p = ""
+		m.dropList(key)
+		return
 	}
-	if meta.HookID != "" {
-		lines = append(lines, "hook_id: "+meta.HookID)
+	for i, f := range m.Extra {
+		if f.Key == key {
+			m.Extra = append(m.Extra[:i:i], m.Extra[i+1:]...)
+			return
+		}
 	}
-	if meta.ConvoyID != "" {
-		lines = append(lines, "convoy_id: "+meta.ConvoyID)
+}
+
+// dropList removes key from the list store. The map is copied first because
+// Meta values are passed around by value and must not alias each other.
+func (m *Meta) dropList(key string) {
+	lists := make(map[string][]string, len(m.lists))
+	for k, v := range m.lists {
+		if k != key {
+			lists[k] = v
+		}
 	}
-	if meta.Conflict {
-		lines = append(lines, "conflict: true")
+	m.lists = lists
+}
+
+func (m *Meta) setExtra(f Field) {
+	extra := append([]Field(nil), m.Extra...)
+	for i := range extra {
+		if extra[i].Key == f.Key {
+			extra[i] = f
+			m.Extra = extra
+			return
+		}
 	}
-	lines = append(lines, "---")
-	return strings.Join(lines, "\n")
+	m.Extra = append(extra, f)
 }
 
 func StripMeta(desc string) string {
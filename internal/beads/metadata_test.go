@@ -0,0 +1,94 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMetaRoundTripPreservesUnknownKeys(t *testing.T) {
+	desc := strings.Join([]string{
+		"---",
+		"cell: api",
+		"owner_team: payments",
+		"notify: [alice, bob]",
+		"labels:",
+		"  - urgent",
+		"  - needs: review",
+		"role: builder",
+		"retries: 3",
+		"---",
+		"",
+		"body",
+	}, "\n")
+	meta := ParseMeta(desc)
+	if meta.Cell != "api" || meta.Role != "builder" {
+		t.Fatalf("unexpected known fields: %+v", meta)
+	}
+	if meta.Notify != "alice" || len(meta.List("notify")) != 2 {
+		t.Fatalf("expected notify list, got %q %v", meta.Notify, meta.List("notify"))
+	}
+	if got := meta.List("labels"); len(got) != 2 || got[1] != "needs: review" {
+		t.Fatalf("unexpected labels: %v", got)
+	}
+	if n, ok := meta.Int("retries"); !ok || n != 3 {
+		t.Fatalf("expected retries=3, got %d %v", n, ok)
+	}
+	meta.ClaimedBy = "agent-1"
+	again := ParseMeta(RenderMeta(meta))
+	if again.Get("owner_team") != "payments" || again.ClaimedBy != "agent-1" {
+		t.Fatalf("round trip lost fields: %s", RenderMeta(meta))
+	}
+	if len(again.Extra) != 3 || again.Extra[0].Key != "owner_team" || again.Extra[1].Key != "labels" || again.Extra[2].Key != "retries" {
+		t.Fatalf("unknown key order not preserved: %+v", again.Extra)
+	}
+	if got := again.List("notify"); len(got) != 2 || got[1] != "bob" {
+		t.Fatalf("notify list lost: %v", got)
+	}
+	if RenderMeta(again) != RenderMeta(meta) {
+		t.Fatalf("render not stable:\n%s\n---\n%s", RenderMeta(again), RenderMeta(meta))
+	}
+}
+
+func TestMetaSetDoesNotAliasCopies(t *testing.T) {
+	meta := ParseMeta("---\nlabels: [a, b]\n---")
+	copyMeta := meta
+	copyMeta.Set("labels", "c")
+	copyMeta.Delete("missing")
+	if got := meta.List("labels"); len(got) != 2 {
+		t.Fatalf("original mutated: %v", got)
+	}
+	if copyMeta.Get("labels") != "c" {
+		t.Fatalf("expected copy updated, got %v", copyMeta.List("labels"))
+	}
+}
+
+func TestMetaRoundTripScalarsListsAndOrder(t *testing.T) {
+	desc := strings.Join([]string{
+		"---",
+		"owner_team: payments",
+		"title: [api] add endpoint [v2]",
+		"notify:",
+		"  - alice",
+		"  - bob",
+		"cell: api",
+		"---",
+	}, "\n")
+	meta := ParseMeta(desc)
+	if meta.Title != "[api] add endpoint [v2]" {
+		t.Fatalf("bracketed scalar parsed as a list: %q", meta.Title)
+	}
+	if got := RenderMeta(meta); got != desc {
+		t.Fatalf("round trip changed the block:\n%s\nwant:\n%s", got, desc)
+	}
+
+	meta.Notify = "carol"
+	meta.Role = "builder"
+	again := ParseMeta(RenderMeta(meta))
+	if got := again.List("notify"); len(got) != 1 || got[0] != "carol" {
+		t.Fatalf("stale list overrode the field: %v\n%s", got, RenderMeta(meta))
+	}
+	want := "---\nowner_team: payments\ntitle: [api] add endpoint [v2]\nnotify: carol\ncell: api\nrole: builder\n---"
+	if got := RenderMeta(meta); got != want {
+		t.Fatalf("unexpected render:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,479 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed bead filter expression. Expressions compare issue fields
+// (id, title, type, status, priority, created_at, updated_at, deps, body) or
+// any front-matter key against a value:
+//
+//	type=assignment and role=reviewer and claimed_at<2h and scope^=services/pay
+//
+// Operators are = != ^= (prefix) $= (suffix) ~= (contains) < <= > >=. A bare
+// key matches when it is set. Terms combine with and, or, not, and
+// parentheses. Comparing a timestamp against a duration (2h, 3d) compares the
+// timestamp's age, so claimed_at<2h means "claimed within the last two hours".
+type Query struct {
+	root queryNode
+	now  func() time.Time
+}
+
+// SortKey orders issues by a field; Desc reverses it.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+type queryNode interface {
+	eval(q *Query, issue Issue, meta Meta) bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ inner queryNode }
+type hasNode struct{ field string }
+type cmpNode struct {
+	field string
+	op    string
+	value string
+}
+
+func (n andNode) eval(q *Query, issue Issue, meta Meta) bool {
+	return n.left.eval(q, issue, meta) && n.right.eval(q, issue, meta)
+}
+
+func (n orNode) eval(q *Query, issue Issue, meta Meta) bool {
+	return n.left.eval(q, issue, meta) || n.right.eval(q, issue, meta)
+}
+
+func (n notNode) eval(q *Query, issue Issue, meta Meta) bool {
+	return !n.inner.eval(q, issue, meta)
+}
+
+func (n hasNode) eval(q *Query, issue Issue, meta Meta) bool {
+	for _, v := range FieldValues(issue, meta, n.field) {
+		if strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (n cmpNode) eval(q *Query, issue Issue, meta Meta) bool {
+	values, want := FieldValues(issue, meta, n.field), n.value
+	if isPriorityField(n.field) {
+		switch n.op {
+		case "=", "!=", "<", "<=", ">", ">=":
+			// p0..p4 and 0..4 name the same priorities and order numerically.
+			for i := range values {
+				values[i] = priorityRank(values[i])
+			}
+			want = priorityRank(want)
+		}
+	}
+	if n.op == "!=" {
+		for _, v := range values {
+			if strings.EqualFold(v, want) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compareValue(q.now(), v, n.op, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseQuery parses a filter expression. An empty expression matches
+// everything.
+func ParseQuery(expr string) (*Query, error) {
+	q := &Query{now: time.Now}
+	toks, err := lexQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return q, nil
+	}
+	p := &queryParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("query: unexpected %q", p.toks[p.pos].text)
+	}
+	q.root = root
+	return q, nil
+}
+
+// Active reports whether the query filters anything.
+func (q *Query) Active() bool {
+	return q != nil && q.root != nil
+}
+
+// Match reports whether issue satisfies the query.
+func (q *Query) Match(issue Issue) bool {
+	return q.MatchMeta(issue, ParseMeta(issue.Description))
+}
+
+// MatchMeta is Match for callers that already parsed the front-matter.
+func (q *Query) MatchMeta(issue Issue, meta Meta) bool {
+	if q == nil || q.root == nil {
+		return true
+	}
+	return q.root.eval(q, issue, meta)
+}
+
+// Filter returns the issues that satisfy the query, keeping their order.
+func (q *Query) Filter(issues []Issue) []Issue {
+	out := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if q.Match(issue) {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// FieldValues returns the values of a named field. Issue fields take
+// precedence; any other name is looked up in the front-matter.
+func FieldValues(issue Issue, meta Meta, field string) []string {
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "id":
+		return []string{issue.ID}
+	case "title":
+		return []string{issue.Title}
+	case "type":
+		return []string{issue.Type}
+	case "status":
+		return []string{issue.Status}
+	case "priority":
+		return []string{issue.Priority}
+	case "created_at", "created":
+		return []string{issue.CreatedAt}
+	case "updated_at", "updated":
+		return []string{issue.UpdatedAt}
+	case "deps":
+		return append([]string(nil), issue.Deps...)
+	case "body":
+		return []string{strings.TrimSpace(StripMeta(issue.Description))}
+	case "description":
+		return []string{issue.Description}
+	}
+	return meta.List(field)
+}
+
+// FieldValue joins a field's values with commas for display.
+func FieldValue(issue Issue, meta Meta, field string) string {
+	return strings.Join(FieldValues(issue, meta, field), ",")
+}
+
+// ParseSort parses a comma-separated sort spec such as "priority,-created_at".
+// A leading "-" sorts that key in descending order.
+func ParseSort(spec string) []SortKey {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := SortKey{Field: part}
+		if strings.HasPrefix(part, "-") {
+			key = SortKey{Field: strings.TrimPrefix(part, "-"), Desc: true}
+		} else if strings.HasPrefix(part, "+") {
+			key.Field = strings.TrimPrefix(part, "+")
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// SortIssues sorts issues in place by keys. Ties keep listing order.
+func SortIssues(issues []Issue, keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	metas := make(map[string]Meta, len(issues))
+	for _, issue := range issues {
+		metas[issue.ID] = ParseMeta(issue.Description)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		for _, key := range keys {
+			a := FieldValue(issues[i], metas[issues[i].ID], key.Field)
+			b := FieldValue(issues[j], metas[issues[j].ID], key.Field)
+			if isPriorityField(key.Field) {
+				a, b = priorityRank(a), priorityRank(b)
+			}
+			c := compareOrdered(a, b)
+			if c == 0 {
+				continue
+			}
+			if key.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareValue(now time.Time, actual, op, want string) bool {
+	switch op {
+	case "=":
+		return strings.EqualFold(actual, want)
+	case "^=":
+		return strings.HasPrefix(strings.ToLower(actual), strings.ToLower(want))
+	case "$=":
+		return strings.HasSuffix(strings.ToLower(actual), strings.ToLower(want))
+	case "~=":
+		return strings.Contains(strings.ToLower(actual), strings.ToLower(want))
+	}
+	var c int
+	if d, ok := parseAge(want); ok {
+		ts, ok := parseQueryTime(actual)
+		if !ok {
+			return false
+		}
+		age := now.Sub(ts)
+		switch {
+		case age < d:
+			c = -1
+		case age > d:
+			c = 1
+		}
+	} else {
+		c = compareOrdered(actual, want)
+		if strings.TrimSpace(actual) == "" {
+			return false
+		}
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func isPriorityField(field string) bool {
+	return strings.EqualFold(strings.TrimSpace(field), "priority")
+}
+
+// priorityRank returns the number of a priority written "p2" or "2", and
+// any other value unchanged.
+func priorityRank(v string) string {
+	v = strings.TrimSpace(v)
+	n := strings.TrimPrefix(strings.ToLower(v), "p")
+	if _, err := strconv.Atoi(n); err != nil {
+		return v
+	}
+	return n
+}
+
+// compareOrdered compares numerically, then as timestamps, then as strings.
+func compareOrdered(a, b string) int {
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	if ta, ok := parseQueryTime(a); ok {
+		if tb, ok := parseQueryTime(b); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func parseQueryTime(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseAge parses Go durations plus a "d" suffix for days.
+func parseAge(v string) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if strings.HasSuffix(v, "d") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(v, "d"), 64)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(n * float64(24*time.Hour)), true
+	}
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return 0, false
+	}
+	d, err := time.ParseDuration(v)
+	return d, err == nil
+}
+
+type queryToken struct {
+	kind string // word, op, lparen, rparen
+	text string
+}
+
+var queryOps = []string{"!=", "^=", "$=", "~=", "<=", ">=", "=", "<", ">"}
+
+func lexQuery(expr string) ([]queryToken, error) {
+	var toks []queryToken
+	rs := []rune(expr)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, queryToken{kind: "lparen", text: "("})
+			i++
+		case r == ')':
+			toks = append(toks, queryToken{kind: "rparen", text: ")"})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("query: unterminated string")
+			}
+			toks = append(toks, queryToken{kind: "string", text: string(rs[i+1 : end])})
+			i = end + 1
+		default:
+			if op := matchQueryOp(rs[i:]); op != "" {
+				toks = append(toks, queryToken{kind: "op", text: op})
+				i += len(op)
+				continue
+			}
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && rs[i] != '(' && rs[i] != ')' && matchQueryOp(rs[i:]) == "" {
+				i++
+			}
+			toks = append(toks, queryToken{kind: "word", text: string(rs[start:i])})
+		}
+	}
+	return toks, nil
+}
+
+func matchQueryOp(rs []rune) string {
+	for _, op := range queryOps {
+		if len(rs) >= len(op) && string(rs[:len(op)]) == op {
+			return op
+		}
+	}
+	return ""
+}
+
+type queryParser struct {
+	toks []queryToken
+	pos  int
+}
+
+func (p *queryParser) peekKeyword(word string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == "word" && strings.EqualFold(p.toks[p.pos].text, word)
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("query: unexpected end of expression")
+	}
+	if p.peekKeyword("not") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	tok := p.toks[p.pos]
+	if tok.kind == "lparen" {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.toks) || p.toks[p.pos].kind != "rparen" {
+			return nil, fmt.Errorf("query: missing )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	if tok.kind != "word" {
+		return nil, fmt.Errorf("query: expected field, got %q", tok.text)
+	}
+	p.pos++
+	if p.pos >= len(p.toks) || p.toks[p.pos].kind != "op" {
+		return hasNode{field: tok.text}, nil
+	}
+	op := p.toks[p.pos].text
+	p.pos++
+	if p.pos >= len(p.toks) || (p.toks[p.pos].kind != "word" && p.toks[p.pos].kind != "string") {
+		return nil, fmt.Errorf("query: %s%s needs a value", tok.text, op)
+	}
+	value := p.toks[p.pos].text
+	p.pos++
+	return cmpNode{field: tok.text, op: op, value: value}, nil
+}
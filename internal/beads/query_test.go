@@ -0,0 +1,73 @@
+package beads
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryMatchesFieldsAndFrontMatter(t *testing.T) {
+	recent := time.Now().UTC().Add(-30 * time.Minute).Format(time.RFC3339)
+	old := time.Now().UTC().Add(-5 * time.Hour).Format(time.RFC3339)
+	issues := []Issue{
+		{ID: "a", Type: "assignment", Status: "open", Priority: "p1", Description: RenderMeta(Meta{Role: "reviewer", Scope: "services/payments", ClaimedAt: recent})},
+		{ID: "b", Type: "assignment", Status: "open", Priority: "p0", Description: RenderMeta(Meta{Role: "reviewer", Scope: "services/payments", ClaimedAt: old})},
+		{ID: "c", Type: "assignment", Status: "closed", Priority: "p2", Description: RenderMeta(Meta{Role: "builder", Scope: "web"})},
+		{ID: "d", Type: "task", Status: "open", Priority: "p2", Description: "---\nestimate: 5\n---"},
+	}
+	cases := []struct {
+		expr string
+		want []string
+	}{
+		{"type=assignment and role=reviewer and claimed_at<2h and scope^=services/pay", []string{"a"}},
+		{"claimed_at>2h", []string{"b"}},
+		{"not type=assignment or status=closed", []string{"c", "d"}},
+		{"(role=builder or role=reviewer) and status!=closed", []string{"a", "b"}},
+		{"estimate>=5", []string{"d"}},
+		{"claimed_at", []string{"a", "b"}},
+		{`scope~="pay"`, []string{"a", "b"}},
+		{"priority<2", []string{"a", "b"}},
+		{"priority>=p2", []string{"c", "d"}},
+		{"priority<=p1 and priority!=0", []string{"a"}},
+		{"priority=1", []string{"a"}},
+	}
+	for _, tc := range cases {
+		q, err := ParseQuery(tc.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.expr, err)
+		}
+		got := q.Filter(issues)
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: got %d issues, want %v", tc.expr, len(got), tc.want)
+		}
+		for i := range got {
+			if got[i].ID != tc.want[i] {
+				t.Fatalf("%q: got %s at %d, want %v", tc.expr, got[i].ID, i, tc.want)
+			}
+		}
+	}
+	for _, tc := range []struct{ expr, want string }{{"priority>=p3", "e"}, {"priority<p10", "abcde"}} {
+		q, err := ParseQuery(tc.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.expr, err)
+		}
+		got := ""
+		for _, issue := range q.Filter(append(issues, Issue{ID: "e", Priority: "p3"})) {
+			got += issue.ID
+		}
+		if got != tc.want {
+			t.Fatalf("%q: got %q, want %q", tc.expr, got, tc.want)
+		}
+	}
+	SortIssues(issues, ParseSort("priority,-id"))
+	if issues[0].ID != "b" || issues[1].ID != "a" || issues[2].ID != "d" || issues[3].ID != "c" {
+		t.Fatalf("unexpected sort order: %s %s %s %s", issues[0].ID, issues[1].ID, issues[2].ID, issues[3].ID)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, expr := range []string{"type=", "(type=task", "type=task and", `title="open`} {
+		if _, err := ParseQuery(expr); err == nil {
+			t.Fatalf("expected error for %q", expr)
+		}
+	}
+}
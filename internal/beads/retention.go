@@ -0,0 +1,230 @@
+package beads
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RollupKind is the front-matter kind of daily event summary beads.
+const RollupKind = "event_rollup"
+
+// RetentionPolicy controls how long event beads of one kind are kept.
+// Kind matches the event's front-matter kind; "*" matches any event kind
+// without a more specific policy.
+type RetentionPolicy struct {
+	Kind string
+	// KeepLast keeps only the newest N events per agent; older ones are
+	// deleted. Zero disables the limit.
+	KeepLast int
+	// CloseAfter closes open events older than this. Zero disables it.
+	CloseAfter time.Duration
+	// DeleteAfter hard-deletes closed events older than this.
+	DeleteAfter time.Duration
+	// Rollup records deleted events in a daily summary bead first.
+	Rollup bool
+}
+
+// DefaultRetentionPolicies covers the high-frequency telemetry that the stop
+// hook emits. Other events are kept until a policy names them.
+func DefaultRetentionPolicies() []RetentionPolicy {
+	return []RetentionPolicy{
+		{Kind: "agent_status", KeepLast: 20, CloseAfter: time.Hour, DeleteAfter: 24 * time.Hour, Rollup: true},
+		{Kind: "hook_idle", KeepLast: 20, CloseAfter: time.Hour, DeleteAfter: 24 * time.Hour, Rollup: true},
+		{Kind: "hook_claim", CloseAfter: 24 * time.Hour, DeleteAfter: 7 * 24 * time.Hour, Rollup: true},
+	}
+}
+
+// GCPlan lists the work a retention pass will do.
+type GCPlan struct {
+	Close   []Issue
+	Delete  []Issue
+	Rollups []Rollup
+}
+
+// Rollup summarizes deleted events of one kind on one day.
+type Rollup struct {
+	Day     string
+	Kind    string
+	Count   int
+	ByAgent map[string]int
+}
+
+// PlanGC applies policies to the event beads in issues. Rollup beads and
+// events without a matching policy are never touched.
+func PlanGC(issues []Issue, policies []RetentionPolicy, now time.Time) GCPlan {
+	byKind := map[string]RetentionPolicy{}
+	for _, p := range policies {
+		byKind[strings.ToLower(strings.TrimSpace(p.Kind))] = p
+	}
+	policyFor := func(kind string) (RetentionPolicy, bool) {
+		if p, ok := byKind[strings.ToLower(kind)]; ok {
+			return p, true
+		}
+		p, ok := byKind["*"]
+		return p, ok
+	}
+
+	type entry struct {
+		issue Issue
+		meta  Meta
+		at    time.Time
+	}
+	groups := map[string][]entry{}
+	var plan GCPlan
+	deleted := map[string]bool{}
+	rollups := map[string]*Rollup{}
+	drop := func(e entry, p RetentionPolicy) {
+		if deleted[e.issue.ID] {
+			return
+		}
+		deleted[e.issue.ID] = true
+		plan.Delete = append(plan.Delete, e.issue)
+		if !p.Rollup {
+			return
+		}
+		day := e.at.UTC().Format("2006-01-02")
+		key := day + "|" + e.meta.Kind
+		r := rollups[key]
+		if r == nil {
+			r = &Rollup{Day: day, Kind: e.meta.Kind, ByAgent: map[string]int{}}
+			rollups[key] = r
+		}
+		r.Count++
+		r.ByAgent[eventAgent(e.meta)]++
+	}
+
+	for _, issue := range issues {
+		if strings.ToLower(issue.Type) != "event" {
+			continue
+		}
+		meta := ParseMeta(issue.Description)
+		if meta.Kind == RollupKind {
+			continue
+		}
+		p, ok := policyFor(meta.Kind)
+		if !ok {
+			continue
+		}
+		at, ok := parseQueryTime(issue.CreatedAt)
+		if !ok {
+			continue
+		}
+		e := entry{issue: issue, meta: meta, at: at}
+		if p.KeepLast > 0 {
+			key := meta.Kind + "|" + eventAgent(meta)
+			groups[key] = append(groups[key], e)
+		}
+		age := now.Sub(at)
+		if IsClosed(issue.Status) {
+			closedAt := at
+			if t, ok := parseQueryTime(issue.UpdatedAt); ok {
+				closedAt = t
+			}
+			if p.DeleteAfter > 0 && now.Sub(closedAt) > p.DeleteAfter {
+				drop(e, p)
+			}
+			continue
+		}
+		if p.CloseAfter > 0 && age > p.CloseAfter {
+			if p.DeleteAfter > 0 && age > p.CloseAfter+p.DeleteAfter {
+				drop(e, p)
+				continue
+			}
+			plan.Close = append(plan.Close, issue)
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entries := groups[k]
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].at.After(entries[j].at) })
+		p, _ := policyFor(entries[0].meta.Kind)
+		for _, e := range entries[min(p.KeepLast, len(entries)):] {
+			drop(e, p)
+		}
+	}
+
+	closing := plan.Close[:0]
+	for _, issue := range plan.Close {
+		if !deleted[issue.ID] {
+			closing = append(closing, issue)
+		}
+	}
+	plan.Close = closing
+
+	rkeys := make([]string, 0, len(rollups))
+	for k := range rollups {
+		rkeys = append(rkeys, k)
+	}
+	sort.Strings(rkeys)
+	for _, k := range rkeys {
+		plan.Rollups = append(plan.Rollups, *rollups[k])
+	}
+	return plan
+}
+
+// RenderRollup renders a rollup bead description, merging counts from an
+// existing rollup description for the same day and kind.
+func RenderRollup(r Rollup, existing string) string {
+	counts := map[string]int{}
+	total := r.Count
+	if existing != "" {
+		prev := ParseMeta(existing)
+		if n, ok := prev.Int("rollup_count"); ok {
+			total += n
+		}
+		for _, line := range strings.Split(StripMeta(existing), "\n") {
+			line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+			parts := strings.SplitN(line, ": ", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if n, err := strconv.Atoi(parts[1]); err == nil {
+				counts[parts[0]] += n
+			}
+		}
+	}
+	for agent, n := range r.ByAgent {
+		counts[agent] += n
+	}
+	meta := Meta{Kind: RollupKind}
+	meta.Set("rollup_day", r.Day)
+	meta.Set("rollup_kind", r.Kind)
+	meta.Set("rollup_count", strconv.Itoa(total))
+	agents := make([]string, 0, len(counts))
+	for agent := range counts {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+	lines := []string{fmt.Sprintf("%d %s event(s) on %s", total, r.Kind, r.Day)}
+	for _, agent := range agents {
+		lines = append(lines, fmt.Sprintf("- %s: %d", agent, counts[agent]))
+	}
+	return RenderMeta(meta) + "\n\n" + strings.Join(lines, "\n")
+}
+
+// ParseDuration parses a Go duration or a whole number of days ("7d").
+func ParseDuration(v string) (time.Duration, error) {
+	d, ok := parseAge(v)
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", v)
+	}
+	return d, nil
+}
+
+func eventAgent(meta Meta) string {
+	if strings.TrimSpace(meta.Cell) != "" || strings.TrimSpace(meta.Role) != "" {
+		return meta.Cell + "/" + meta.Role
+	}
+	if strings.TrimSpace(meta.AgentID) != "" {
+		return meta.AgentID
+	}
+	return "-"
+}
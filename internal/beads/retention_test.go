@@ -0,0 +1,52 @@
+package beads
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlanGCAppliesPolicies(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	status := func(id string, age time.Duration, cell string) Issue {
+		return Issue{
+			ID:          id,
+			Type:        "event",
+			Status:      "open",
+			CreatedAt:   now.Add(-age).Format(time.RFC3339),
+			Description: RenderMeta(Meta{Kind: "agent_status", Cell: cell, Role: "builder"}),
+		}
+	}
+	var issues []Issue
+	for i := 0; i < 5; i++ {
+		issues = append(issues, status(fmt.Sprintf("s%d", i), time.Duration(i)*time.Minute, "api"))
+	}
+	issues = append(issues,
+		status("old", 3*time.Hour, "web"),
+		Issue{ID: "keep", Type: "event", Status: "open", CreatedAt: now.Add(-48 * time.Hour).Format(time.RFC3339), Description: RenderMeta(Meta{Kind: "assignment_complete"})},
+	)
+	policies := []RetentionPolicy{{Kind: "agent_status", KeepLast: 3, CloseAfter: time.Hour, DeleteAfter: 24 * time.Hour, Rollup: true}}
+	plan := PlanGC(issues, policies, now)
+
+	deleted := map[string]bool{}
+	for _, issue := range plan.Delete {
+		deleted[issue.ID] = true
+	}
+	if len(deleted) != 2 || !deleted["s3"] || !deleted["s4"] {
+		t.Fatalf("expected the two oldest api events deleted, got %v", deleted)
+	}
+	if len(plan.Close) != 1 || plan.Close[0].ID != "old" {
+		t.Fatalf("expected old web event closed, got %+v", plan.Close)
+	}
+	if len(plan.Rollups) != 1 || plan.Rollups[0].Count != 2 || plan.Rollups[0].ByAgent["api/builder"] != 2 {
+		t.Fatalf("unexpected rollups: %+v", plan.Rollups)
+	}
+
+	desc := RenderRollup(plan.Rollups[0], "")
+	merged := RenderRollup(plan.Rollups[0], desc)
+	meta := ParseMeta(merged)
+	if n, _ := meta.Int("rollup_count"); n != 4 || !strings.Contains(merged, "api/builder: 4") {
+		t.Fatalf("rollup merge failed:\n%s", merged)
+	}
+}
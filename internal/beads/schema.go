@@ -0,0 +1,177 @@
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Schema declares what a bead of one type must carry. Required and Allowed
+// name front-matter keys; Allowed also accepts "priority" for the issue's
+// priority field. Sections are markdown headings the body must contain.
+type Schema struct {
+	Type     string
+	Required []string
+	Allowed  map[string][]string
+	Sections []string
+}
+
+// Violation is a single schema failure on a bead.
+type Violation struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// SchemaError reports every violation found on a bead that was about to be
+// written.
+type SchemaError struct {
+	Type       string
+	Violations []Violation
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return fmt.Sprintf("%s bead is invalid: %s", e.Type, strings.Join(parts, "; "))
+}
+
+var (
+	priorities = []string{"p0", "p1", "p2", "p3"}
+	severities = []string{"low", "med", "medium", "high", "critical"}
+)
+
+// DefaultSchemas returns the built-in schemas. The "*" entry applies to every
+// type in addition to the type's own schema.
+func DefaultSchemas() map[string]Schema {
+	return map[string]Schema{
+		"*": {
+			Type:    "*",
+			Allowed: map[string][]string{"priority": priorities, "severity": severities},
+		},
+		"assignment": {
+			Type:     "assignment",
+			Required: []string{"cell", "role", "worktree", "inbox", "outbox", "promise"},
+		},
+		"request": {
+			Type:     "request",
+			Required: []string{"cell", "scope"},
+		},
+		"observation": {
+			Type:     "observation",
+			Required: []string{"cell", "severity"},
+		},
+		"event": {
+			Type:     "event",
+			Required: []string{"kind"},
+		},
+		"contract": {
+			Type:     "contract",
+			Sections: []string{"Acceptance Criteria"},
+		},
+		"contractproposal": {
+			Type:     "contractproposal",
+			Sections: []string{"Acceptance Criteria"},
+		},
+		"decision": {
+			Type:     "decision",
+			Sections: []string{"Acceptance Criteria"},
+		},
+		"migrationstep": {
+			Type:     "migrationstep",
+			Sections: []string{"Acceptance Criteria"},
+		},
+	}
+}
+
+// Validate checks a bead against the "*" schema and the schema for its type.
+// Types without a schema only get the "*" checks.
+func Validate(schemas map[string]Schema, issue Issue) []Violation {
+	beadType := strings.ToLower(strings.TrimSpace(issue.Type))
+	var out []Violation
+	for _, key := range []string{"*", beadType} {
+		schema, ok := schemas[key]
+		if !ok {
+			continue
+		}
+		for _, v := range schema.check(issue) {
+			v.ID = issue.ID
+			v.Type = beadType
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Check validates a bead that has not been created yet and returns a
+// *SchemaError when it breaks its schema.
+func Check(schemas map[string]Schema, issue Issue) error {
+	violations := Validate(schemas, issue)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &SchemaError{Type: strings.ToLower(issue.Type), Violations: violations}
+}
+
+func (s Schema) check(issue Issue) []Violation {
+	meta := ParseMeta(issue.Description)
+	var out []Violation
+	for _, key := range s.Required {
+		if strings.TrimSpace(meta.Get(key)) == "" {
+			out = append(out, Violation{Field: key, Message: "required"})
+		}
+	}
+	keys := make([]string, 0, len(s.Allowed))
+	for key := range s.Allowed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		allowed := s.Allowed[key]
+		value := meta.Get(key)
+		if key == "priority" {
+			value = issue.Priority
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || containsFold(allowed, value) {
+			continue
+		}
+		out = append(out, Violation{Field: key, Message: fmt.Sprintf("%q is not one of %s", value, strings.Join(allowed, ", "))})
+	}
+	body := StripMeta(issue.Description)
+	for _, section := range s.Sections {
+		if !hasSection(body, section) {
+			out = append(out, Violation{Field: "section", Message: fmt.Sprintf("missing %q section", section)})
+		}
+	}
+	return out
+}
+
+var sectionPatterns sync.Map // heading -> *regexp.Regexp
+
+func hasSection(body, heading string) bool {
+	if re, ok := sectionPatterns.Load(heading); ok {
+		return re.(*regexp.Regexp).MatchString(body)
+	}
+	re := regexp.MustCompile(`(?mi)^#+\s*` + regexp.QuoteMeta(heading) + `\s*$`)
+	sectionPatterns.Store(heading, re)
+	return re.MatchString(body)
+}
+
+func containsFold(values []string, v string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package beads
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateSchemas(t *testing.T) {
+	schemas := DefaultSchemas()
+	assignment := Issue{
+		ID:          "a1",
+		Type:        "assignment",
+		Priority:    "P9",
+		Description: RenderMeta(Meta{Cell: "api", Role: "builder", Inbox: "mail/inbox/t1.md"}),
+	}
+	var fields []string
+	for _, v := range Validate(schemas, assignment) {
+		fields = append(fields, v.Field)
+	}
+	want := []string{"priority", "worktree", "outbox", "promise"}
+	if len(fields) != len(want) {
+		t.Fatalf("expected %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, fields)
+		}
+	}
+
+	contract := Issue{Type: "contract", Description: RenderMeta(Meta{Cell: "api"}) + "\n\n## acceptance criteria\n- [ ] tests"}
+	if v := Validate(schemas, contract); len(v) != 0 {
+		t.Fatalf("expected contract to pass, got %v", v)
+	}
+	contract.Description = RenderMeta(Meta{Cell: "api"}) + "\n\nno sections"
+	err := Check(schemas, contract)
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Violations) != 1 || schemaErr.Violations[0].Field != "section" {
+		t.Fatalf("expected missing section error, got %v", err)
+	}
+
+	request := Issue{Type: "request", Description: RenderMeta(Meta{Cell: "api", Scope: "apps/api", Severity: "HIGH"})}
+	if err := Check(schemas, request); err != nil {
+		t.Fatalf("expected request to pass, got %v", err)
+	}
+}
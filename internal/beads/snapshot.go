@@ -0,0 +1,335 @@
+package beads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Snapshot is an in-memory view of every bead with indexes by type, cell,
+// and turn so hot paths can filter without re-listing or re-parsing
+// front-matter.
+type Snapshot struct {
+	mu     sync.RWMutex
+	issues []Issue
+	meta   []Meta
+	pos    map[string]int
+	byType map[string][]int
+	byCell map[string][]int
+	byTurn map[string][]int
+	dirty  bool
+}
+
+// NewSnapshot builds an indexed snapshot from a full listing.
+func NewSnapshot(issues []Issue) *Snapshot {
+	s := &Snapshot{
+		issues: make([]Issue, len(issues)),
+		meta:   make([]Meta, len(issues)),
+	}
+	copy(s.issues, issues)
+	for i := range s.issues {
+		s.meta[i] = ParseMeta(s.issues[i].Description)
+	}
+	s.reindex()
+	return s
+}
+
+// Issues returns a copy of every bead in listing order.
+func (s *Snapshot) Issues() []Issue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Issue, len(s.issues))
+	copy(out, s.issues)
+	return out
+}
+
+// Len returns the number of beads in the snapshot.
+func (s *Snapshot) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.issues)
+}
+
+// Get returns the bead with the given ID.
+func (s *Snapshot) Get(id string) (Issue, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.pos[id]
+	if !ok {
+		return Issue{}, false
+	}
+	return s.issues[i], true
+}
+
+// Meta returns the parsed front-matter for the bead with the given ID.
+func (s *Snapshot) Meta(id string) Meta {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i, ok := s.pos[id]
+	if !ok {
+		return Meta{}
+	}
+	return s.meta[i]
+}
+
+// ByType returns beads whose type matches (case-insensitive).
+func (s *Snapshot) ByType(issueType string) []Issue {
+	return s.lookup("type", strings.ToLower(strings.TrimSpace(issueType)))
+}
+
+// ByCell returns beads whose front-matter cell matches.
+func (s *Snapshot) ByCell(cell string) []Issue {
+	return s.lookup("cell", strings.TrimSpace(cell))
+}
+
+// ByTurn returns beads whose front-matter turn_id matches.
+func (s *Snapshot) ByTurn(turnID string) []Issue {
+	return s.lookup("turn", strings.TrimSpace(turnID))
+}
+
+func (s *Snapshot) lookup(index, key string) []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirty {
+		s.reindex()
+	}
+	var positions []int
+	switch index {
+	case "type":
+		positions = s.byType[key]
+	case "cell":
+		positions = s.byCell[key]
+	case "turn":
+		positions = s.byTurn[key]
+	}
+	out := make([]Issue, 0, len(positions))
+	for _, i := range positions {
+		out = append(out, s.issues[i])
+	}
+	return out
+}
+
+// upsert replaces or appends a bead after a write. Fields the backend omitted
+// from its write response are carried over from the cached copy.
+func (s *Snapshot) upsert(issue Issue) {
+	if strings.TrimSpace(issue.ID) == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta := ParseMeta(issue.Description)
+	if i, ok := s.pos[issue.ID]; ok {
+		old := s.issues[i]
+		if issue.Deps == nil {
+			issue.Deps = old.Deps
+		}
+		if issue.Description == "" {
+			issue.Description = old.Description
+			meta = s.meta[i]
+		}
+		if issue.CreatedAt == "" {
+			issue.CreatedAt = old.CreatedAt
+		}
+		if issue.Type == "" {
+			issue.Type = old.Type
+		}
+		if !strings.EqualFold(old.Type, issue.Type) || s.meta[i].Cell != meta.Cell || s.meta[i].TurnID != meta.TurnID {
+			s.dirty = true
+		}
+		s.issues[i] = issue
+		s.meta[i] = meta
+		return
+	}
+	s.issues = append(s.issues, issue)
+	s.meta = append(s.meta, meta)
+	s.index(len(s.issues) - 1)
+}
+
+func (s *Snapshot) reindex() {
+	s.pos = make(map[string]int, len(s.issues))
+	s.byType = map[string][]int{}
+	s.byCell = map[string][]int{}
+	s.byTurn = map[string][]int{}
+	for i := range s.issues {
+		s.index(i)
+	}
+	s.dirty = false
+}
+
+func (s *Snapshot) index(i int) {
+	s.pos[s.issues[i].ID] = i
+	s.byType[strings.ToLower(s.issues[i].Type)] = append(s.byType[strings.ToLower(s.issues[i].Type)], i)
+	if s.meta[i].Cell != "" {
+		s.byCell[s.meta[i].Cell] = append(s.byCell[s.meta[i].Cell], i)
+	}
+	if s.meta[i].TurnID != "" {
+		s.byTurn[s.meta[i].TurnID] = append(s.byTurn[s.meta[i].TurnID], i)
+	}
+}
+
+// CachedStore wraps a Store with a snapshot of List. The first read loads the
+// snapshot; writes made through the CachedStore update it in place, so later
+// reads in the same invocation never re-run the backend's list.
+type CachedStore struct {
+	inner Store
+	mu    sync.Mutex
+	snap  *Snapshot
+}
+
+// NewCachedStore wraps inner with a write-through snapshot cache.
+func NewCachedStore(inner Store) *CachedStore {
+	return &CachedStore{inner: inner}
+}
+
+// Invalidate drops the snapshot so the next read hits the backend. Polling
+// loops call this between iterations to see changes made by other processes.
+func (s *CachedStore) Invalidate() {
+	s.mu.Lock()
+	s.snap = nil
+	s.mu.Unlock()
+}
+
+// Snapshot returns the cached snapshot, loading it on first use.
+func (s *CachedStore) Snapshot(ctx context.Context) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snap != nil {
+		return s.snap, nil
+	}
+	issues, err := s.inner.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.snap = NewSnapshot(issues)
+	return s.snap, nil
+}
+
+func (s *CachedStore) record(issue Issue) {
+	s.mu.Lock()
+	snap := s.snap
+	s.mu.Unlock()
+	if snap != nil {
+		snap.upsert(issue)
+	}
+}
+
+func (s *CachedStore) Init(ctx context.Context) error {
+	s.Invalidate()
+	return s.inner.Init(ctx)
+}
+
+func (s *CachedStore) Create(ctx context.Context, req CreateRequest) (Issue, error) {
+	issue, err := s.inner.Create(ctx, req)
+	if err != nil {
+		return issue, err
+	}
+	if issue.Deps == nil && len(req.Deps) > 0 {
+		issue.Deps = append([]string(nil), req.Deps...)
+	}
+	s.record(issue)
+	return issue, nil
+}
+
+func (s *CachedStore) Update(ctx context.Context, id string, req UpdateRequest) (Issue, error) {
+	issue, err := s.inner.Update(ctx, id, req)
+	if err != nil {
+		return issue, err
+	}
+	s.record(issue)
+	return issue, nil
+}
+
+func (s *CachedStore) Close(ctx context.Context, id string, opts CloseOptions) (Issue, error) {
+	issue, err := s.inner.Close(ctx, id, opts)
+	if err != nil {
+		return issue, err
+	}
+	s.record(issue)
+	return issue, nil
+}
+
+func (s *CachedStore) Show(ctx context.Context, id string) (Issue, error) {
+	s.mu.Lock()
+	snap := s.snap
+	s.mu.Unlock()
+	if snap != nil {
+		if issue, ok := snap.Get(id); ok {
+			return issue, nil
+		}
+	}
+	return s.inner.Show(ctx, id)
+}
+
+func (s *CachedStore) List(ctx context.Context) ([]Issue, error) {
+	snap, err := s.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Issues(), nil
+}
+
+// Version forwards to the backend when it can report one.
+func (s *CachedStore) Version(ctx context.Context) (string, error) {
+	if v, ok := s.inner.(Versioner); ok {
+		return v.Version(ctx)
+	}
+	return "", fmt.Errorf("backend does not report versions")
+}
+
+// Capabilities forwards to the backend.
+func (s *CachedStore) Capabilities(ctx context.Context) (Capabilities, error) {
+	return storeCapabilities(ctx, s.inner, false)
+}
+
+// Probe forwards to the backend.
+func (s *CachedStore) Probe(ctx context.Context) (Capabilities, error) {
+	return storeCapabilities(ctx, s.inner, true)
+}
+
+// AddComment forwards to the backend. Threads are not part of the snapshot.
+func (s *CachedStore) AddComment(ctx context.Context, id, author, body string) (Comment, error) {
+	cm, ok := s.inner.(Commenter)
+	if !ok {
+		return Comment{}, ErrCommentsUnsupported
+	}
+	return cm.AddComment(ctx, id, author, body)
+}
+
+// Comments forwards to the backend.
+func (s *CachedStore) Comments(ctx context.Context, id string) ([]Comment, error) {
+	cm, ok := s.inner.(Commenter)
+	if !ok {
+		return nil, ErrCommentsUnsupported
+	}
+	return cm.Comments(ctx, id)
+}
+
+// Ready is delegated to the backend, which owns blocker semantics.
+func (s *CachedStore) Ready(ctx context.Context) ([]Issue, error) {
+	return s.inner.Ready(ctx)
+}
+
+func (s *CachedStore) DepAdd(ctx context.Context, id, dep string) error {
+	if err := s.inner.DepAdd(ctx, id, dep); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	snap := s.snap
+	s.mu.Unlock()
+	if snap != nil {
+		if issue, ok := snap.Get(id); ok {
+			issue.Deps = append(append([]string(nil), issue.Deps...), dep)
+			snap.upsert(issue)
+		}
+	}
+	return nil
+}
+
+func (s *CachedStore) Delete(ctx context.Context, ids []string, opts DeleteOptions) (string, error) {
+	out, err := s.inner.Delete(ctx, ids, opts)
+	if err == nil && !opts.DryRun {
+		s.Invalidate()
+	}
+	return out, err
+}
@@ -0,0 +1,55 @@
+package beads
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+type countingStore struct {
+	Store
+	lists int
+}
+
+func (s *countingStore) List(ctx context.Context) ([]Issue, error) {
+	s.lists++
+	return s.Store.List(ctx)
+}
+
+func TestCachedStoreWriteThrough(t *testing.T) {
+	inner := &countingStore{Store: NewFileStore(filepath.Join(t.TempDir(), "beads.jsonl"))}
+	client := Client{Store: NewCachedStore(inner)}
+	if _, err := client.Create(nil, CreateRequest{Title: "seed", Type: "task", Description: "---\ncell: alpha\nturn_id: t1\n---"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	snap, err := client.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	created, err := client.Create(nil, CreateRequest{Title: "assn", Type: "assignment", Description: "---\ncell: alpha\nturn_id: t1\n---"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := client.UpdateStatus(nil, created.ID, "in_progress"); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got := len(snap.ByTurn("t1")); got != 2 {
+		t.Fatalf("expected 2 beads in turn index, got %d", got)
+	}
+	if got := snap.ByType("ASSIGNMENT"); len(got) != 1 || got[0].Status != "in_progress" {
+		t.Fatalf("expected updated assignment in type index, got %+v", got)
+	}
+	if _, err := client.List(nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if inner.lists != 1 {
+		t.Fatalf("expected a single backend list, got %d", inner.lists)
+	}
+	client.Invalidate()
+	if _, err := client.List(nil); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if inner.lists != 2 {
+		t.Fatalf("expected invalidate to force a backend list, got %d", inner.lists)
+	}
+}
@@ -0,0 +1,68 @@
+package beads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Backend names accepted by Open and rig.json's beads_backend field.
+const (
+	BackendBD    = "bd"
+	BackendJSONL = "jsonl"
+)
+
+// Store is the issue-store backend behind Client. The bd CLI is one
+// implementation; FileStore is a pure-Go alternative that needs no external
+// binary.
+type Store interface {
+	Init(ctx context.Context) error
+	Create(ctx context.Context, req CreateRequest) (Issue, error)
+	Update(ctx context.Context, id string, req UpdateRequest) (Issue, error)
+	Close(ctx context.Context, id string, opts CloseOptions) (Issue, error)
+	Show(ctx context.Context, id string) (Issue, error)
+	List(ctx context.Context) ([]Issue, error)
+	Ready(ctx context.Context) ([]Issue, error)
+	DepAdd(ctx context.Context, id, dep string) error
+	Delete(ctx context.Context, ids []string, opts DeleteOptions) (string, error)
+}
+
+// UpdateRequest contains the fields to change on an existing bead.
+// Empty fields are left untouched.
+type UpdateRequest struct {
+	Status      string
+	Description string
+	// Reason explains a status change in the bead's history; backends do
+	// not store it.
+	Reason string
+	// From is the status the caller last read, recorded as the history
+	// entry's prior status. When empty the Client looks it up.
+	From string
+}
+
+// ValidBackend reports whether name selects a known Store backend.
+// An empty name selects the bd CLI.
+func ValidBackend(name string) bool {
+	switch strings.TrimSpace(name) {
+	case "", BackendBD, BackendJSONL:
+		return true
+	default:
+		return false
+	}
+}
+
+// Open returns a Client for the named backend. An empty backend selects the
+// bd CLI running in repo; "jsonl" selects a FileStore at path.
+func Open(backend, repo, path string) (Client, error) {
+	switch strings.TrimSpace(backend) {
+	case "", BackendBD:
+		return Client{RepoPath: repo, Store: BDStore{RepoPath: repo}}, nil
+	case BackendJSONL:
+		if strings.TrimSpace(path) == "" {
+			return Client{}, fmt.Errorf("jsonl beads backend requires a store path")
+		}
+		return Client{RepoPath: repo, Store: NewFileStore(path)}, nil
+	default:
+		return Client{}, fmt.Errorf("unknown beads backend %q (want %s or %s)", backend, BackendBD, BackendJSONL)
+	}
+}
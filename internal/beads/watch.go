@@ -0,0 +1,166 @@
+package beads
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// ChangeKind classifies an entry in the bead change feed.
+type ChangeKind string
+
+const (
+	ChangeCreated ChangeKind = "created"
+	ChangeUpdated ChangeKind = "updated"
+	ChangeClosed  ChangeKind = "closed"
+	ChangeDeleted ChangeKind = "deleted"
+)
+
+// Change describes one bead transition between two snapshots. Old is nil for
+// created beads and New is nil for deleted ones.
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	ID     string     `json:"id"`
+	Type   string     `json:"type,omitempty"`
+	Fields []string   `json:"fields,omitempty"`
+	Old    *Issue     `json:"old,omitempty"`
+	New    *Issue     `json:"new,omitempty"`
+	At     time.Time  `json:"at"`
+}
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// Interval between polls. Defaults to two seconds.
+	Interval time.Duration
+	// Initial emits a created change for every bead present at start.
+	Initial bool
+	// OnError receives list failures; Watch keeps polling after them.
+	OnError func(error)
+}
+
+// Versioner is implemented by stores that can cheaply report whether their
+// contents changed, letting Watch skip a full listing between changes.
+type Versioner interface {
+	Version(ctx context.Context) (string, error)
+}
+
+// Watch polls client and streams the differences between successive
+// listings until ctx is done, then closes the channel.
+func Watch(ctx context.Context, client Client, opts WatchOptions) <-chan Change {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	out := make(chan Change)
+	go func() {
+		defer close(out)
+		var prev []Issue
+		primed := false
+		lastVersion := ""
+		for {
+			version, versioned := storeVersion(ctx, client)
+			if !primed || !versioned || version != lastVersion {
+				client.Invalidate()
+				issues, err := client.List(ctx)
+				if err != nil {
+					if opts.OnError != nil {
+						opts.OnError(err)
+					}
+				} else {
+					var changes []Change
+					if primed || opts.Initial {
+						changes = Diff(prev, issues)
+					}
+					prev = issues
+					primed = true
+					lastVersion = version
+					for _, change := range changes {
+						select {
+						case out <- change:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(opts.Interval):
+			}
+		}
+	}()
+	return out
+}
+
+func storeVersion(ctx context.Context, client Client) (string, bool) {
+	v, ok := client.store().(Versioner)
+	if !ok {
+		return "", false
+	}
+	version, err := v.Version(ctx)
+	if err != nil {
+		return "", false
+	}
+	return version, true
+}
+
+// Diff returns the changes that turn listing old into listing new, in the
+// order beads appear in new followed by deletions.
+func Diff(old, new []Issue) []Change {
+	now := time.Now().UTC()
+	before := make(map[string]Issue, len(old))
+	for _, issue := range old {
+		before[issue.ID] = issue
+	}
+	seen := make(map[string]bool, len(new))
+	var out []Change
+	for _, issue := range new {
+		seen[issue.ID] = true
+		cur := issue
+		prev, ok := before[issue.ID]
+		if !ok {
+			out = append(out, Change{Kind: ChangeCreated, ID: issue.ID, Type: issue.Type, New: &cur, At: now})
+			continue
+		}
+		fields := changedFields(prev, issue)
+		if len(fields) == 0 {
+			continue
+		}
+		kind := ChangeUpdated
+		if IsClosed(issue.Status) && !IsClosed(prev.Status) {
+			kind = ChangeClosed
+		}
+		out = append(out, Change{Kind: kind, ID: issue.ID, Type: issue.Type, Fields: fields, Old: &prev, New: &cur, At: now})
+	}
+	for _, issue := range old {
+		if seen[issue.ID] {
+			continue
+		}
+		prev := issue
+		out = append(out, Change{Kind: ChangeDeleted, ID: issue.ID, Type: issue.Type, Old: &prev, At: now})
+	}
+	return out
+}
+
+func changedFields(a, b Issue) []string {
+	var fields []string
+	if a.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if a.Priority != b.Priority {
+		fields = append(fields, "priority")
+	}
+	if a.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	if !reflect.DeepEqual(a.Deps, b.Deps) && (len(a.Deps) > 0 || len(b.Deps) > 0) {
+		fields = append(fields, "deps")
+	}
+	return fields
+}
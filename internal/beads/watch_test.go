@@ -0,0 +1,60 @@
+package beads
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiffClassifiesChanges(t *testing.T) {
+	old := []Issue{
+		{ID: "a", Status: "open", Title: "A"},
+		{ID: "b", Status: "open", Title: "B"},
+		{ID: "c", Status: "open", Title: "C"},
+	}
+	cur := []Issue{
+		{ID: "a", Status: "open", Title: "A"},
+		{ID: "b", Status: "closed", Title: "B"},
+		{ID: "d", Status: "open", Title: "D"},
+	}
+	changes := Diff(old, cur)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Kind != ChangeClosed || changes[0].ID != "b" || changes[0].Old.Status != "open" || changes[0].New.Status != "closed" {
+		t.Fatalf("unexpected close change: %+v", changes[0])
+	}
+	if changes[1].Kind != ChangeCreated || changes[1].ID != "d" {
+		t.Fatalf("unexpected create change: %+v", changes[1])
+	}
+	if changes[2].Kind != ChangeDeleted || changes[2].ID != "c" {
+		t.Fatalf("unexpected delete change: %+v", changes[2])
+	}
+}
+
+func TestWatchStreamsFileStoreChanges(t *testing.T) {
+	client, err := Open(BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	feed := Watch(ctx, client, WatchOptions{Interval: 10 * time.Millisecond})
+	time.Sleep(50 * time.Millisecond)
+	issue, err := client.Create(nil, CreateRequest{Title: "Task", Type: "task"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	change := <-feed
+	if change.Kind != ChangeCreated || change.ID != issue.ID {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+	if _, err := client.Close(nil, issue.ID, "done"); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	change = <-feed
+	if change.Kind != ChangeClosed || change.ID != issue.ID {
+		t.Fatalf("unexpected change: %+v", change)
+	}
+}
@@ -16,10 +16,13 @@ func usage() string {
 mforge — Microforge (Beads + tmux + Claude Code hooks)
 
 Usage:
-  mforge init <rig> --repo <path>
+  mforge init <rig> --repo <path> [--beads bd|jsonl]
+  mforge plan [--file microforge.yaml] [--json]
+  mforge apply [--file microforge.yaml]
 
-  mforge cell add <cell> --scope <path-prefix>
-  mforge cell bootstrap <cell> [--architect] [--single]
+  mforge cell add <cell> --scope <path-prefix> [--include <glob>]... [--exclude <glob>]...
+  mforge cell bootstrap <cell> [--architect] [--single] [--role <name>]
+  mforge cell config <cell> [--json]
 
   mforge agent spawn <cell> <role>
   mforge agent stop  <cell> <role>
@@ -40,10 +43,10 @@ Usage:
   mforge task update --task <id> --scope <path-prefix>
   mforge task complete --task <id> [--reason <text>] [--force]
   mforge task delete --task <id> [--reason <text>] [--force] [--cascade] [--hard] [--dry-run]
-  mforge task list
+  mforge task list [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
   mforge task decompose --task <id> --titles <a,b,c> [--kind <kind>]
   mforge scope list
-  mforge scope show --scope <path-prefix>
+  mforge scope show --scope <path>
   mforge engine run [--wait]
   mforge engine emit --type <event> [--scope <path>] [--title <text>] [--source <role>] [--payload <json>]
   mforge engine drain [--keep]
@@ -53,7 +56,7 @@ Usage:
   mforge quick-assign <bead-id> <cell> [--role <role>] [--promise <token>]
   mforge quick-assign <bead-id> <cell> [--role <role>] [--promise <token>]
   mforge request create --cell <cell> --role <role> --severity <sev> --priority <p> --scope <path> --payload <json>
-  mforge request list [--cell <cell>] [--status <status>] [--priority <p>]
+  mforge request list [--cell <cell>] [--status <status>] [--priority <p>] [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
   mforge request triage --request <id> --action create-task|merge|block
   mforge monitor run-tests <cell> --cmd <command...> [--severity <sev>] [--priority <p>] [--scope <path>]
   mforge monitor run <cell> --cmd <command...> [--severity <sev>] [--priority <p>] [--scope <path>] [--observation <text>]
@@ -82,12 +85,20 @@ Usage:
   mforge checkpoint [--message <text>]
   mforge wait [--turn <id>] [--interval <seconds>]
 mforge bead create --type <type> --title <title> [--cell <cell>] [--turn <id>] ...
-mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--turn <id>]
+mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--turn <id>] [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
 mforge bead show <id>
 mforge bead close <id>
 mforge bead status <id> <status>
 mforge bead triage --id <id> --cell <cell> --role <role>
   mforge bead dep add <id> <dep>
+  mforge bead comment <id> [--body <text>] [--author <name>] [--json]
+  mforge bead history <id> [--json]
+  mforge bead capabilities [--refresh] [--json]
+  mforge bead watch [--interval <seconds>] [--type <type>] [--where <expr>] [--initial]
+  mforge bead gc [--dry-run] [--kind <kind>]
+  mforge bead lint [--type <type>] [--all] [--fix] [--json]
+  mforge bead export (--epic <id>|--turn <id>|--cell <cell>) [--out <path>]
+  mforge bead import --file <path> [--dry-run]
   mforge bead template --type <type> --title <title> --cell <cell>
   mforge review create --title <title> --cell <cell>
   mforge pr create --title <title> --cell <cell> [--url <url>]
@@ -106,10 +117,15 @@ mforge bead triage --id <id> --cell <cell> --role <role>
   mforge library start [--addr <addr>]
   mforge library query --q <query> [--service <name>] [--addr <addr>]
   mforge watch [--interval <seconds>] [--role <role>] [--fswatch] [--tui]
-  mforge tui [--interval <seconds>] [--remote] [--watch] [--role <role>]
+  mforge tui [--interval <seconds>] [--remote] [--watch] [--role <role>] [--query <expr>]
   mforge migrate beads [--all]
   mforge migrate rig [--all]
+  mforge doctor [--fix] [--json]
   mforge rig <list|delete|rename|backup|restore|message> ...
+  mforge secret <set|list|rm> [<name>]
+  mforge policy show [--cell <cell>] [--json]
+  mforge policy check --cell <cell> --role <role> --tool <tool> [--command <cmd>] [--path <path>]
+  mforge guardrails report [--since <dur>] [--cell <cell>] [--role <role>] [--recent <n>] [--json]
   mforge ssh <rig> --cmd <command...> [--tty]
   mforge context <get|set|unset|list> [<rig>]
   mforge completions <install|path|bash|zsh>
@@ -117,10 +133,12 @@ mforge bead triage --id <id> --cell <cell> --role <role>
   # Invoked by Claude Code hooks:
   mforge hook stop [--role <role>]
   mforge hook guardrails
+  mforge hook heartbeat
   mforge hook emit --event <name>
 
 Environment:
   MF_HOME   override default home (~/.microforge)
+  MF_SECRETS_PASSPHRASE   unlock the secrets file for secret:NAME references
 `)
 }
 
@@ -134,6 +152,8 @@ func Run(args []string) error {
 		home = v
 	}
 
+	subcmd.ResetBeadsCache()
+
 	cmd := args[0]
 	rest := args[1:]
 	activeRig := ""
@@ -388,6 +408,42 @@ func Run(args []string) error {
 			return nil
 		}
 		return subcmd.Contract(home, rest)
+	case "doctor":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Doctor(home, rest)
+	case "plan":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Plan(home, rest)
+	case "apply":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Apply(home, rest)
+	case "policy":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Policy(home, rest)
+	case "guardrails":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Guardrails(home, rest)
+	case "secret":
+		if hasHelpFlag(rest) {
+			printCommandUsage(cmd)
+			return nil
+		}
+		return subcmd.Secret(home, rest)
 	case "hook":
 		if hasHelpFlag(rest) {
 			printCommandUsage(cmd)
@@ -404,11 +460,11 @@ func maybeInjectActiveRig(cmd string, rest []string, activeRig string) []string
 		return rest
 	}
 	switch cmd {
-	case "cell", "task", "request", "epic", "manager", "turn", "bead", "review", "pr", "merge", "coordinator", "digest", "build", "deploy", "contract", "architect", "library", "engine", "convoy", "scope", "monitor", "round", "migrate", "watch":
+	case "cell", "task", "request", "epic", "manager", "turn", "bead", "review", "pr", "merge", "coordinator", "digest", "build", "deploy", "contract", "architect", "library", "engine", "convoy", "scope", "monitor", "round", "migrate", "watch", "policy", "guardrails":
 		return injectAfterSubcommand(rest, activeRig)
 	case "agent":
 		return injectAfterSubcommandWithOverride(rest, activeRig, map[string]bool{"create": true, "bootstrap": true})
-	case "assign", "quick-assign", "wait", "report", "ssh", "checkpoint", "tui", "status":
+	case "assign", "quick-assign", "wait", "report", "ssh", "checkpoint", "tui", "status", "doctor":
 		return injectAtStart(rest, activeRig)
 	default:
 		return rest
@@ -430,7 +486,7 @@ func injectAfterSubcommandWithOverride(rest []string, activeRig string, subcomma
 
 func requiresActiveRig(cmd string) bool {
 	switch cmd {
-	case "cell", "agent", "task", "request", "epic", "manager", "turn", "bead", "review", "pr", "merge", "coordinator", "digest", "build", "deploy", "contract", "architect", "library", "engine", "convoy", "scope", "monitor", "assign", "quick-assign", "wait", "report", "ssh", "round", "checkpoint", "tui", "watch", "status":
+	case "cell", "agent", "task", "request", "epic", "manager", "turn", "bead", "review", "pr", "merge", "coordinator", "digest", "build", "deploy", "contract", "architect", "library", "engine", "convoy", "scope", "monitor", "assign", "quick-assign", "wait", "report", "ssh", "round", "checkpoint", "tui", "watch", "status", "doctor", "policy", "guardrails":
 		return true
 	default:
 		return false
@@ -479,12 +535,13 @@ func printCommandUsage(cmd string) {
 func commandUsage(cmd string) (string, bool) {
 	switch cmd {
 	case "init":
-		return "mforge init <rig> --repo <path>", true
+		return "mforge init <rig> --repo <path> [--beads bd|jsonl]", true
 	case "cell":
 		return strings.TrimSpace(`
-mforge cell add <cell> --scope <path-prefix>
-mforge cell bootstrap <cell> [--architect] [--single]
+mforge cell add <cell> --scope <path-prefix> [--include <glob>]... [--exclude <glob>]...
+mforge cell bootstrap <cell> [--architect] [--single] [--role <name>]
 mforge cell agent-file <cell> --role <role>
+mforge cell config <cell> [--json]
 `), true
 	case "agent":
 		return strings.TrimSpace(`
@@ -504,20 +561,39 @@ mforge agent status [--cell <cell>] [--role <role>] [--remote] [--json]
 `), true
 	case "status":
 		return "mforge status [--cell <cell>] [--role <role>] [--json]", true
+	case "doctor":
+		return "mforge doctor [--fix] [--json]", true
+	case "plan":
+		return "mforge plan [--file microforge.yaml] [--json]", true
+	case "apply":
+		return "mforge apply [--file microforge.yaml]", true
+	case "policy":
+		return strings.TrimSpace(`
+mforge policy show [--cell <cell>] [--json]
+mforge policy check --cell <cell> --role <role> --tool <tool> [--command <cmd>] [--path <path>]
+`), true
+	case "guardrails":
+		return "mforge guardrails report [--since <dur>] [--cell <cell>] [--role <role>] [--recent <n>] [--json]", true
+	case "secret":
+		return strings.TrimSpace(`
+mforge secret set <name> < value
+mforge secret list
+mforge secret rm <name>
+`), true
 	case "task":
 		return strings.TrimSpace(`
 mforge task create --title <t> [--body <md>] [--scope <path-prefix>] [--kind improve|fix|review|monitor|doc]
 mforge task update --task <id> --scope <path-prefix>
 mforge task complete --task <id> [--reason <text>] [--force]
 mforge task delete --task <id> [--reason <text>] [--force] [--cascade] [--hard] [--dry-run]
-mforge task list
+mforge task list [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
 mforge task split --task <id> --cells <a,b,c>
 mforge task decompose --task <id> --titles <a,b,c> [--kind <kind>]
 `), true
 	case "scope":
 		return strings.TrimSpace(`
 mforge scope list
-mforge scope show --scope <path-prefix>
+mforge scope show --scope <path>
 `), true
 	case "engine":
 		return strings.TrimSpace(`
@@ -536,7 +612,7 @@ mforge engine drain [--keep]
 	case "request":
 		return strings.TrimSpace(`
 mforge request create --cell <cell> --role <role> --severity <sev> --priority <p> --scope <path> --payload <json>
-mforge request list [--cell <cell>] [--status <status>] [--priority <p>]
+mforge request list [--cell <cell>] [--status <status>] [--priority <p>] [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
 mforge request triage --request <id> --action create-task|merge|block
 `), true
 	case "monitor":
@@ -580,12 +656,20 @@ mforge round merge --feature <branch> [--base <branch>]
 	case "bead":
 		return strings.TrimSpace(`
 mforge bead create --type <type> --title <title> [--priority <p>] [--status <status>] [--cell <cell>] [--role <role>] [--scope <path>] [--turn <id>] [--severity <sev>] [--description <text>] [--acceptance <text>] [--compat <text>] [--links <text>] [--deps <a,b,c>]
-mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--priority <p>] [--turn <id>]
+mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--priority <p>] [--turn <id>] [--where <expr>] [--sort <fields>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]
 mforge bead show <id>
 mforge bead close <id> [--reason <text>]
 mforge bead status <id> <status> [--reason <text>]
 mforge bead triage --id <id> --cell <cell> --role <role> [--turn <id>] [--promise <token>]
 mforge bead dep add <id> <dep>
+mforge bead comment <id> [--body <text>] [--author <name>] [--json]
+mforge bead history <id> [--json]
+mforge bead capabilities [--refresh] [--json]
+mforge bead watch [--interval <seconds>] [--type <type>] [--where <expr>] [--initial]
+mforge bead gc [--dry-run] [--kind <kind>]
+mforge bead lint [--type <type>] [--all] [--fix] [--json]
+mforge bead export (--epic <id>|--turn <id>|--cell <cell>) [--out <path>]
+mforge bead import --file <path> [--dry-run]
 mforge bead template --type <type> --title <title> --cell <cell> [--scope <path>] [--priority <p>] [--turn <id>]
 `), true
 	case "review":
@@ -626,7 +710,7 @@ mforge library query --q <query> [--service <name>] [--addr <addr>]
 	case "migrate":
 		return "mforge migrate beads [--all]\nmforge migrate rig [--all]", true
 	case "tui":
-		return "mforge tui [--interval <seconds>] [--remote] [--watch] [--role <role>]", true
+		return "mforge tui [--interval <seconds>] [--remote] [--watch] [--role <role>] [--query <expr>]", true
 	case "context":
 		return strings.TrimSpace(`
 mforge context get
@@ -660,6 +744,7 @@ mforge completions zsh
 		return strings.TrimSpace(`
 mforge hook stop [--role <role>]
 mforge hook guardrails
+mforge hook heartbeat
 mforge hook emit --event <name>
 `), true
 	default:
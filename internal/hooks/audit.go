@@ -0,0 +1,220 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+// GuardrailRecord is one guardrail decision in the rig's guardrails.jsonl.
+type GuardrailRecord struct {
+	Timestamp string `json:"timestamp"`
+	Cell      string `json:"cell"`
+	Role      string `json:"role"`
+	AgentID   string `json:"agent_id,omitempty"`
+	TurnID    string `json:"turn_id,omitempty"`
+	Tool      string `json:"tool"`
+	Input     string `json:"input,omitempty"`
+	Decision  string `json:"decision"`
+	Rule      string `json:"rule,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// maxAuditInput bounds the tool input summary stored per decision.
+const maxAuditInput = 200
+
+// maxAuditBytes is the size at which guardrails.jsonl rotates to
+// guardrails.jsonl.1.
+const maxAuditBytes = 16 << 20
+
+// AuditGuardrailDecision appends the decision to the rig's guardrail audit
+// log. When a denial brings the agent to its denials-per-turn threshold, a
+// guardrail_denials event is emitted once for that turn.
+func AuditGuardrailDecision(ctx context.Context, client beads.Client, identity AgentIdentity, in ClaudeHookInput, dec DecisionResponse) error {
+	if identity.RigHome == "" || identity.RigName == "" {
+		return nil
+	}
+	turnID := currentTurnID(identity)
+	rec := GuardrailRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Cell:      identity.CellName,
+		Role:      identity.Role,
+		AgentID:   identity.AgentID,
+		TurnID:    turnID,
+		Tool:      strings.TrimSpace(in.ToolName),
+		Input:     summarizeToolInput(in),
+		Decision:  dec.Decision,
+		Rule:      dec.Rule,
+		Reason:    dec.Reason,
+	}
+	if err := appendGuardrailRecord(rig.GuardrailsAuditPath(identity.RigHome, identity.RigName), rec); err != nil {
+		return err
+	}
+	if dec.Decision != "deny" {
+		return nil
+	}
+	count := countTurnDenial(identity, turnID)
+	threshold := identityConfig(identity).DenialsPerTurn
+	if threshold > 0 && count == threshold {
+		emitDenialThresholdEvent(ctx, client, identity, turnID, count, threshold, rec)
+	}
+	return nil
+}
+
+func appendGuardrailRecord(path string, rec GuardrailRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := util.RotateFile(path, maxAuditBytes); err != nil {
+		return err
+	}
+	// Inputs can carry commands and paths, so the log is private; Chmod
+	// also tightens logs created before it was.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Chmod(0o600); err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// ReadGuardrailAudit loads the rig's guardrail audit log, including the
+// rotated generation, oldest first. A missing log is empty; malformed lines
+// are skipped.
+func ReadGuardrailAudit(home, rigName string) ([]GuardrailRecord, error) {
+	path := rig.GuardrailsAuditPath(home, rigName)
+	var out []GuardrailRecord
+	for _, p := range []string{path + ".1", path} {
+		recs, err := readGuardrailRecords(p)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, recs...)
+	}
+	return out, nil
+}
+
+func readGuardrailRecords(path string) ([]GuardrailRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var out []GuardrailRecord
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var rec GuardrailRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out, sc.Err()
+}
+
+// turnDenials is the per-agent denial counter kept next to the heartbeat,
+// so the threshold check does not rescan the audit log.
+type turnDenials struct {
+	TurnID string `json:"turn_id"`
+	Count  int    `json:"count"`
+}
+
+// countTurnDenial records one denial for the agent's current turn and
+// returns the turn's total.
+func countTurnDenial(identity AgentIdentity, turnID string) int {
+	base := heartbeatDir(identity)
+	if base == "" {
+		return 0
+	}
+	path := filepath.Join(base, "denials.json")
+	var state turnDenials
+	if b, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(b, &state)
+	}
+	if state.TurnID != turnID {
+		state = turnDenials{TurnID: turnID}
+	}
+	state.Count++
+	if err := util.EnsureDir(base); err == nil {
+		if b, err := json.Marshal(state); err == nil {
+			_ = util.AtomicWriteFile(path, b, 0o644)
+		}
+	}
+	return state.Count
+}
+
+func emitDenialThresholdEvent(ctx context.Context, client beads.Client, identity AgentIdentity, turnID string, count, threshold int, last GuardrailRecord) {
+	fields := map[string]string{
+		"denials":   fmt.Sprint(count),
+		"threshold": fmt.Sprint(threshold),
+		"rule":      last.Rule,
+		"reason":    last.Reason,
+	}
+	if journalEvent(identity, "guardrail_denials", turnID, fields) {
+		return
+	}
+	descMeta := beads.Meta{
+		Cell:    identity.CellName,
+		Role:    identity.Role,
+		Scope:   identity.Scope,
+		TurnID:  turnID,
+		Kind:    "guardrail_denials",
+		AgentID: identity.AgentID,
+		HookID:  identity.HookID,
+	}
+	body := fmt.Sprintf("denials=%d\nthreshold=%d\nlast_tool=%s\nlast_rule=%s\nlast_reason=%s", count, threshold, last.Tool, last.Rule, last.Reason)
+	desc := beads.RenderMeta(descMeta) + "\n\n" + body
+	_, _ = client.Create(ctx, beads.CreateRequest{
+		Title:       fmt.Sprintf("Guardrail denials %s/%s", identity.CellName, identity.Role),
+		Type:        "event",
+		Priority:    "p2",
+		Status:      "open",
+		Description: desc,
+	})
+}
+
+// summarizeToolInput keeps the part of a tool input a reviewer needs: the
+// command, path, or URL, shortened to maxAuditInput.
+func summarizeToolInput(in ClaudeHookInput) string {
+	s := ""
+	switch strings.TrimSpace(in.ToolName) {
+	case "Bash", "PermissionRequest":
+		s = extractToolCommand(in)
+	default:
+		s = extractToolPath(in)
+		if m, ok := in.ToolInput.(map[string]any); ok && s == "" {
+			for _, k := range []string{"url", "pattern", "query"} {
+				if v, ok := m[k].(string); ok && v != "" {
+					s = v
+					break
+				}
+			}
+		}
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxAuditInput {
+		s = string(r[:maxAuditInput]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,75 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func TestAuditGuardrailDecisionAndDenialThreshold(t *testing.T) {
+	t.Setenv("CLAUDE_TOOL_INPUT_FILE_PATH", "")
+	t.Setenv("CLAUDE_TOOL_INPUT_COMMAND", "")
+	home := t.TempDir()
+	cfg := rig.DefaultRigConfig("r", "/repo")
+	cfg.CellDefaults = &rig.CellOverrides{Health: &rig.HealthThresholds{DenialsPerTurn: 2}}
+	if err := util.EnsureDir(rig.RigDir(home, "r")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.SaveRigConfig(rig.RigConfigPath(home, "r"), cfg); err != nil {
+		t.Fatal(err)
+	}
+	client, err := beads.Open(beads.BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := AgentIdentity{RigHome: home, RigName: "r", CellName: "api", Role: "builder", Scope: "svc", Worktree: t.TempDir()}
+	ctx := context.Background()
+	calls := []string{"go test ./...", "curl a", "curl b", "curl c", "curl d"}
+	for _, cmd := range calls {
+		in := ClaudeHookInput{ToolName: "Bash", ToolInput: map[string]any{"command": cmd}}
+		dec, err := GuardrailsHook(in, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := AuditGuardrailDecision(ctx, client, id, in, dec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := ReadGuardrailAudit(home, "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(calls) {
+		t.Fatalf("expected %d records, got %d", len(calls), len(records))
+	}
+	if info, err := os.Stat(rig.GuardrailsAuditPath(home, "r")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a private audit log, got %v (%v)", info, err)
+	}
+	if records[0].Decision != "allow" || records[1].Decision != "deny" || records[1].Rule != "allowed-commands" || records[1].Input != "curl a" {
+		t.Fatalf("unexpected records: %+v", records[:2])
+	}
+
+	issues, err := client.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := 0
+	for _, issue := range issues {
+		if strings.Contains(issue.Description, "guardrail_denials") {
+			events++
+			if !strings.Contains(issue.Description, "denials=2") {
+				t.Fatalf("event should fire on the second denial: %s", issue.Description)
+			}
+		}
+	}
+	if events != 1 {
+		t.Fatalf("expected one guardrail_denials event, got %d", events)
+	}
+}
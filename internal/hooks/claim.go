@@ -0,0 +1,222 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+// LeaseKey is the front-matter key holding the claim lease expiry.
+const LeaseKey = "lease_expires_at"
+
+// DefaultLeaseTTL is how long a claim stays valid without a heartbeat.
+// MF_CLAIM_LEASE overrides it with a Go duration (e.g. "45m").
+const DefaultLeaseTTL = 30 * time.Minute
+
+// ErrClaimHeld is returned when another agent holds an unexpired lease.
+var ErrClaimHeld = errors.New("assignment claimed by another agent")
+
+// ErrClaimLost is returned when a claim write was overtaken by another agent.
+var ErrClaimLost = errors.New("assignment claim lost to another agent")
+
+// ClaimOwner returns the claimed_by value for an agent.
+func ClaimOwner(identity AgentIdentity) string {
+	return fmt.Sprintf("%s/%s", identity.CellName, identity.Role)
+}
+
+// LeaseTTL returns the configured claim lease duration.
+func LeaseTTL() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("MF_CLAIM_LEASE")); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultLeaseTTL
+}
+
+// LeaseExpired reports whether meta carries a lease that ended before now.
+// Claims written before leases existed have no expiry and never expire.
+func LeaseExpired(meta beads.Meta, now time.Time) bool {
+	exp, ok := meta.Time(LeaseKey)
+	return ok && now.After(exp)
+}
+
+// leaseHeldByOther reports whether meta is claimed by someone other than
+// owner under a lease that is still live.
+func leaseHeldByOther(meta beads.Meta, owner string, now time.Time) bool {
+	claimedBy := strings.TrimSpace(meta.ClaimedBy)
+	if claimedBy == "" || strings.EqualFold(claimedBy, owner) {
+		return false
+	}
+	return !LeaseExpired(meta, now)
+}
+
+// ClaimAssignment atomically claims an assignment for identity. The claim is
+// made under a per-assignment lock file, re-reads the bead from the store,
+// refuses live leases held by others, writes status, owner, and expiry in a
+// single update, and then reads the bead back to verify the write won.
+func ClaimAssignment(ctx context.Context, client beads.Client, identity AgentIdentity, id string) (beads.Issue, beads.Meta, error) {
+	unlock, err := lockClaim(ctx, identity.RigHome, identity.RigName, id)
+	if err != nil {
+		return beads.Issue{}, beads.Meta{}, err
+	}
+	defer unlock()
+
+	owner := ClaimOwner(identity)
+	now := time.Now().UTC()
+	client.Invalidate()
+	issue, err := client.Show(ctx, id)
+	if err != nil {
+		return beads.Issue{}, beads.Meta{}, err
+	}
+	if beads.IsClosed(issue.Status) {
+		return beads.Issue{}, beads.Meta{}, fmt.Errorf("assignment %s is %s", id, issue.Status)
+	}
+	meta := beads.ParseMeta(issue.Description)
+	if leaseHeldByOther(meta, owner, now) {
+		return beads.Issue{}, beads.Meta{}, fmt.Errorf("%w: %s held by %s", ErrClaimHeld, id, meta.ClaimedBy)
+	}
+	if !strings.EqualFold(strings.TrimSpace(meta.ClaimedBy), owner) {
+		meta.ClaimedBy = owner
+		meta.ClaimedAt = now.Format(time.RFC3339)
+	}
+	if strings.TrimSpace(meta.ClaimedAt) == "" {
+		meta.ClaimedAt = now.Format(time.RFC3339)
+	}
+	expiry := now.Add(LeaseTTL()).Format(time.RFC3339)
+	meta.Set(LeaseKey, expiry)
+
+	if err := client.Require(ctx, beads.CapUpdateDescription); err != nil {
+		if !errors.Is(err, beads.ErrUpdateDescriptionUnsupported) {
+			return beads.Issue{}, beads.Meta{}, err
+		}
+		// Older bd cannot rewrite descriptions; fall back to a status-only
+		// claim, which is still serialized by the lock.
+		if _, err := client.Update(ctx, id, beads.UpdateRequest{Status: "in_progress", Reason: "claimed", From: issue.Status}); err != nil {
+			return beads.Issue{}, beads.Meta{}, err
+		}
+		issue.Status = "in_progress"
+		return issue, meta, nil
+	}
+	desc := renderWithBody(meta, issue.Description)
+	if _, err := client.Update(ctx, id, beads.UpdateRequest{Status: "in_progress", Description: desc, Reason: "claimed", From: issue.Status}); err != nil {
+		return beads.Issue{}, beads.Meta{}, err
+	}
+
+	client.Invalidate()
+	verified, err := client.Show(ctx, id)
+	if err != nil {
+		return beads.Issue{}, beads.Meta{}, err
+	}
+	got := beads.ParseMeta(verified.Description)
+	if !strings.EqualFold(got.ClaimedBy, owner) || got.Get(LeaseKey) != expiry {
+		return beads.Issue{}, beads.Meta{}, fmt.Errorf("%w: %s now held by %s", ErrClaimLost, id, got.ClaimedBy)
+	}
+	return verified, got, nil
+}
+
+// RenewLease extends identity's lease on an assignment and returns the new
+// expiry. It fails with ErrClaimLost when the assignment is no longer owned
+// by identity.
+func RenewLease(ctx context.Context, client beads.Client, identity AgentIdentity, id string) (time.Time, error) {
+	unlock, err := lockClaim(ctx, identity.RigHome, identity.RigName, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer unlock()
+
+	client.Invalidate()
+	issue, err := client.Show(ctx, id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	meta := beads.ParseMeta(issue.Description)
+	if beads.IsClosed(issue.Status) || !strings.EqualFold(strings.TrimSpace(meta.ClaimedBy), ClaimOwner(identity)) {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrClaimLost, id)
+	}
+	expiry := time.Now().UTC().Add(LeaseTTL())
+	meta.Set(LeaseKey, expiry.Format(time.RFC3339))
+	if _, err := client.UpdateDescription(ctx, id, renderWithBody(meta, issue.Description)); err != nil {
+		return time.Time{}, err
+	}
+	return expiry, nil
+}
+
+// ReleaseExpiredLeases reopens in-progress assignments whose lease has
+// expired, clearing the claim so another agent can pick them up. It returns
+// the IDs it released.
+func ReleaseExpiredLeases(ctx context.Context, client beads.Client, home, rigName string, issues []beads.Issue) ([]string, error) {
+	var released []string
+	now := time.Now().UTC()
+	for _, issue := range issues {
+		if strings.ToLower(issue.Type) != "assignment" || beads.IsClosed(issue.Status) {
+			continue
+		}
+		if !LeaseExpired(beads.ParseMeta(issue.Description), now) {
+			continue
+		}
+		ok, err := releaseLease(ctx, client, home, rigName, issue.ID, now)
+		if err != nil {
+			return released, err
+		}
+		if ok {
+			released = append(released, issue.ID)
+		}
+	}
+	return released, nil
+}
+
+func releaseLease(ctx context.Context, client beads.Client, home, rigName, id string, now time.Time) (bool, error) {
+	unlock, err := lockClaim(ctx, home, rigName, id)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	client.Invalidate()
+	issue, err := client.Show(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	meta := beads.ParseMeta(issue.Description)
+	if beads.IsClosed(issue.Status) || !LeaseExpired(meta, now) {
+		return false, nil
+	}
+	meta.Delete("claimed_by")
+	meta.Delete("claimed_at")
+	meta.Delete(LeaseKey)
+	if _, err := client.Update(ctx, id, beads.UpdateRequest{Status: "open", Description: renderWithBody(meta, issue.Description), Reason: "lease expired", From: issue.Status}); err != nil {
+		return false, fmt.Errorf("releasing lease on %s: %w", id, err)
+	}
+	return true, nil
+}
+
+// lockClaim serializes claim writes for one assignment across processes that
+// share the rig home. Without a rig home there is nothing to lock against,
+// and the post-write verification alone guards the claim.
+func lockClaim(ctx context.Context, home, rigName, id string) (func(), error) {
+	if strings.TrimSpace(home) == "" || strings.TrimSpace(rigName) == "" {
+		return func() {}, nil
+	}
+	path := rig.ClaimLockPath(home, rigName, id)
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return util.LockFile(ctx, path)
+}
+
+func renderWithBody(meta beads.Meta, desc string) string {
+	out := beads.RenderMeta(meta)
+	if body := strings.TrimSpace(beads.StripMeta(desc)); body != "" {
+		out += "\n\n" + body
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package hooks
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+)
+
+func newClaimFixture(t *testing.T) (beads.Client, string, string) {
+	t.Helper()
+	home := t.TempDir()
+	client, err := beads.Open(beads.BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	meta := beads.Meta{Cell: "api", Role: "builder"}
+	issue, err := client.Create(nil, beads.CreateRequest{
+		Title:       "Assignment",
+		Type:        "assignment",
+		Status:      "open",
+		Description: beads.RenderMeta(meta) + "\n\nbody",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return client, home, issue.ID
+}
+
+func TestClaimAssignmentSingleWinner(t *testing.T) {
+	client, home, id := newClaimFixture(t)
+	agents := []AgentIdentity{
+		{RigHome: home, RigName: "r", CellName: "api", Role: "builder"},
+		{RigHome: home, RigName: "r", CellName: "api", Role: "cell"},
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(agents))
+	for i := range agents {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = ClaimAssignment(nil, client, agents[i], id)
+		}(i)
+	}
+	wg.Wait()
+	wins := 0
+	for _, err := range errs {
+		if err == nil {
+			wins++
+			continue
+		}
+		if !errors.Is(err, ErrClaimHeld) && !errors.Is(err, ErrClaimLost) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if wins != 1 {
+		t.Fatalf("expected exactly one winner, got %d (%v)", wins, errs)
+	}
+	issue, err := client.Show(nil, id)
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	meta := beads.ParseMeta(issue.Description)
+	if issue.Status != "in_progress" || meta.ClaimedBy == "" || !meta.Has(LeaseKey) {
+		t.Fatalf("claim not recorded: %s %+v", issue.Status, meta)
+	}
+	if beads.StripMeta(issue.Description) == "" {
+		t.Fatalf("claim dropped body")
+	}
+}
+
+func TestReleaseExpiredLeases(t *testing.T) {
+	t.Setenv("MF_CLAIM_LEASE", "1ms")
+	client, home, id := newClaimFixture(t)
+	identity := AgentIdentity{RigHome: home, RigName: "r", CellName: "api", Role: "builder"}
+	if _, _, err := ClaimAssignment(nil, client, identity, id); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	issues, err := client.List(nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	released, err := ReleaseExpiredLeases(nil, client, home, "r", issues)
+	if err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if len(released) != 1 || released[0] != id {
+		t.Fatalf("expected %s released, got %v", id, released)
+	}
+	issue, _ := client.Show(nil, id)
+	if meta := beads.ParseMeta(issue.Description); issue.Status != "open" || meta.ClaimedBy != "" {
+		t.Fatalf("lease not cleared: %s %+v", issue.Status, meta)
+	}
+	if _, err := RenewLease(nil, client, identity, id); !errors.Is(err, ErrClaimLost) {
+		t.Fatalf("expected renew to report lost claim, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func TestGuardrailsUseRoleRegistry(t *testing.T) {
+	t.Setenv("CLAUDE_TOOL_INPUT_FILE_PATH", "")
+	t.Setenv("CLAUDE_TOOL_INPUT_COMMAND", "")
+	home := t.TempDir()
+	cfg := rig.DefaultRigConfig("r", "/repo")
+	cfg.Roles = []rig.RoleSpec{
+		{Name: "security-auditor", Access: rig.RoleReadOnly, Bash: rig.BashAllowlist, AllowedCommands: []string{"rg"}},
+		{Name: "perf-tuner", Bash: rig.BashAny},
+	}
+	if err := util.EnsureDir(rig.RigDir(home, "r")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.SaveRigConfig(rig.RigConfigPath(home, "r"), cfg); err != nil {
+		t.Fatal(err)
+	}
+	wt := t.TempDir()
+	id := func(role string) AgentIdentity {
+		return AgentIdentity{RigHome: home, RigName: "r", Role: role, Scope: "svc", Worktree: wt}
+	}
+	cases := []struct {
+		role, tool string
+		input      map[string]any
+		allow      bool
+	}{
+		{"security-auditor", "Write", map[string]any{"file_path": wt + "/svc/a.go"}, false},
+		{"security-auditor", "Bash", map[string]any{"command": "rg secret"}, true},
+		{"security-auditor", "Bash", map[string]any{"command": "go test ./..."}, false},
+		{"perf-tuner", "Bash", map[string]any{"command": "perf record"}, true},
+		{"perf-tuner", "Write", map[string]any{"file_path": wt + "/other/a.go"}, false},
+		{"reviewer", "Bash", map[string]any{"command": "ls"}, false},
+		{"builder", "Bash", map[string]any{"command": "curl x"}, false},
+		{"builder", "Bash", map[string]any{"command": "go test ./..."}, true},
+	}
+	for _, c := range cases {
+		resp, err := GuardrailsHook(ClaudeHookInput{ToolName: c.tool, ToolInput: c.input}, id(c.role))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (resp.Decision == "allow") != c.allow {
+			t.Fatalf("%s %s %v: got %s (%s)", c.role, c.tool, c.input, resp.Decision, resp.Reason)
+		}
+	}
+}
@@ -6,9 +6,11 @@ package hooks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -36,39 +38,46 @@ type StopHookResponse struct {
 type DecisionResponse struct {
 	Decision string `json:"decision"`
 	Reason   string `json:"reason,omitempty"`
+	// Rule names the policy rule ("<source>:<name>") or built-in check
+	// behind the decision for the audit log; it is not sent to Claude.
+	Rule string `json:"-"`
 }
 
 // AgentIdentity contains the identity and context for an agent within a cell.
 // It is stored in .mf/active-agent.json and used by hooks to determine
 // agent role, scope, and mail paths.
 type AgentIdentity struct {
-	RigName     string `json:"rig_name"`
-	RigHome     string `json:"rig_home"`
-	RepoPath    string `json:"repo_path"`
-	CellName    string `json:"cell_name"`
-	Role        string `json:"role"`
-	Scope       string `json:"scope"`
-	Worktree    string `json:"worktree_path"`
-	TmuxSession string `json:"tmux_session"`
-	Inbox       string `json:"inbox"`
-	Outbox      string `json:"outbox"`
-	Archive     string `json:"archive"`
-	AgentID     string `json:"agent_id,omitempty"`
-	RoleID      string `json:"role_id,omitempty"`
-	MailboxID   string `json:"mailbox_id,omitempty"`
-	HookID      string `json:"hook_id,omitempty"`
-	Class       string `json:"class,omitempty"`
+	SchemaVersion int    `json:"schema_version,omitempty"`
+	RigName       string `json:"rig_name"`
+	RigHome       string `json:"rig_home"`
+	RepoPath      string `json:"repo_path"`
+	CellName      string `json:"cell_name"`
+	Role          string `json:"role"`
+	Scope         string `json:"scope"`
+	Worktree      string `json:"worktree_path"`
+	TmuxSession   string `json:"tmux_session"`
+	Inbox         string `json:"inbox"`
+	Outbox        string `json:"outbox"`
+	Archive       string `json:"archive"`
+	AgentID       string `json:"agent_id,omitempty"`
+	RoleID        string `json:"role_id,omitempty"`
+	MailboxID     string `json:"mailbox_id,omitempty"`
+	HookID        string `json:"hook_id,omitempty"`
+	Class         string `json:"class,omitempty"`
 }
 
 // LoadIdentityFromCWD loads the agent identity from .mf/active-agent.json
-// in the given working directory. Returns an error if the file is missing
-// or malformed.
+// in the given working directory, migrating an older schema in place.
+// Returns an error if the file is missing, malformed, or too new.
 func LoadIdentityFromCWD(cwd string) (AgentIdentity, error) {
 	p := filepath.Join(cwd, ".mf", "active-agent.json")
-	b, err := os.ReadFile(p)
-	if err != nil {
+	if _, err := os.Stat(p); err != nil {
 		return AgentIdentity{}, fmt.Errorf("missing %s: %w", p, err)
 	}
+	b, err := rig.MigrateFile(rig.SchemaAgent, p)
+	if err != nil {
+		return AgentIdentity{}, err
+	}
 	var id AgentIdentity
 	if err := json.Unmarshal(b, &id); err != nil {
 		return AgentIdentity{}, err
@@ -86,6 +95,8 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 		return StopHookResponse{}, err
 	}
 	turnID := currentTurnID(identity)
+	owner := ClaimOwner(identity)
+	now := time.Now().UTC()
 	var chosen beads.Issue
 	var meta beads.Meta
 	for _, issue := range ready {
@@ -93,8 +104,7 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 			continue
 		}
 		m := beads.ParseMeta(issue.Description)
-		claimID := fmt.Sprintf("%s/%s", identity.CellName, identity.Role)
-		if strings.TrimSpace(m.ClaimedBy) != "" && !strings.EqualFold(m.ClaimedBy, claimID) {
+		if leaseHeldByOther(m, owner, now) {
 			continue
 		}
 		if m.Cell != "" && m.Cell != identity.CellName {
@@ -106,8 +116,15 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 		if turnID != "" && m.TurnID != "" && m.TurnID != turnID {
 			continue
 		}
-		chosen = issue
-		meta = m
+		claimed, claimedMeta, err := ClaimAssignment(ctx, client, identity, issue.ID)
+		if err != nil {
+			if errors.Is(err, ErrClaimHeld) || errors.Is(err, ErrClaimLost) {
+				continue
+			}
+			return StopHookResponse{}, err
+		}
+		chosen = claimed
+		meta = claimedMeta
 		break
 	}
 	if chosen.ID == "" {
@@ -121,7 +138,6 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 		}
 		return StopHookResponse{Continue: false}, nil
 	}
-	_, _ = client.UpdateStatus(ctx, chosen.ID, "in_progress")
 
 	inboxRel := meta.Inbox
 	if strings.TrimSpace(inboxRel) == "" {
@@ -141,16 +157,11 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 	if strings.TrimSpace(meta.DependsOn) == "" && len(chosen.Deps) > 0 {
 		meta.DependsOn = strings.Join(chosen.Deps, ",")
 	}
-	if strings.TrimSpace(meta.ClaimedBy) == "" {
-		meta.ClaimedBy = fmt.Sprintf("%s/%s", identity.CellName, identity.Role)
-	}
-	if strings.TrimSpace(meta.ClaimedAt) == "" {
-		meta.ClaimedAt = time.Now().UTC().Format(time.RFC3339)
-	}
 
 	body := strings.TrimSpace(beads.StripMeta(chosen.Description))
 	inboxAbs := filepath.Join(identity.Worktree, inboxRel)
-	mail := renderMail(identity, chosen.ID, chosen.Type, chosen.Title, body, outboxRel, promise, meta.DependsOn, meta.ClaimedBy, meta.ClaimedAt)
+	thread := threadFor(ctx, client, chosen)
+	mail := renderMail(identity, chosen.ID, chosen.Type, chosen.Title, body, outboxRel, promise, meta.DependsOn, meta.ClaimedBy, meta.ClaimedAt, thread)
 	if err := util.AtomicWriteFile(inboxAbs, []byte(mail), 0o644); err != nil {
 		return StopHookResponse{}, err
 	}
@@ -165,7 +176,12 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 		reason = "CONTEXT RESET REQUIRED: Drop prior task context. Start fresh with this assignment only.\n" + reason
 	}
 
-	UpdateHeartbeat(identity, "claimed", chosen.ID, turnID, "")
+	writeHeartbeat(identity, AgentHeartbeat{
+		Status:         "claimed",
+		AssignmentID:   chosen.ID,
+		TurnID:         turnID,
+		LeaseExpiresAt: meta.Get(LeaseKey),
+	})
 	emitAgentStatusEvent(ctx, client, identity, turnID, "claimed", chosen.ID)
 	return StopHookResponse{
 		Continue: true,
@@ -174,6 +190,9 @@ func StopHook(ctx context.Context, client beads.Client, identity AgentIdentity)
 }
 
 func emitAgentStatusEvent(ctx context.Context, client beads.Client, identity AgentIdentity, turnID, status, assignmentID string) {
+	if journalEvent(identity, "agent_status", turnID, map[string]string{"status": status, "assignment_id": assignmentID}) {
+		return
+	}
 	descMeta := beads.Meta{
 		Cell:    identity.CellName,
 		Role:    identity.Role,
@@ -202,11 +221,11 @@ func updateAssignmentClaim(ctx context.Context, client beads.Client, issue beads
 	if strings.TrimSpace(body) != "" {
 		desc += "\n\n" + strings.TrimSpace(body)
 	}
-	if _, err := client.UpdateDescription(ctx, issue.ID, desc); err != nil {
-		if err == beads.ErrUpdateDescriptionUnsupported {
-			return
-		}
+	// Without description updates the claim lives in mail only.
+	if client.Require(ctx, beads.CapUpdateDescription) != nil {
+		return
 	}
+	client.TryUpdate(ctx, issue.ID, beads.UpdateRequest{Description: desc})
 }
 
 func ralphLoopEnabled() bool {
@@ -240,18 +259,12 @@ func updateHookBead(ctx context.Context, client beads.Client, identity AgentIden
 		Kind:    "hook",
 	}
 	desc := beads.RenderMeta(descMeta) + "\n\n" + mail
-	if _, err := client.UpdateDescription(ctx, identity.HookID, desc); err != nil {
-		if err == beads.ErrUpdateDescriptionUnsupported {
-			_, _ = client.Create(ctx, beads.CreateRequest{
-				Title:       fmt.Sprintf("Hook event %s/%s", identity.CellName, identity.Role),
-				Type:        "hook",
-				Priority:    "p2",
-				Status:      "open",
-				Description: desc,
-				Deps:        []string{"related:" + issue.ID},
-			})
-		}
+	// The hook bead mirrors the inbox; when bd cannot rewrite it the inbox
+	// file is the only copy rather than a new hook bead per turn.
+	if client.Require(ctx, beads.CapUpdateDescription) != nil {
+		return
 	}
+	client.TryUpdate(ctx, identity.HookID, beads.UpdateRequest{Description: desc})
 }
 
 func emitHookEvent(ctx context.Context, client beads.Client, identity AgentIdentity, issue beads.Issue, meta beads.Meta) {
@@ -288,20 +301,16 @@ func updateHookIdle(ctx context.Context, client beads.Client, identity AgentIden
 		Kind:   "hook",
 	}
 	desc := beads.RenderMeta(descMeta) + "\n\nIDLE"
-	if _, err := client.UpdateDescription(ctx, identity.HookID, desc); err != nil {
-		if err == beads.ErrUpdateDescriptionUnsupported {
-			_, _ = client.Create(ctx, beads.CreateRequest{
-				Title:       fmt.Sprintf("Hook idle %s/%s", identity.CellName, identity.Role),
-				Type:        "hook",
-				Priority:    "p3",
-				Status:      "open",
-				Description: desc,
-			})
-		}
+	if client.Require(ctx, beads.CapUpdateDescription) != nil {
+		return
 	}
+	client.TryUpdate(ctx, identity.HookID, beads.UpdateRequest{Description: desc})
 }
 
 func emitHookIdleEvent(ctx context.Context, client beads.Client, identity AgentIdentity, turnID string) {
+	if journalEvent(identity, "hook_idle", turnID, nil) {
+		return
+	}
 	descMeta := beads.Meta{
 		Cell:    identity.CellName,
 		Role:    identity.Role,
@@ -337,11 +346,14 @@ func currentTurnID(identity AgentIdentity) string {
 	return strings.TrimSpace(state.ID)
 }
 
-func renderMail(id AgentIdentity, taskID, kind, title, body, outRel, promise, deps, claimedBy, claimedAt string) string {
+func renderMail(id AgentIdentity, taskID, kind, title, body, outRel, promise, deps, claimedBy, claimedAt, thread string) string {
 	b := strings.TrimSpace(body)
 	if b == "" {
 		b = "_(no additional body provided)_"
 	}
+	if strings.TrimSpace(thread) != "" {
+		b += "\n\n# Discussion\n" + strings.TrimSpace(thread)
+	}
 	scope := id.Scope
 	return fmt.Sprintf(`---
 task_id: %s
@@ -374,32 +386,366 @@ depends_on: %s
 `, taskID, kind, id.Role, scope, outRel, promise, claimedBy, claimedAt, deps, title, b, scope, outRel, promise)
 }
 
-// GuardrailsHook validates tool usage against agent role and scope restrictions.
-// Read-only roles (reviewer, monitor, architect) are denied Write/Edit/Bash.
-// All roles are denied writes outside their configured scope prefix.
+// threadFor collects the comments on an assignment and on the beads it is
+// related to (usually its task), oldest first, so the agent picking it up
+// sees earlier review feedback and blockers. Backends without comments yield
+// an empty thread.
+func threadFor(ctx context.Context, client beads.Client, issue beads.Issue) string {
+	ids := append([]string{issue.ID}, beads.DepTargets(issue.Deps, beads.EdgeRelated)...)
+	var all []beads.Comment
+	for _, id := range ids {
+		comments, err := client.Comments(ctx, id)
+		if err != nil {
+			continue
+		}
+		all = append(all, comments...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt < all[j].CreatedAt })
+	return beads.FormatThread(all)
+}
+
+// GuardrailsHook validates tool usage against protected paths, the
+// guardrail policy, the role registry, and scope restrictions. Writes to
+// microforge's control files and the cell's protected paths, and reads of
+// its read_deny paths, are denied before any policy rule is consulted. A
+// matching policy deny rule blocks the call and is named in the reason; a
+// matching allow rule lifts the role's read-only and Bash limits. Without a
+// matching rule, read-only roles are denied writes and Bash follows the
+// role's bash policy. All roles are denied writes outside their cell's
+// include/exclude scope. Bash commands are parsed and every command,
+// redirect target, and known file operand in them is checked; a command
+// that cannot be parsed is denied.
 func GuardrailsHook(in ClaudeHookInput, identity AgentIdentity) (DecisionResponse, error) {
 	tool := strings.TrimSpace(in.ToolName)
-	if tool == "Write" || tool == "Edit" {
-		if identity.Role == "reviewer" || identity.Role == "monitor" || identity.Role == "architect" {
-			return DecisionResponse{Decision: "deny", Reason: "Role is read-only: " + identity.Role}, nil
+	policy, err := LoadPolicy(identity.RigHome, identity.RigName, identity.CellName)
+	if err != nil {
+		return DecisionResponse{Decision: "deny", Reason: "Guardrail policy is invalid: " + err.Error(), Rule: "invalid-policy"}, nil
+	}
+	req := PolicyRequest{Role: identity.Role, Tool: tool, Path: strings.TrimSpace(extractToolPath(in)), Root: identity.Worktree}
+	if tool == "Bash" || tool == "PermissionRequest" {
+		req.Command = strings.TrimSpace(extractToolCommand(in))
+		req.Path = ""
+		return bashDecision(policy, req, identity), nil
+	}
+	writeTool := isWriteTool(tool)
+	if writeTool && req.Path != "" && writeProtected(identity, req.Path) {
+		return protectedDeny(req.Path), nil
+	}
+	if isReadTool(tool) {
+		if fp, denied := readToolDenied(identity, tool, req.Path, in); denied {
+			return readDeny(fp), nil
 		}
-		fp := strings.TrimSpace(extractToolPath(in))
-		if fp != "" && !pathWithinScope(identity, fp) {
-			return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Write/Edit outside scope %q is blocked (saw: %s)", identity.Scope, fp)}, nil
+	}
+	allowed := false
+	allowRule := ""
+	if rule, ok := policy.Match(req); ok {
+		if rule.Effect == PolicyDeny {
+			return policyDeny(rule, req), nil
 		}
+		allowed = true
+		allowRule = rule.ID()
 	}
-	if tool == "Bash" || tool == "PermissionRequest" {
-		if identity.Role == "reviewer" || identity.Role == "monitor" || identity.Role == "architect" {
-			return DecisionResponse{Decision: "deny", Reason: "Role is read-only: " + identity.Role}, nil
+	if !writeTool {
+		return DecisionResponse{Decision: "allow", Rule: allowRule}, nil
+	}
+	spec := identityRole(identityConfig(identity), identity.Role)
+	if spec.ReadOnly() && !allowed {
+		return DecisionResponse{Decision: "deny", Reason: "Role is read-only: " + identity.Role, Rule: "read-only"}, nil
+	}
+	if req.Path != "" && !isOwnOutbox(identity, req.Path) && !pathWithinScope(identity, req.Path) {
+		return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Write/Edit outside scope %q is blocked (saw: %s)", identityScope(identity).String(), req.Path), Rule: "scope"}, nil
+	}
+	return DecisionResponse{Decision: "allow", Rule: allowRule}, nil
+}
+
+func isWriteTool(tool string) bool {
+	switch tool {
+	case "Write", "Edit", "MultiEdit", "NotebookEdit":
+		return true
+	}
+	return false
+}
+
+func isReadTool(tool string) bool {
+	switch tool {
+	case "Read", "Grep", "Glob":
+		return true
+	}
+	return false
+}
+
+// readToolDenied reports whether a Read, Grep, or Glob call could read a
+// path on the cell's read_deny list. Grep and Glob search a tree: an empty
+// path is the worktree, and the search is denied when any file it would
+// read, narrowed by its glob or type, is denied.
+func readToolDenied(identity AgentIdentity, tool, fp string, in ClaudeHookInput) (string, bool) {
+	deny := identityCell(identity).ReadDeny
+	if len(deny) == 0 {
+		return "", false
+	}
+	if tool == "Read" {
+		return fp, fp != "" && rig.MatchGuardPath(deny, identity.Worktree, fp)
+	}
+	input, _ := in.ToolInput.(map[string]any)
+	var globs []string
+	switch tool {
+	case "Grep":
+		if g, ok := input["glob"].(string); ok && strings.TrimSpace(g) != "" {
+			globs = append(globs, g)
+		}
+		if t, ok := input["type"].(string); ok && strings.TrimSpace(t) != "" {
+			typeGlobs, known := grepTypeGlobs[strings.TrimSpace(t)]
+			if !known {
+				// An unknown type cannot narrow the check.
+				return rig.GuardTreeMatch(deny, identity.Worktree, fp)
+			}
+			if len(globs) == 0 {
+				globs = typeGlobs
+			}
+		}
+	case "Glob":
+		if g, ok := input["pattern"].(string); ok {
+			globs = append(globs, g)
+		}
+	}
+	return rig.GuardSearchMatch(deny, identity.Worktree, fp, globs)
+}
+
+// grepTypeGlobs are the file globs of the common ripgrep types Grep's type
+// parameter names.
+var grepTypeGlobs = map[string][]string{
+	"c":    {"*.c", "*.h"},
+	"cpp":  {"*.cpp", "*.cc", "*.cxx", "*.hpp", "*.hh", "*.h"},
+	"css":  {"*.css", "*.scss"},
+	"go":   {"*.go"},
+	"html": {"*.html", "*.htm"},
+	"java": {"*.java"},
+	"js":   {"*.js", "*.jsx", "*.mjs", "*.cjs"},
+	"json": {"*.json"},
+	"md":   {"*.md", "*.markdown"},
+	"py":   {"*.py", "*.pyi"},
+	"rust": {"*.rs"},
+	"sh":   {"*.sh", "*.bash", "*.zsh"},
+	"sql":  {"*.sql"},
+	"toml": {"*.toml"},
+	"ts":   {"*.ts", "*.tsx", "*.mts", "*.cts"},
+	"yaml": {"*.yaml", "*.yml"},
+}
+
+func readDeny(fp string) DecisionResponse {
+	return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Reading %s is denied by the cell's read_deny list", fp), Rule: "read-deny"}
+}
+
+// writeProtected reports whether fp is a control path or on the cell's
+// protected list. The outbox file of the agent's current assignment is the
+// one control path it may write.
+func writeProtected(identity AgentIdentity, fp string) bool {
+	if isOwnOutbox(identity, fp) {
+		return false
+	}
+	return rig.MatchGuardPath(identityCell(identity).WritePatterns(identity.RigHome), identity.Worktree, fp)
+}
+
+func protectedDeny(fp string) DecisionResponse {
+	return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Path is protected from agent writes: %s", fp), Rule: "protected"}
+}
+
+// isOwnOutbox reports whether fp is the outbox file of the assignment the
+// agent's heartbeat says it is working on.
+func isOwnOutbox(identity AgentIdentity, fp string) bool {
+	hb, ok := readHeartbeat(identity)
+	if !ok || strings.TrimSpace(hb.AssignmentID) == "" || identity.Worktree == "" {
+		return false
+	}
+	outbox := strings.TrimSpace(identity.Outbox)
+	if outbox == "" {
+		outbox = filepath.Join("mail", "outbox")
+	}
+	if !filepath.IsAbs(fp) {
+		fp = filepath.Join(identity.Worktree, fp)
+	}
+	return filepath.Clean(fp) == filepath.Join(identity.Worktree, outbox, hb.AssignmentID+".md")
+}
+
+// bashDecision checks a Bash command. Policy rules are matched against the
+// whole command line and then against each command the shell would run;
+// commands without a matching allow rule follow the role's bash policy.
+// Redirect targets are checked as writes. The file operands of the
+// commands ShellCommand.Files knows, and input redirects, are checked
+// against the protected and read_deny lists.
+func bashDecision(policy Policy, req PolicyRequest, identity AgentIdentity) DecisionResponse {
+	if rule, ok := policy.Match(req); ok && rule.Effect == PolicyDeny {
+		return policyDeny(rule, req)
+	}
+	analysis, err := AnalyzeShell(req.Command)
+	if err != nil {
+		return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Bash command could not be analyzed, so it is blocked: %v", err), Rule: "shell-parse"}
+	}
+	cfg := identityConfig(identity)
+	spec := identityRole(cfg, identity.Role)
+	if len(analysis.Commands) == 0 && spec.Bash == rig.BashNone {
+		if _, ok := policy.Match(req); !ok {
+			return bashDisabled(spec, identity.Role)
+		}
+	}
+	for _, c := range analysis.Commands {
+		if c.ProgramDynamic {
+			return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Command name is only known at run time, so it is blocked: %s", c.String()), Rule: "shell-dynamic"}
+		}
+		sub := req
+		sub.Command = c.String()
+		rule, ok := policy.Match(sub)
+		if ok && rule.Effect == PolicyDeny {
+			return policyDeny(rule, sub)
+		}
+		if ok {
+			continue
 		}
-		if identity.Role == "builder" {
-			cmd := strings.TrimSpace(extractToolCommand(in))
-			if cmd != "" && !isAllowedBuilderCommand(cmd) {
-				return DecisionResponse{Decision: "deny", Reason: "Command not allowed for builder policy: " + cmd}, nil
+		if c.ArgsDynamic {
+			if rule, ok := policy.matchIgnoringArgs(sub, PolicyDeny); ok {
+				return DecisionResponse{Decision: "deny", Reason: policyDenyReason(rule, sub) + "; its arguments are only known at run time", Rule: rule.ID()}
 			}
 		}
+		switch spec.Bash {
+		case rig.BashNone:
+			return bashDisabled(spec, identity.Role)
+		case rig.BashAllowlist:
+			if !shellBuiltins[c.Program()] && !cfg.AllowsCommand(c.Program()) {
+				return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Command not allowed for %s policy: %s (add an allow rule to policy.json)", identity.Role, commandContext(c.String(), req.Command)), Rule: "allowed-commands"}
+			}
+		}
+	}
+	for _, w := range analysis.Writes {
+		if w.Dynamic {
+			return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Redirect target is only known at run time, so it is blocked: %s", w.Target), Rule: "shell-dynamic"}
+		}
+		if writeProtected(identity, w.Target) {
+			return protectedDeny(w.Target)
+		}
+		wreq := PolicyRequest{Role: req.Role, Tool: "Write", Path: w.Target, Root: req.Root}
+		rule, ok := policy.Match(wreq)
+		if ok && rule.Effect == PolicyDeny {
+			return policyDeny(rule, wreq)
+		}
+		if spec.ReadOnly() && !ok {
+			return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Role is read-only: %s (redirect to %s)", identity.Role, w.Target), Rule: "read-only"}
+		}
+		if !isOwnOutbox(identity, w.Target) && !pathWithinScope(identity, w.Target) {
+			return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Redirect outside scope %q is blocked (saw: %s)", identityScope(identity).String(), w.Target), Rule: "scope"}
+		}
+	}
+	for _, r := range analysis.Reads {
+		if dec, denied := shellPathDenied(identity, r, false, req.Command); denied {
+			return dec
+		}
+	}
+	for _, c := range analysis.Commands {
+		reads, writes := c.Files()
+		for _, w := range writes {
+			if dec, denied := shellPathDenied(identity, w, true, c.String()); denied {
+				return dec
+			}
+		}
+		for _, r := range reads {
+			if dec, denied := shellPathDenied(identity, r, false, c.String()); denied {
+				return dec
+			}
+		}
+	}
+	return DecisionResponse{Decision: "allow"}
+}
+
+// shellPathDenied checks a path a Bash command reads or writes against the
+// read_deny or write-protected lists. Wildcards are expanded against the
+// worktree as the shell would; a path only known at run time is denied.
+func shellPathDenied(identity AgentIdentity, p ShellPath, write bool, cmd string) (DecisionResponse, bool) {
+	if p.Dynamic {
+		return DecisionResponse{Decision: "deny", Reason: fmt.Sprintf("Path is only known at run time, so it is blocked: %s (in: %s)", p.Target, cmd), Rule: "shell-dynamic"}, true
+	}
+	patterns := identityCell(identity).ReadDeny
+	if write {
+		patterns = identityCell(identity).WritePatterns(identity.RigHome)
+	}
+	for _, fp := range expandShellGlob(identity.Worktree, p.Target) {
+		if write && isOwnOutbox(identity, fp) {
+			continue
+		}
+		match, denied := fp, rig.MatchGuardPath(patterns, identity.Worktree, fp)
+		if !denied && p.Tree {
+			match, denied = rig.GuardTreeMatch(patterns, identity.Worktree, fp)
+		}
+		if !denied {
+			continue
+		}
+		if write {
+			return protectedDeny(match), true
+		}
+		return readDeny(match), true
+	}
+	return DecisionResponse{}, false
+}
+
+// expandShellGlob returns the paths a glob operand names, in the form it
+// was written (relative to the worktree or absolute). An operand without
+// wildcards, or whose glob matches nothing, is returned as is, as the shell
+// would pass it.
+func expandShellGlob(root, fp string) []string {
+	if !strings.ContainsAny(fp, "*?[") {
+		return []string{fp}
+	}
+	pattern := fp
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(root, pattern)
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) == 0 {
+		return []string{fp}
+	}
+	return matches
+}
+
+func bashDisabled(spec rig.RoleSpec, role string) DecisionResponse {
+	if spec.ReadOnly() {
+		return DecisionResponse{Decision: "deny", Reason: "Role is read-only: " + role, Rule: "read-only"}
+	}
+	return DecisionResponse{Decision: "deny", Reason: "Bash is disabled for role: " + role, Rule: "bash-disabled"}
+}
+
+// commandContext names the offending command and, when it is only part of
+// the command line, the line it came from.
+func commandContext(sub, line string) string {
+	if sub == strings.TrimSpace(line) {
+		return sub
+	}
+	return fmt.Sprintf("%s (in: %s)", sub, line)
+}
+
+func policyDeny(rule PolicyRule, req PolicyRequest) DecisionResponse {
+	return DecisionResponse{Decision: "deny", Reason: policyDenyReason(rule, req), Rule: rule.ID()}
+}
+
+func policyDenyReason(rule PolicyRule, req PolicyRequest) string {
+	subject := req.Command
+	if subject == "" {
+		subject = req.Path
+	}
+	if subject == "" {
+		subject = req.Tool
 	}
-	return DecisionResponse{Decision: "allow"}, nil
+	reason := fmt.Sprintf("Blocked by %s policy rule %q: %s", rule.Source, rule.Name, subject)
+	if rule.Reason != "" {
+		reason += " (" + rule.Reason + ")"
+	}
+	return reason
+}
+
+// identityRole looks the agent's role up in the registry. A role missing
+// from the registry is treated as a write role limited to the allowlist.
+func identityRole(cfg rig.Resolved, role string) rig.RoleSpec {
+	if spec, ok := cfg.Rig.Role(role); ok {
+		return spec
+	}
+	return rig.RoleSpec{Name: role, Access: rig.RoleWrite, Bash: rig.BashAllowlist}
 }
 
 func extractToolPath(in ClaudeHookInput) string {
@@ -414,6 +760,9 @@ func extractToolPath(in ClaudeHookInput) string {
 		if p, ok := v["file_path"].(string); ok {
 			return p
 		}
+		if p, ok := v["notebook_path"].(string); ok {
+			return p
+		}
 	case string:
 		return v
 	}
@@ -438,66 +787,47 @@ func extractToolCommand(in ClaudeHookInput) string {
 	return ""
 }
 
-func isAllowedBuilderCommand(cmd string) bool {
-	cmd = strings.TrimSpace(cmd)
-	if cmd == "" {
-		return true
-	}
-	parts := strings.Fields(cmd)
-	if len(parts) == 0 {
-		return true
-	}
-	allowed := map[string]bool{
-		"go":   true,
-		"make": true,
-		"git":  true,
-		"rg":   true,
-		"sed":  true,
-		"cat":  true,
-		"ls":   true,
-		"pwd":  true,
-		"env":  true,
-		"grep": true,
-	}
-	return allowed[parts[0]]
+func pathWithinScope(identity AgentIdentity, fp string) bool {
+	return identityScope(identity).MatchPath(identity.Worktree, fp)
 }
 
-func pathWithinScope(identity AgentIdentity, fp string) bool {
-	scope := strings.TrimSpace(identity.Scope)
-	if scope == "" || scope == "." {
-		return true
-	}
-	base := scope
-	if !filepath.IsAbs(base) {
-		base = filepath.Join(identity.Worktree, base)
-	}
-	baseAbs, err := filepath.Abs(base)
-	if err != nil {
-		return false // fail-closed: deny on path resolution error
-	}
+// identityScope returns the cell's include/exclude scope from cell.json,
+// falling back to the identity's scope prefix when the cell cannot be loaded.
+func identityScope(identity AgentIdentity) rig.Scope {
+	return identityCell(identity).Scope()
+}
 
-	target := fp
-	if !filepath.IsAbs(target) {
-		target = filepath.Join(identity.Worktree, target)
-	}
-	targetAbs, err := filepath.Abs(target)
-	if err != nil {
-		return false // fail-closed: deny on path resolution error
+// identityCell loads the agent's cell.json. When it cannot be loaded the
+// cell has only the identity's scope prefix and no protected or read_deny
+// lists; control paths stay protected regardless.
+func identityCell(identity AgentIdentity) rig.CellConfig {
+	if identity.RigHome != "" && identity.RigName != "" && identity.CellName != "" {
+		if cfg, err := rig.LoadCellConfig(rig.CellConfigPath(identity.RigHome, identity.RigName, identity.CellName)); err == nil {
+			return cfg
+		}
 	}
+	return rig.CellConfig{Name: identity.CellName, ScopePrefix: identity.Scope}
+}
 
-	rel, err := filepath.Rel(baseAbs, targetAbs)
-	if err != nil {
-		return false // fail-closed: deny on path resolution error
-	}
-	if rel == "." {
-		return true
-	}
-	if rel == ".." {
-		return false
+// identityConfig resolves the agent's rig and cell configuration for its
+// role. Built-in
+// defaults apply when either file cannot be loaded.
+func identityConfig(identity AgentIdentity) rig.Resolved {
+	rc := rig.RigConfig{Name: identity.RigName}
+	cc := rig.CellConfig{Name: identity.CellName, ScopePrefix: identity.Scope}
+	if identity.RigHome != "" && identity.RigName != "" {
+		if cfg, err := rig.LoadRigConfig(rig.RigConfigPath(identity.RigHome, identity.RigName)); err == nil {
+			rc = cfg
+		}
+		if identity.CellName != "" {
+			if cfg, err := rig.LoadCellConfig(rig.CellConfigPath(identity.RigHome, identity.RigName, identity.CellName)); err == nil {
+				cc = cfg
+			}
+		}
 	}
-	prefix := ".." + string(os.PathSeparator)
-	if strings.HasPrefix(rel, prefix) {
-		return false
+	res, err := rig.ResolveRole(rc, cc, identity.Role)
+	if err != nil {
+		res, _ = rig.ResolveRole(rig.RigConfig{Name: identity.RigName}, rig.CellConfig{Name: identity.CellName}, identity.Role)
 	}
-	return true
+	return res
 }
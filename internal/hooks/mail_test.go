@@ -0,0 +1,32 @@
+package hooks
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/beads"
+)
+
+func TestRenderMailIncludesThread(t *testing.T) {
+	client, err := beads.Open(beads.BackendJSONL, t.TempDir(), filepath.Join(t.TempDir(), "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	task, _ := client.Create(nil, beads.CreateRequest{Title: "Add healthz", Type: "task"})
+	assn, _ := client.Create(nil, beads.CreateRequest{Title: "Assignment " + task.ID, Type: "assignment", Deps: []string{"related:" + task.ID}})
+	if _, err := client.AddComment(nil, task.ID, "api/reviewer", "Rejected: missing tests"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	if _, err := client.AddComment(nil, assn.ID, "human:ops", "Use port 8081"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+	thread := threadFor(nil, client, assn)
+	mail := renderMail(AgentIdentity{Role: "builder", Scope: "apps/api"}, assn.ID, "assignment", assn.Title, "body", "mail/outbox/x.md", "DONE", "", "", "", thread)
+	if !strings.Contains(mail, "# Discussion") || !strings.Contains(mail, "**api/reviewer** on "+task.ID) || !strings.Contains(mail, "Use port 8081") {
+		t.Fatalf("thread missing from mail:\n%s", mail)
+	}
+	if strings.Index(mail, "Rejected") > strings.Index(mail, "# Deliverables") {
+		t.Fatalf("discussion should precede deliverables:\n%s", mail)
+	}
+}
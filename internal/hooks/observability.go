@@ -1,37 +1,145 @@
 package hooks
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/util"
 )
 
 type AgentHeartbeat struct {
-	Timestamp    string `json:"timestamp"`
-	Status       string `json:"status"`
-	AssignmentID string `json:"assignment_id,omitempty"`
-	TurnID       string `json:"turn_id,omitempty"`
-	Message      string `json:"message,omitempty"`
+	Timestamp      string `json:"timestamp"`
+	Status         string `json:"status"`
+	AssignmentID   string `json:"assignment_id,omitempty"`
+	TurnID         string `json:"turn_id,omitempty"`
+	Message        string `json:"message,omitempty"`
+	LeaseExpiresAt string `json:"lease_expires_at,omitempty"`
 }
 
 func UpdateHeartbeat(identity AgentIdentity, status, assignmentID, turnID, message string) {
-	if identity.RigHome == "" || identity.RigName == "" || identity.CellName == "" || identity.Role == "" {
-		return
-	}
-	base := filepath.Join(identity.RigHome, "rigs", identity.RigName, "agents", identity.CellName, identity.Role)
-	_ = util.EnsureDir(base)
-	hb := AgentHeartbeat{
-		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+	writeHeartbeat(identity, AgentHeartbeat{
 		Status:       status,
 		AssignmentID: assignmentID,
 		TurnID:       turnID,
 		Message:      message,
+	})
+}
+
+// Heartbeat refreshes the agent heartbeat and renews the lease on the
+// assignment it is working on. The backend is only touched once the lease is
+// past half its lifetime, so calling this on every tool use stays cheap.
+func Heartbeat(ctx context.Context, client beads.Client, identity AgentIdentity) error {
+	hb, ok := readHeartbeat(identity)
+	if !ok || strings.TrimSpace(hb.AssignmentID) == "" {
+		return nil
+	}
+	if !strings.EqualFold(hb.Status, "claimed") && !strings.EqualFold(hb.Status, "working") {
+		return nil
+	}
+	hb.Status = "working"
+	hb.Message = ""
+	exp, err := time.Parse(time.RFC3339, hb.LeaseExpiresAt)
+	if err != nil || time.Until(exp) < LeaseTTL()/2 {
+		renewed, err := RenewLease(ctx, client, identity, hb.AssignmentID)
+		if err != nil {
+			if errors.Is(err, ErrClaimLost) {
+				hb.Status = "lease_lost"
+				hb.Message = err.Error()
+				hb.LeaseExpiresAt = ""
+				writeHeartbeat(identity, hb)
+			}
+			return err
+		}
+		hb.LeaseExpiresAt = renewed.Format(time.RFC3339)
+	}
+	writeHeartbeat(identity, hb)
+	return nil
+}
+
+func heartbeatDir(identity AgentIdentity) string {
+	if identity.RigHome == "" || identity.RigName == "" || identity.CellName == "" || identity.Role == "" {
+		return ""
+	}
+	return filepath.Join(identity.RigHome, "rigs", identity.RigName, "agents", identity.CellName, identity.Role)
+}
+
+func readHeartbeat(identity AgentIdentity) (AgentHeartbeat, bool) {
+	base := heartbeatDir(identity)
+	if base == "" {
+		return AgentHeartbeat{}, false
+	}
+	b, err := os.ReadFile(filepath.Join(base, "heartbeat.json"))
+	if err != nil {
+		return AgentHeartbeat{}, false
+	}
+	var hb AgentHeartbeat
+	if err := json.Unmarshal(b, &hb); err != nil {
+		return AgentHeartbeat{}, false
 	}
+	return hb, true
+}
+
+func writeHeartbeat(identity AgentIdentity, hb AgentHeartbeat) {
+	base := heartbeatDir(identity)
+	if base == "" {
+		return
+	}
+	_ = util.EnsureDir(base)
+	hb.Timestamp = time.Now().UTC().Format(time.RFC3339)
 	b, err := json.MarshalIndent(hb, "", "  ")
 	if err != nil {
 		return
 	}
 	_ = util.AtomicWriteFile(filepath.Join(base, "heartbeat.json"), b, 0o644)
 }
+
+// journalEvent appends a telemetry event to the rig's telemetry.jsonl when
+// rig.json routes kind there instead of to beads, and reports whether it did.
+func journalEvent(identity AgentIdentity, kind, turnID string, fields map[string]string) bool {
+	if identity.RigHome == "" || identity.RigName == "" {
+		return false
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(identity.RigHome, identity.RigName))
+	if err != nil || !cfg.Retention.JournalsKind(kind) {
+		return false
+	}
+	rec := map[string]string{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"kind":      kind,
+		"cell":      identity.CellName,
+		"role":      identity.Role,
+		"agent_id":  identity.AgentID,
+		"turn_id":   turnID,
+	}
+	for k, v := range fields {
+		rec[k] = v
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return false
+	}
+	path := rig.TelemetryJournalPath(identity.RigHome, identity.RigName)
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return false
+	}
+	// bead gc rewrites the journal under the same lock.
+	unlock, err := util.LockFile(nil, path+".lock")
+	if err != nil {
+		return false
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err == nil
+}
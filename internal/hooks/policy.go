@@ -0,0 +1,299 @@
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+// Policy rule effects.
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+)
+
+// Policy is a guardrail policy file: ordered allow/deny rules matched
+// against the agent's role, the tool, the Bash command and its arguments,
+// and the tool's path. The first matching rule wins.
+type Policy struct {
+	// Inherit, when false, drops the layers below this file (the rig policy
+	// for a cell file, the built-in rules for the rig file).
+	Inherit *bool        `json:"inherit,omitempty"`
+	Rules   []PolicyRule `json:"rules"`
+}
+
+// PolicyRule is one allow or deny rule. Empty fields match anything.
+// Command matches the program name and Args the rest of the command line;
+// both are globs (* matches any text) or regular expressions prefixed with
+// "re:". Paths are scope-style globs relative to the worktree.
+type PolicyRule struct {
+	Name    string   `json:"name"`
+	Effect  string   `json:"effect"`
+	Reason  string   `json:"reason,omitempty"`
+	Roles   []string `json:"roles,omitempty"`
+	Tools   []string `json:"tools,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Args    string   `json:"args,omitempty"`
+	Paths   []string `json:"paths,omitempty"`
+
+	// Source is the layer the rule came from: "cell", "rig", or "default".
+	Source string `json:"source,omitempty"`
+}
+
+// PolicyRequest describes one tool call for policy evaluation.
+type PolicyRequest struct {
+	Role    string
+	Tool    string
+	Command string
+	Path    string
+	Root    string
+}
+
+// DefaultPolicyRules apply beneath the rig and cell policy files.
+var DefaultPolicyRules = []PolicyRule{
+	{
+		Name: "no-force-push", Effect: PolicyDeny, Tools: []string{"Bash"},
+		Command: "git", Args: `re:(^|\s)push\s(.*\s)?(--force|--force-with-lease|-f)(\s|=|$)`,
+		Reason: "force pushes rewrite shared history",
+	},
+	{
+		Name: "no-sed-in-place", Effect: PolicyDeny, Tools: []string{"Bash"},
+		Command: "sed", Args: `re:(^|\s)(-[a-zA-Z]*i|--in-place)`,
+		Reason: "edit files with Edit so scope checks apply",
+	},
+}
+
+// LoadPolicy merges the cell policy, the rig policy, and the built-in
+// rules, in that order. Missing files are skipped.
+func LoadPolicy(home, rigName, cellName string) (Policy, error) {
+	var merged Policy
+	inherit := true
+	if home != "" && rigName != "" && cellName != "" {
+		p, ok, err := readPolicyFile(rig.CellPolicyPath(home, rigName, cellName), "cell")
+		if err != nil {
+			return Policy{}, err
+		}
+		if ok {
+			merged.Rules = append(merged.Rules, p.Rules...)
+			inherit = p.Inherit == nil || *p.Inherit
+		}
+	}
+	if inherit && home != "" && rigName != "" {
+		p, ok, err := readPolicyFile(rig.RigPolicyPath(home, rigName), "rig")
+		if err != nil {
+			return Policy{}, err
+		}
+		if ok {
+			merged.Rules = append(merged.Rules, p.Rules...)
+			inherit = p.Inherit == nil || *p.Inherit
+		}
+	}
+	if inherit {
+		for _, r := range DefaultPolicyRules {
+			r.Source = "default"
+			merged.Rules = append(merged.Rules, r)
+		}
+	}
+	return merged, nil
+}
+
+func readPolicyFile(path, source string) (Policy, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Policy{}, false, nil
+		}
+		return Policy{}, false, err
+	}
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return Policy{}, false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i := range p.Rules {
+		p.Rules[i].Source = source
+	}
+	if err := p.Validate(); err != nil {
+		return Policy{}, false, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, true, nil
+}
+
+// Validate checks effects and compiles every pattern.
+func (p Policy) Validate() error {
+	for i, r := range p.Rules {
+		label := r.Name
+		if label == "" {
+			label = fmt.Sprintf("rules[%d]", i)
+		}
+		if r.Effect != PolicyAllow && r.Effect != PolicyDeny {
+			return fmt.Errorf("rule %s: effect must be %s or %s", label, PolicyAllow, PolicyDeny)
+		}
+		for _, pat := range append([]string{r.Command, r.Args}, r.Tools...) {
+			if _, err := compilePolicyPattern(pat); err != nil {
+				return fmt.Errorf("rule %s: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule that applies to req.
+func (p Policy) Match(req PolicyRequest) (PolicyRule, bool) {
+	for _, r := range p.Rules {
+		if r.matches(req) {
+			return r, true
+		}
+	}
+	return PolicyRule{}, false
+}
+
+// matchIgnoringArgs returns the first rule with the given effect and an
+// Args pattern that applies to req when its arguments are disregarded. It
+// is used for commands whose arguments are only known at run time.
+func (p Policy) matchIgnoringArgs(req PolicyRequest, effect string) (PolicyRule, bool) {
+	for _, r := range p.Rules {
+		if r.Effect != effect || r.Args == "" {
+			continue
+		}
+		loose := r
+		loose.Args = ""
+		if loose.matches(req) {
+			return r, true
+		}
+	}
+	return PolicyRule{}, false
+}
+
+func (r PolicyRule) matches(req PolicyRequest) bool {
+	if len(r.Roles) > 0 && !hasRole(r.Roles, req.Role) {
+		return false
+	}
+	if len(r.Tools) > 0 {
+		ok := false
+		for _, t := range r.Tools {
+			if policyPatternMatch(t, req.Tool) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if r.Command != "" || r.Args != "" {
+		program, args := splitCommand(req.Command)
+		if program == "" {
+			return false
+		}
+		if r.Command != "" && !policyPatternMatch(r.Command, program) {
+			return false
+		}
+		if r.Args != "" && !policyPatternMatch(r.Args, args) {
+			return false
+		}
+	}
+	if len(r.Paths) > 0 {
+		if strings.TrimSpace(req.Path) == "" {
+			return false
+		}
+		if !(rig.Scope{Include: r.Paths}).MatchPath(req.Root, req.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// ID identifies the rule in the guardrail audit log as "<source>:<name>".
+func (r PolicyRule) ID() string { return r.Source + ":" + r.Name }
+
+// Describe renders the rule for deny reasons and `mforge policy show`.
+func (r PolicyRule) Describe() string {
+	var parts []string
+	if len(r.Roles) > 0 {
+		parts = append(parts, "roles="+strings.Join(r.Roles, ","))
+	}
+	if len(r.Tools) > 0 {
+		parts = append(parts, "tools="+strings.Join(r.Tools, ","))
+	}
+	if r.Command != "" {
+		parts = append(parts, "command="+r.Command)
+	}
+	if r.Args != "" {
+		parts = append(parts, "args="+r.Args)
+	}
+	if len(r.Paths) > 0 {
+		parts = append(parts, "paths="+strings.Join(r.Paths, ","))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "any")
+	}
+	return fmt.Sprintf("%s %s [%s] %s", r.Effect, r.Name, r.Source, strings.Join(parts, " "))
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == "*" || r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommand returns the program's base name and the remaining
+// arguments of a command line.
+func splitCommand(cmd string) (string, string) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return filepath.Base(fields[0]), strings.Join(fields[1:], " ")
+}
+
+var policyPatterns sync.Map
+
+func policyPatternMatch(pattern, s string) bool {
+	re, err := compilePolicyPattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// compilePolicyPattern turns a glob into an anchored regexp; "re:" patterns
+// are used as written and match anywhere unless anchored.
+func compilePolicyPattern(pattern string) (*regexp.Regexp, error) {
+	if v, ok := policyPatterns.Load(pattern); ok {
+		return v.(*regexp.Regexp), nil
+	}
+	expr := ""
+	if strings.HasPrefix(pattern, "re:") {
+		expr = strings.TrimPrefix(pattern, "re:")
+	} else {
+		var b strings.Builder
+		b.WriteString("^")
+		for _, ch := range pattern {
+			switch ch {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(ch)))
+			}
+		}
+		b.WriteString("$")
+		expr = b.String()
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+	}
+	policyPatterns.Store(pattern, re)
+	return re, nil
+}
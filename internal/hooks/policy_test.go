@@ -0,0 +1,77 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func writePolicy(t *testing.T, path, body string) {
+	t.Helper()
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGuardrailsPolicyRules(t *testing.T) {
+	t.Setenv("CLAUDE_TOOL_INPUT_FILE_PATH", "")
+	t.Setenv("CLAUDE_TOOL_INPUT_COMMAND", "")
+	home := t.TempDir()
+	wt := t.TempDir()
+	writePolicy(t, rig.RigPolicyPath(home, "r"), `{"rules": [
+		{"name": "no-migrations", "effect": "deny", "tools": ["Write", "Edit"], "paths": ["svc/**/migrations/**"], "reason": "migrations are reviewed by hand"},
+		{"name": "no-web", "effect": "deny", "tools": ["Web*"]}
+	]}`)
+	writePolicy(t, rig.CellPolicyPath(home, "r", "web"), `{"rules": [
+		{"name": "node-tests", "effect": "allow", "roles": ["builder"], "tools": ["Bash"], "command": "npm", "args": "re:^(test|run lint)"}
+	]}`)
+	id := AgentIdentity{RigHome: home, RigName: "r", CellName: "web", Role: "builder", Scope: "svc", Worktree: wt}
+	cases := []struct {
+		tool  string
+		input map[string]any
+		allow bool
+		rule  string
+	}{
+		{"Bash", map[string]any{"command": "npm test"}, true, ""},
+		{"Bash", map[string]any{"command": "npm publish"}, false, ""},
+		{"Bash", map[string]any{"command": "git push --force origin main"}, false, "no-force-push"},
+		{"Bash", map[string]any{"command": "git push origin main"}, true, ""},
+		{"Bash", map[string]any{"command": "sed -i s/a/b/ ../x.go"}, false, "no-sed-in-place"},
+		{"Bash", map[string]any{"command": "sed -n 1p x.go"}, true, ""},
+		{"Write", map[string]any{"file_path": wt + "/svc/api/migrations/001.sql"}, false, "no-migrations"},
+		{"Write", map[string]any{"file_path": wt + "/svc/api/main.go"}, true, ""},
+		{"WebFetch", map[string]any{"url": "https://example.com"}, false, "no-web"},
+		{"Read", map[string]any{"file_path": wt + "/svc/a.go"}, true, ""},
+	}
+	for _, c := range cases {
+		resp, err := GuardrailsHook(ClaudeHookInput{ToolName: c.tool, ToolInput: c.input}, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (resp.Decision == "allow") != c.allow {
+			t.Fatalf("%s %v: got %s (%s)", c.tool, c.input, resp.Decision, resp.Reason)
+		}
+		if c.rule != "" && !strings.Contains(resp.Reason, `"`+c.rule+`"`) {
+			t.Fatalf("%s %v: reason should name %s, got %q", c.tool, c.input, c.rule, resp.Reason)
+		}
+	}
+
+	writePolicy(t, rig.CellPolicyPath(home, "r", "web"), `{"inherit": false, "rules": []}`)
+	resp, _ := GuardrailsHook(ClaudeHookInput{ToolName: "WebFetch"}, id)
+	if resp.Decision != "allow" {
+		t.Fatalf("inherit=false should drop rig rules, got %s", resp.Reason)
+	}
+
+	writePolicy(t, rig.CellPolicyPath(home, "r", "web"), `{"rules": [{"name": "bad", "effect": "maybe"}]}`)
+	resp, _ = GuardrailsHook(ClaudeHookInput{ToolName: "Read"}, id)
+	if resp.Decision != "deny" || !strings.Contains(resp.Reason, "invalid") {
+		t.Fatalf("invalid policy should fail closed, got %s (%s)", resp.Decision, resp.Reason)
+	}
+}
@@ -0,0 +1,144 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func TestGuardrailsProtectedAndReadDenyPaths(t *testing.T) {
+	t.Setenv("CLAUDE_TOOL_INPUT_FILE_PATH", "")
+	t.Setenv("CLAUDE_TOOL_INPUT_COMMAND", "")
+	home := t.TempDir()
+	wt := rig.CellWorktreeDir(home, "r", "api")
+	if err := util.EnsureDir(wt); err != nil {
+		t.Fatal(err)
+	}
+	cell := rig.CellConfig{
+		Name:         "api",
+		ScopePrefix:  "svc/api",
+		WorktreePath: wt,
+		Protected:    rig.DefaultProtectedPaths,
+		ReadDeny:     rig.DefaultReadDeny(home, "r"),
+	}
+	if err := rig.SaveCellConfig(rig.CellConfigPath(home, "r", "api"), cell); err != nil {
+		t.Fatal(err)
+	}
+	// A policy allow rule does not lift protected or read_deny paths.
+	writePolicy(t, rig.RigPolicyPath(home, "r"), `{"rules": [{"name": "anything", "effect": "allow", "tools": ["Write", "Read"]}]}`)
+	id := AgentIdentity{RigHome: home, RigName: "r", CellName: "api", Role: "builder", Worktree: wt, Outbox: "mail/outbox"}
+	UpdateHeartbeat(id, "working", "mf-7", "", "")
+	other := rig.CellWorktreeDir(home, "r", "web")
+	cases := []struct {
+		tool  string
+		input map[string]any
+		allow bool
+	}{
+		{"Write", map[string]any{"file_path": filepath.Join(wt, ".mf/active-agent.json")}, false},
+		{"Edit", map[string]any{"file_path": ".claude/settings.json"}, false},
+		{"MultiEdit", map[string]any{"file_path": "kubeconfig.yaml"}, false},
+		{"Write", map[string]any{"file_path": "mail/outbox/mf-3.md"}, false},
+		{"Write", map[string]any{"file_path": "mail/outbox/mf-7.md"}, true},
+		{"Write", map[string]any{"file_path": rig.CellPolicyPath(home, "r", "api")}, false},
+		{"Write", map[string]any{"file_path": "svc/api/main.go"}, true},
+		{"Bash", map[string]any{"command": "echo x > .mf/hooks.json"}, false},
+		{"Bash", map[string]any{"command": "echo done >> mail/outbox/mf-7.md"}, true},
+		{"Read", map[string]any{"file_path": ".env"}, false},
+		{"Read", map[string]any{"file_path": filepath.Join(other, "svc/web/main.go")}, false},
+		{"Grep", map[string]any{"pattern": "TODO", "path": other}, false},
+		{"Glob", map[string]any{"pattern": "*.go", "path": "svc/api"}, true},
+		{"Read", map[string]any{"file_path": "svc/api/main.go"}, true},
+	}
+	for _, c := range cases {
+		resp, err := GuardrailsHook(ClaudeHookInput{ToolName: c.tool, ToolInput: c.input}, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (resp.Decision == "allow") != c.allow {
+			t.Fatalf("%s %v: got %s (%s)", c.tool, c.input, resp.Decision, resp.Reason)
+		}
+	}
+}
+
+func TestGuardrailsReadDenySearchesAndShellArgs(t *testing.T) {
+	t.Setenv("CLAUDE_TOOL_INPUT_FILE_PATH", "")
+	t.Setenv("CLAUDE_TOOL_INPUT_COMMAND", "")
+	home := t.TempDir()
+	wt := rig.CellWorktreeDir(home, "r", "api")
+	other := rig.CellWorktreeDir(home, "r", "web")
+	for _, f := range []string{filepath.Join(wt, ".env"), filepath.Join(wt, ".mf/active-agent.json"), filepath.Join(wt, "svc/api/main.go"), filepath.Join(other, "svc/web/main.go")} {
+		if err := util.EnsureDir(filepath.Dir(f)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte("KEY=1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cell := rig.CellConfig{
+		Name:         "api",
+		ScopePrefix:  "svc/api",
+		WorktreePath: wt,
+		Protected:    rig.DefaultProtectedPaths,
+		ReadDeny:     rig.DefaultReadDeny(home, "r"),
+	}
+	if err := rig.SaveCellConfig(rig.CellConfigPath(home, "r", "api"), cell); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		role  string
+		tool  string
+		input map[string]any
+		rule  string // "" means allowed
+	}{
+		{"builder", "Grep", map[string]any{"pattern": "KEY"}, "read-deny"},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "path": "."}, "read-deny"},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "glob": ".env"}, "read-deny"},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "path": rig.CellsDir(home, "r")}, "read-deny"},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "glob": "*.go"}, ""},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "type": "go"}, ""},
+		{"builder", "Grep", map[string]any{"pattern": "KEY", "path": "svc/api"}, ""},
+		{"builder", "Glob", map[string]any{"pattern": "**/.env"}, "read-deny"},
+		{"builder", "Glob", map[string]any{"pattern": "../../web/worktree/**/*.go"}, "read-deny"},
+		{"builder", "Glob", map[string]any{"pattern": "**/*.go"}, ""},
+		{"builder", "Bash", map[string]any{"command": "cat .env"}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "cat < .env"}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "cat .e*"}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "grep -r KEY " + other}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "grep -rn KEY ."}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "cat \"$F\""}, "shell-dynamic"},
+		{"builder", "Bash", map[string]any{"command": "git checkout HEAD -- .claude/settings.json"}, "protected"},
+		{"builder", "Bash", map[string]any{"command": "cat svc/api/main.go && grep -rn KEY svc/api"}, ""},
+		{"builder", "Bash", map[string]any{"command": "git checkout -- svc/api/main.go"}, ""},
+		{"cell", "Bash", map[string]any{"command": "cp x .claude/settings.json"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "cp x .claude"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "rm -rf .mf"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "rm -rf ."}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "cd svc && rm -rf ../mail"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "echo x | tee -a .git/config"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "cp --target-directory=.mf/x a b"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "cp --target=.mf a b"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "cp -rt.mf a"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "sort --output=.claude/settings.json a"}, "protected"},
+		{"cell", "Bash", map[string]any{"command": "sort -o.claude/settings.json a"}, "protected"},
+		{"builder", "Bash", map[string]any{"command": "rg --file=.env svc/api"}, "read-deny"},
+		{"builder", "Bash", map[string]any{"command": "grep -f.env svc/api/main.go"}, "read-deny"},
+		{"cell", "Bash", map[string]any{"command": "cp --target-directory=svc/api a b"}, ""},
+		{"cell", "Bash", map[string]any{"command": "mv svc/api/main.go svc/api/app.go && rm -f svc/api/old.go"}, ""},
+	}
+	for _, c := range cases {
+		id := AgentIdentity{RigHome: home, RigName: "r", CellName: "api", Role: c.role, Worktree: wt, Outbox: "mail/outbox"}
+		resp, err := GuardrailsHook(ClaudeHookInput{ToolName: c.tool, ToolInput: c.input}, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.rule == "" && resp.Decision != "allow" {
+			t.Fatalf("%s %s %v: denied (%s)", c.role, c.tool, c.input, resp.Reason)
+		}
+		if c.rule != "" && (resp.Decision != "deny" || resp.Rule != c.rule) {
+			t.Fatalf("%s %s %v: got %s %q (%s), want deny %q", c.role, c.tool, c.input, resp.Decision, resp.Rule, resp.Reason, c.rule)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// ShellCommand is one command a Bash tool call would run. Args hold the
+// words with quoting removed; a word only known at run time (a parameter
+// expansion or command substitution) is kept as written.
+type ShellCommand struct {
+	Args []string
+	// ProgramDynamic is set when the program name itself is computed at
+	// run time, so it cannot be checked.
+	ProgramDynamic bool
+	// ArgsDynamic is set when any argument is computed at run time.
+	ArgsDynamic bool
+	// Dir is the directory the script has changed into when the command
+	// runs; "" is the worktree. DirUnknown is set after a `cd` whose target
+	// is only known at run time.
+	Dir        string
+	DirUnknown bool
+	// dynamic marks each argument computed at run time.
+	dynamic []bool
+}
+
+// Program returns the command's program name as written.
+func (c ShellCommand) Program() string {
+	if len(c.Args) == 0 {
+		return ""
+	}
+	return c.Args[0]
+}
+
+func (c ShellCommand) String() string { return strings.Join(c.Args, " ") }
+
+// ShellPath is a file a Bash tool call reads or writes.
+type ShellPath struct {
+	// Target is the path, joined with the directory the script has changed
+	// into when that is known.
+	Target string
+	// Dynamic is set when the target, or the directory it is relative to,
+	// is only known at run time.
+	Dynamic bool
+	// Tree is set when everything beneath Target is read or written too,
+	// as by `grep -r` or `rm -r`.
+	Tree bool
+}
+
+// ShellAnalysis lists every command and redirect target in a Bash command,
+// including those inside pipelines, lists, subshells, command and process
+// substitutions, and `bash -c`/`eval` strings.
+type ShellAnalysis struct {
+	Commands []ShellCommand
+	Writes   []ShellPath
+	Reads    []ShellPath
+}
+
+// maxShellDepth bounds how deeply `bash -c` and `eval` strings are reparsed.
+const maxShellDepth = 8
+
+// shellBuiltins never need to be on a role's allowed_commands; they do not
+// start another program. Policy rules still apply to them.
+var shellBuiltins = map[string]bool{
+	":": true, "[": true, "cd": true, "echo": true, "exit": true, "export": true,
+	"false": true, "popd": true, "printf": true, "pushd": true, "pwd": true,
+	"read": true, "return": true, "set": true, "shift": true, "test": true,
+	"true": true, "unset": true, "wait": true,
+}
+
+// shellWrappers run the command that follows their own options. The value
+// lists the options that take an argument. Transparent wrappers only change
+// how the inner command runs, so only the inner command is checked; the
+// others (sudo) are checked as well as the inner command.
+var shellWrappers = map[string]struct {
+	transparent bool
+	valueFlags  []string
+}{
+	"command": {transparent: true},
+	"env":     {transparent: true, valueFlags: []string{"-u", "--unset", "-C", "--chdir"}},
+	"exec":    {transparent: true, valueFlags: []string{"-a"}},
+	"nice":    {transparent: true, valueFlags: []string{"-n", "--adjustment"}},
+	"nohup":   {transparent: true},
+	"stdbuf":  {transparent: true, valueFlags: []string{"-i", "-o", "-e"}},
+	"time":    {transparent: true, valueFlags: []string{"-f", "--format", "-o", "--output"}},
+	"timeout": {transparent: true, valueFlags: []string{"-s", "--signal", "-k", "--kill-after"}},
+	"xargs":   {transparent: true, valueFlags: []string{"-I", "-L", "-n", "-P", "-s", "-d", "-E", "-a", "--arg-file", "--delimiter", "--max-args", "--max-procs"}},
+	"doas":    {valueFlags: []string{"-u", "-C"}},
+	"sudo":    {valueFlags: []string{"-u", "--user", "-g", "--group", "-C", "-D", "--chdir", "-h", "--host", "-p", "--prompt"}},
+}
+
+// shellInterpreters run their -c argument as a script.
+var shellInterpreters = map[string]bool{"bash": true, "dash": true, "ksh": true, "sh": true, "zsh": true}
+
+// AnalyzeShell parses a Bash command and returns every command it would
+// run and every file it would read or write through a redirection. Callers should
+// treat an error as a reason to deny the command.
+func AnalyzeShell(src string) (ShellAnalysis, error) {
+	a := &shellAnalyzer{}
+	if err := a.parse(src, 0); err != nil {
+		return ShellAnalysis{}, err
+	}
+	return a.out, nil
+}
+
+type shellAnalyzer struct {
+	out ShellAnalysis
+	// dir is the directory the script has changed into; "" is the
+	// worktree. dirUnknown is set after a `cd` whose target is dynamic.
+	dir        string
+	dirUnknown bool
+}
+
+func (a *shellAnalyzer) parse(src string, depth int) error {
+	if depth > maxShellDepth {
+		return fmt.Errorf("nested shell commands exceed depth %d", maxShellDepth)
+	}
+	file, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(src), "")
+	if err != nil {
+		return err
+	}
+	var walkErr error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if walkErr != nil {
+			return false
+		}
+		switch x := node.(type) {
+		case *syntax.CallExpr:
+			if len(x.Args) > 0 {
+				walkErr = a.call(x.Args, depth)
+			}
+		case *syntax.Redirect:
+			a.redirect(x)
+		}
+		return true
+	})
+	return walkErr
+}
+
+func (a *shellAnalyzer) call(words []*syntax.Word, depth int) error {
+	cmd := ShellCommand{}
+	dynamic := make([]bool, len(words))
+	for i, w := range words {
+		lit, ok := shellWordLiteral(w)
+		if !ok {
+			lit = printShellWord(w)
+			dynamic[i] = true
+		}
+		cmd.Args = append(cmd.Args, lit)
+	}
+	for {
+		if len(cmd.Args) == 0 {
+			return nil
+		}
+		cmd.ProgramDynamic = dynamic[0] || strings.ContainsAny(cmd.Args[0], "*?[{")
+		cmd.ArgsDynamic = false
+		for _, d := range dynamic[1:] {
+			cmd.ArgsDynamic = cmd.ArgsDynamic || d
+		}
+		if cmd.ProgramDynamic {
+			a.add(cmd, dynamic)
+			return nil
+		}
+		program := filepath.Base(cmd.Args[0])
+		if shellInterpreters[program] {
+			if script, ok, err := interpreterScript(cmd.Args, dynamic); ok || err != nil {
+				if err != nil {
+					return err
+				}
+				return a.parse(script, depth+1)
+			}
+		}
+		if program == "eval" {
+			if cmd.ArgsDynamic {
+				return fmt.Errorf("eval of a string only known at run time: %s", cmd.String())
+			}
+			return a.parse(strings.Join(cmd.Args[1:], " "), depth+1)
+		}
+		if program == "cd" || program == "pushd" {
+			a.chdir(cmd.Args[1:], dynamic[1:])
+		}
+		wrapper, ok := shellWrappers[program]
+		if !ok {
+			a.add(cmd, dynamic)
+			return nil
+		}
+		start := wrappedCommandStart(program, cmd.Args, wrapper.valueFlags)
+		if start >= len(cmd.Args) {
+			a.add(cmd, dynamic)
+			return nil
+		}
+		if !wrapper.transparent {
+			a.add(cmd, dynamic)
+		}
+		if program == "xargs" {
+			// xargs appends words read from stdin.
+			dynamic = append(dynamic, true)
+			cmd.Args = append(cmd.Args, "<stdin>")
+		}
+		cmd = ShellCommand{Args: cmd.Args[start:]}
+		dynamic = dynamic[start:]
+	}
+}
+
+func (a *shellAnalyzer) add(cmd ShellCommand, dynamic []bool) {
+	cmd.Dir = a.dir
+	cmd.DirUnknown = a.dirUnknown
+	cmd.dynamic = dynamic
+	a.out.Commands = append(a.out.Commands, cmd)
+}
+
+// wrappedCommandStart returns the index of the command a wrapper runs.
+func wrappedCommandStart(program string, args []string, valueFlags []string) int {
+	i := 1
+	for i < len(args) {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return i + 1
+		case isValueFlag(valueFlags, arg):
+			i += 2
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			i++
+		case program == "env" && strings.Contains(arg, "="):
+			i++
+		case program == "timeout":
+			// The first operand is the duration.
+			return i + 1
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+func isValueFlag(flags []string, arg string) bool {
+	for _, f := range flags {
+		if f == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// interpreterScript returns the -c script of a shell interpreter call; ok
+// is false when the interpreter runs a script file or stdin instead.
+func interpreterScript(args []string, dynamic []bool) (string, bool, error) {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-o" || arg == "+o" || arg == "-O" || arg == "+O":
+			i++
+			continue
+		case strings.HasPrefix(arg, "--") && arg != "--":
+			continue
+		case strings.HasPrefix(arg, "+"):
+			continue
+		case !strings.HasPrefix(arg, "-") || arg == "--" || arg == "-":
+			return "", false, nil
+		case !strings.Contains(arg[1:], "c"):
+			continue
+		}
+		if i+1 >= len(args) {
+			return "", false, fmt.Errorf("%s -c without a command", args[0])
+		}
+		if dynamic[i+1] {
+			return "", false, fmt.Errorf("%s -c with a script only known at run time: %s", args[0], args[i+1])
+		}
+		return args[i+1], true, nil
+	}
+	return "", false, nil
+}
+
+func (a *shellAnalyzer) chdir(args []string, dynamic []bool) {
+	target := ""
+	for i, arg := range args {
+		if arg == "--" || (strings.HasPrefix(arg, "-") && arg != "-") {
+			continue
+		}
+		if dynamic[i] || arg == "-" {
+			a.dirUnknown = true
+			return
+		}
+		target = arg
+		break
+	}
+	if target == "" {
+		target = "~"
+	}
+	target, ok := expandTilde(target)
+	if !ok {
+		a.dirUnknown = true
+		return
+	}
+	if filepath.IsAbs(target) {
+		a.dir = target
+		a.dirUnknown = false
+		return
+	}
+	a.dir = filepath.Join(a.dir, target)
+}
+
+// writeRedirects are the operators that open their target for writing.
+var writeRedirects = map[syntax.RedirOperator]bool{
+	syntax.RdrOut: true, syntax.AppOut: true, syntax.ClbOut: true,
+	syntax.RdrAll: true, syntax.AppAll: true, syntax.RdrInOut: true,
+}
+
+func (a *shellAnalyzer) redirect(r *syntax.Redirect) {
+	if r.Word == nil {
+		return
+	}
+	target, ok := shellWordLiteral(r.Word)
+	if !ok {
+		target = printShellWord(r.Word)
+	}
+	if r.Op == syntax.RdrIn || r.Op == syntax.RdrInOut {
+		if p, keep := shellPath(a.dir, a.dirUnknown, target, !ok); keep {
+			a.out.Reads = append(a.out.Reads, p)
+		}
+	}
+	switch {
+	case writeRedirects[r.Op]:
+	case r.Op == syntax.DplOut:
+		// >&2 duplicates a descriptor; >&file writes the file.
+		if ok && (target == "-" || strings.Trim(target, "0123456789") == "") {
+			return
+		}
+	default:
+		return
+	}
+	if p, keep := shellPath(a.dir, a.dirUnknown, target, !ok); keep {
+		a.out.Writes = append(a.out.Writes, p)
+	}
+}
+
+// shellPath resolves a path word against the directory the script is in.
+// keep is false for device files, which are never checked.
+func shellPath(dir string, dirUnknown bool, target string, dynamic bool) (ShellPath, bool) {
+	if dynamic {
+		return ShellPath{Target: target, Dynamic: true}, true
+	}
+	if isDevicePath(target) {
+		return ShellPath{}, false
+	}
+	target, expanded := expandTilde(target)
+	p := ShellPath{Target: target, Dynamic: !expanded}
+	if !filepath.IsAbs(target) {
+		if dirUnknown {
+			p.Dynamic = true
+		} else if dir != "" {
+			p.Target = filepath.Join(dir, target)
+		}
+	}
+	return p, true
+}
+
+func isDevicePath(p string) bool {
+	switch p {
+	case "/dev/null", "/dev/stdout", "/dev/stderr", "/dev/tty":
+		return true
+	}
+	return strings.HasPrefix(p, "/dev/fd/")
+}
+
+// expandTilde expands a leading ~ or ~/; ~user is reported as unknown.
+func expandTilde(p string) (string, bool) {
+	if !strings.HasPrefix(p, "~") {
+		return p, true
+	}
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, false
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p, false
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~")), true
+}
+
+// shellWordLiteral returns the word with quoting removed when it contains
+// no expansions.
+func shellWordLiteral(w *syntax.Word) (string, bool) {
+	var b strings.Builder
+	for _, part := range w.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			b.WriteString(unescapeShell(p.Value))
+		case *syntax.SglQuoted:
+			if p.Dollar {
+				return "", false
+			}
+			b.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, qp := range p.Parts {
+				lit, ok := qp.(*syntax.Lit)
+				if !ok {
+					return "", false
+				}
+				b.WriteString(unescapeDoubleQuoted(lit.Value))
+			}
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+func unescapeShell(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == '\n' {
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unescapeDoubleQuoted(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("$`\"\\\n", s[i+1]) >= 0 {
+			i++
+			if s[i] == '\n' {
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func printShellWord(w *syntax.Word) string {
+	var b strings.Builder
+	if err := syntax.NewPrinter().Print(&b, w); err != nil {
+		return "<unprintable>"
+	}
+	return b.String()
+}
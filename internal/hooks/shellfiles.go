@@ -0,0 +1,386 @@
+package hooks
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// shellReadCommands read every operand. The value lists the options that
+// take an argument.
+var shellReadCommands = map[string][]string{
+	".":         nil,
+	"base64":    {"-w", "--wrap"},
+	"cat":       nil,
+	"cmp":       {"-i", "-n", "--ignore-initial", "--bytes"},
+	"diff":      {"-I", "-x", "-X", "-L", "--label", "--exclude", "--exclude-from", "--ignore-matching-lines"},
+	"head":      {"-n", "-c", "--lines", "--bytes"},
+	"hexdump":   {"-n", "-s", "-e", "-f"},
+	"less":      nil,
+	"md5sum":    nil,
+	"more":      nil,
+	"nl":        {"-b", "-s", "-v", "-w"},
+	"od":        {"-A", "-j", "-N", "-t", "-w"},
+	"sha1sum":   nil,
+	"sha256sum": nil,
+	"sort":      {"-k", "-t", "-S", "-T", "-o", "--key", "--field-separator", "--output"},
+	"source":    nil,
+	"strings":   {"-n", "-t", "--bytes"},
+	"tac":       {"-s", "--separator"},
+	"tail":      {"-n", "-c", "--lines", "--bytes"},
+	"uniq":      {"-f", "-s", "-w"},
+	"wc":        nil,
+	"xxd":       {"-c", "-g", "-l", "-s"},
+}
+
+// shellSearchCommands take a pattern or script as their first operand
+// unless one of patternFlags supplies it, then read the remaining operands.
+var shellSearchCommands = map[string]struct {
+	valueFlags   []string
+	patternFlags []string
+}{
+	"grep":  {valueFlags: grepValueFlags, patternFlags: []string{"-e", "-f", "--regexp", "--file"}},
+	"egrep": {valueFlags: grepValueFlags, patternFlags: []string{"-e", "-f", "--regexp", "--file"}},
+	"fgrep": {valueFlags: grepValueFlags, patternFlags: []string{"-e", "-f", "--regexp", "--file"}},
+	"rg": {
+		valueFlags:   []string{"-e", "-f", "-g", "-t", "-T", "-m", "-A", "-B", "-C", "-j", "-M", "-E", "--regexp", "--file", "--glob", "--iglob", "--type", "--type-not", "--max-count", "--context", "--max-depth", "--threads", "--encoding", "--max-columns"},
+		patternFlags: []string{"-e", "-f", "--regexp", "--file"},
+	},
+	"sed":  {valueFlags: []string{"-e", "-f", "-l", "--expression", "--file", "--line-length"}, patternFlags: []string{"-e", "-f", "--expression", "--file"}},
+	"awk":  {valueFlags: []string{"-f", "-v", "-F"}, patternFlags: []string{"-f"}},
+	"gawk": {valueFlags: []string{"-f", "-v", "-F"}, patternFlags: []string{"-f"}},
+}
+
+var grepValueFlags = []string{"-e", "-f", "-m", "-A", "-B", "-C", "-d", "-D", "--regexp", "--file", "--max-count", "--after-context", "--before-context", "--context", "--include", "--exclude", "--exclude-dir", "--exclude-from", "--label"}
+
+// shellWriteCommands write, create, or delete every operand.
+var shellWriteCommands = map[string][]string{
+	"chgrp":    {"--reference"},
+	"chmod":    {"--reference"},
+	"chown":    {"--reference"},
+	"mkdir":    {"-m", "--mode"},
+	"rm":       nil,
+	"rmdir":    nil,
+	"shred":    {"-n", "-s", "--iterations", "--size"},
+	"tee":      nil,
+	"touch":    {"-d", "-r", "-t", "--date", "--reference"},
+	"truncate": {"-r", "-s", "--reference", "--size"},
+	"unlink":   nil,
+}
+
+// shellCopyCommands write their last operand, or the -t directory, and
+// read the others; mv also removes them.
+var shellCopyCommands = map[string][]string{
+	"cp":      {"-t", "-S", "--target-directory", "--suffix"},
+	"install": {"-t", "-S", "-m", "-o", "-g", "--target-directory", "--suffix", "--mode", "--owner", "--group"},
+	"ln":      {"-t", "-S", "--target-directory", "--suffix"},
+	"mv":      {"-t", "-S", "--target-directory", "--suffix"},
+}
+
+// shellGitWrites are the git subcommands that overwrite or delete the
+// paths they are given, and the options that take an argument.
+var shellGitWrites = map[string][]string{
+	"checkout": {"-b", "-B", "--orphan", "--conflict", "--pathspec-from-file"},
+	"clean":    {"-e", "--exclude"},
+	"mv":       nil,
+	"restore":  {"-s", "--source", "--conflict", "--pathspec-from-file"},
+	"rm":       {"--pathspec-from-file"},
+}
+
+// Files returns the paths the command reads and writes through its
+// arguments, for the commands listed in the tables above. Operands of other
+// commands are not treated as paths. Paths are resolved like redirect
+// targets; an operand only known at run time is returned as Dynamic.
+func (c ShellCommand) Files() (reads, writes []ShellPath) {
+	if len(c.Args) == 0 || c.ProgramDynamic {
+		return nil, nil
+	}
+	program := filepath.Base(c.Args[0])
+	add := func(list *[]ShellPath, dir string, a shellArg, tree bool) {
+		if p, ok := shellPath(dir, c.DirUnknown, a.text, c.isDynamic(a.index)); ok {
+			p.Tree = tree
+			*list = append(*list, p)
+		}
+	}
+	if flags, ok := shellReadCommands[program]; ok {
+		opts, ops := shellOperands(c.Args, 1, flags)
+		for _, a := range ops {
+			add(&reads, c.Dir, a, false)
+		}
+		if a, ok := opts.value("-o", "--output"); ok && program == "sort" {
+			add(&writes, c.Dir, a, false)
+		}
+		return reads, writes
+	}
+	if spec, ok := shellSearchCommands[program]; ok {
+		opts, ops := shellOperands(c.Args, 1, spec.valueFlags)
+		if !opts.given(spec.patternFlags...) && len(ops) > 0 {
+			ops = ops[1:]
+		}
+		if a, ok := opts.value("-f", "--file"); ok {
+			add(&reads, c.Dir, a, false)
+		}
+		tree := program == "rg" || opts.has('r', "--recursive") || opts.has('R', "--dereference-recursive")
+		for _, a := range ops {
+			add(&reads, c.Dir, a, tree)
+		}
+		if tree && len(ops) == 0 {
+			reads = append(reads, ShellPath{Target: c.Dir, Dynamic: c.DirUnknown, Tree: true})
+		}
+		if program == "sed" && opts.has('i', "--in-place") {
+			for _, a := range ops {
+				add(&writes, c.Dir, a, false)
+			}
+		}
+		return reads, writes
+	}
+	if flags, ok := shellWriteCommands[program]; ok {
+		opts, ops := shellOperands(c.Args, 1, flags)
+		switch program {
+		case "chmod", "chown", "chgrp":
+			// The first operand is the mode or owner.
+			if !opts.given("--reference") && len(ops) > 0 {
+				ops = ops[1:]
+			}
+		}
+		tree := program != "tee" && (opts.has('r', "--recursive") || opts.has('R', "--recursive"))
+		for _, a := range ops {
+			add(&writes, c.Dir, a, tree)
+		}
+		if a, ok := opts.value("-r", "--reference"); ok && program != "rm" {
+			add(&reads, c.Dir, a, false)
+		}
+		return reads, writes
+	}
+	if flags, ok := shellCopyCommands[program]; ok {
+		opts, ops := shellOperands(c.Args, 1, flags)
+		dest, hasDest := opts.value("-t", "--target-directory")
+		if program == "install" && opts.has('d', "--directory") {
+			for _, a := range ops {
+				add(&writes, c.Dir, a, false)
+			}
+			return reads, writes
+		}
+		if !hasDest {
+			if len(ops) < 2 {
+				// A single operand is a source written into the current
+				// directory (ln) or an error.
+				for _, a := range ops {
+					add(&reads, c.Dir, a, false)
+				}
+				return reads, writes
+			}
+			dest = ops[len(ops)-1]
+			ops = ops[:len(ops)-1]
+		}
+		recursive := program == "mv" || opts.has('r', "--recursive") || opts.has('R', "--recursive") || opts.has('a', "--archive")
+		add(&writes, c.Dir, dest, false)
+		for _, a := range ops {
+			if program == "mv" {
+				add(&writes, c.Dir, a, true)
+			} else {
+				add(&reads, c.Dir, a, recursive)
+			}
+			// A source copied into a directory lands at dest/<name>.
+			if p, ok := shellPath(c.Dir, c.DirUnknown, dest.text, c.isDynamic(dest.index) || c.isDynamic(a.index)); ok {
+				p.Target = filepath.Join(p.Target, filepath.Base(a.text))
+				p.Tree = recursive
+				writes = append(writes, p)
+			}
+		}
+		return reads, writes
+	}
+	switch program {
+	case "dd":
+		for i, arg := range c.Args[1:] {
+			switch {
+			case strings.HasPrefix(arg, "if="):
+				if p, ok := shellPath(c.Dir, c.DirUnknown, arg[3:], c.isDynamic(i+1)); ok {
+					reads = append(reads, p)
+				}
+			case strings.HasPrefix(arg, "of="):
+				if p, ok := shellPath(c.Dir, c.DirUnknown, arg[3:], c.isDynamic(i+1)); ok {
+					writes = append(writes, p)
+				}
+			}
+		}
+	case "git":
+		writes = c.gitWrites()
+	}
+	return reads, writes
+}
+
+// gitWrites returns the paths a git checkout, restore, rm, mv, or clean
+// overwrites. Every operand is treated as a path, since a branch name and
+// a file cannot be told apart here; after "--" only paths remain.
+func (c ShellCommand) gitWrites() []ShellPath {
+	dir := c.Dir
+	dirDynamic := false
+	i := 1
+	for i < len(c.Args) && strings.HasPrefix(c.Args[i], "-") {
+		switch c.Args[i] {
+		case "-C":
+			if i+1 < len(c.Args) {
+				dirDynamic = dirDynamic || c.isDynamic(i+1)
+				if filepath.IsAbs(c.Args[i+1]) {
+					dir = c.Args[i+1]
+				} else {
+					dir = filepath.Join(dir, c.Args[i+1])
+				}
+			}
+			i += 2
+		case "-c", "--git-dir", "--work-tree", "--namespace":
+			i += 2
+		default:
+			i++
+		}
+	}
+	if i >= len(c.Args) {
+		return nil
+	}
+	flags, ok := shellGitWrites[c.Args[i]]
+	if !ok {
+		return nil
+	}
+	sub := c.Args[i]
+	_, ops := shellOperands(c.Args, i+1, flags)
+	for k := i + 1; k < len(c.Args); k++ {
+		if c.Args[k] == "--" {
+			ops = ops[len(ops)-(len(c.Args)-k-1):]
+			break
+		}
+	}
+	var out []ShellPath
+	for _, a := range ops {
+		if p, ok := shellPath(dir, c.DirUnknown || dirDynamic, a.text, c.isDynamic(a.index)); ok {
+			p.Tree = true
+			out = append(out, p)
+		}
+	}
+	if sub == "clean" && len(ops) == 0 {
+		out = append(out, ShellPath{Target: dir, Dynamic: c.DirUnknown || dirDynamic, Tree: true})
+	}
+	return out
+}
+
+func (c ShellCommand) isDynamic(i int) bool {
+	return i < len(c.dynamic) && c.dynamic[i]
+}
+
+// shellArg is an operand or option value: its text and the index of the
+// argument it came from. An inline value ("--output=x", "-tDIR") is the text
+// after the option within that argument.
+type shellArg struct {
+	text  string
+	index int
+}
+
+// shellOptions are the options a command was given, keyed by name; the
+// value is the option's argument, with index -1 when it takes none.
+type shellOptions map[string]shellArg
+
+// value returns the argument of the first of names that was given.
+func (o shellOptions) value(names ...string) (shellArg, bool) {
+	for _, n := range names {
+		if a, ok := o[n]; ok && a.index >= 0 {
+			return a, true
+		}
+	}
+	return shellArg{}, false
+}
+
+// given reports whether any of names was given.
+func (o shellOptions) given(names ...string) bool {
+	for _, n := range names {
+		if _, ok := o[n]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether the short option letter, alone or in a cluster, or
+// the long option was given.
+func (o shellOptions) has(short byte, long string) bool {
+	if _, ok := o[long]; ok {
+		return true
+	}
+	for name := range o {
+		if len(name) > 1 && name[0] == '-' && name[1] != '-' && strings.IndexByte(name[1:], short) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// shellOperands splits args[start:] into options and operands the way
+// getopt does. Options in valueFlags take an argument: the text after "="
+// for a long option, the rest of a short-option cluster, or else the next
+// argument. A long option may be abbreviated to a unique prefix of one in
+// valueFlags. "--" ends the options.
+func shellOperands(args []string, start int, valueFlags []string) (shellOptions, []shellArg) {
+	opts := shellOptions{}
+	var ops []shellArg
+	for i := start; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			for j := i + 1; j < len(args); j++ {
+				ops = append(ops, shellArg{text: args[j], index: j})
+			}
+			return opts, ops
+		case strings.HasPrefix(arg, "--"):
+			name, inline, hasInline := strings.Cut(arg, "=")
+			name = longValueFlag(valueFlags, name)
+			switch {
+			case !isValueFlag(valueFlags, name):
+				opts[name] = shellArg{index: -1}
+			case hasInline:
+				opts[name] = shellArg{text: inline, index: i}
+			case i+1 < len(args):
+				opts[name] = shellArg{text: args[i+1], index: i + 1}
+				i++
+			default:
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			opts[arg] = shellArg{index: -1}
+			for k := 1; k < len(arg); k++ {
+				name := "-" + arg[k:k+1]
+				if !isValueFlag(valueFlags, name) {
+					continue
+				}
+				if k+1 < len(arg) {
+					opts[name] = shellArg{text: arg[k+1:], index: i}
+				} else if i+1 < len(args) {
+					opts[name] = shellArg{text: args[i+1], index: i + 1}
+					i++
+				}
+				break
+			}
+		default:
+			ops = append(ops, shellArg{text: arg, index: i})
+		}
+	}
+	return opts, ops
+}
+
+// longValueFlag expands an abbreviated long option to the one value flag it
+// is a unique prefix of, and returns other names unchanged.
+func longValueFlag(valueFlags []string, name string) string {
+	match := ""
+	for _, f := range valueFlags {
+		if f == name {
+			return f
+		}
+		if strings.HasPrefix(f, "--") && strings.HasPrefix(f, name) {
+			if match != "" {
+				return name
+			}
+			match = f
+		}
+	}
+	if match == "" {
+		return name
+	}
+	return match
+}
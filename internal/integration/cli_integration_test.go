@@ -349,6 +349,9 @@ func runFakeBD() {
 		os.Exit(2)
 	}
 	switch args[0] {
+	case "version":
+		fmt.Println("bd version 0.0.0-fake")
+		os.Exit(0)
 	case "init":
 		_ = os.MkdirAll(filepath.Join(mustGetwd(), ".beads"), 0o755)
 		_ = saveStore(storePath, loadStoreRaw(storePath))
@@ -358,6 +361,10 @@ func runFakeBD() {
 		_ = json.NewEncoder(os.Stdout).Encode(issue)
 		os.Exit(0)
 	case "update":
+		if len(args) > 1 && args[1] == "--help" {
+			fmt.Println("Flags:\n  -d, --description string\n  -s, --status string")
+			os.Exit(0)
+		}
 		issue := handleBdUpdate(storePath, args[1:])
 		_ = json.NewEncoder(os.Stdout).Encode(issue)
 		os.Exit(0)
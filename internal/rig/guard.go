@@ -0,0 +1,258 @@
+package rig
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ControlPaths are microforge's own files in a worktree: agent identity,
+// hook wiring, Claude settings, and mail. Agents may never write them,
+// whatever the cell's protected list says; guardrails let an agent write
+// only the outbox file of its current assignment.
+var ControlPaths = []string{".mf", ".claude", "mail"}
+
+// DefaultProtectedPaths are written to cell.json by `cell bootstrap` when
+// the cell has no protected list.
+var DefaultProtectedPaths = []string{".git", "kubeconfig.yaml"}
+
+// DefaultReadDeny returns the read_deny list `cell bootstrap` writes when
+// the cell has none: env files, keys, the kubeconfig copied into the
+// worktree, other cells' worktrees, agent env files, the secrets store,
+// and the user's cloud and SSH credentials.
+func DefaultReadDeny(home, rigName string) []string {
+	return []string{
+		"**/.env",
+		"**/.env.*",
+		"**/*.pem",
+		"**/*.key",
+		"**/id_rsa*",
+		"kubeconfig.yaml",
+		filepath.ToSlash(filepath.Join(CellsDir(home, rigName), "*", "worktree", "**")),
+		filepath.ToSlash(filepath.Join(home, "run", "**")),
+		filepath.ToSlash(SecretsPath(home)),
+		"~/.aws/**",
+		"~/.kube/**",
+		"~/.ssh/**",
+	}
+}
+
+// WritePatterns returns the patterns agents may never write: the control
+// paths, everything under the microforge home outside the agent's own
+// worktree (rig and cell configs, policy files, secrets), and the cell's
+// protected list.
+func (c CellConfig) WritePatterns(home string) []string {
+	out := append([]string{}, ControlPaths...)
+	if strings.TrimSpace(home) != "" {
+		if abs, err := filepath.Abs(home); err == nil {
+			out = append(out, filepath.ToSlash(filepath.Join(abs, "**")))
+		}
+	}
+	return append(out, c.Protected...)
+}
+
+// MatchGuardPath reports whether fp, resolved against the worktree root,
+// matches any guard pattern. Relative patterns use scope syntax and apply
+// to paths inside root; absolute and "~/" patterns apply to paths outside
+// it, so a pattern covering every cell's worktree does not cover the
+// agent's own.
+func MatchGuardPath(patterns []string, root, fp string) bool {
+	if strings.TrimSpace(fp) == "" {
+		return false
+	}
+	target := fp
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	targetAbs, err := filepath.Abs(target)
+	if err != nil {
+		return false
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	rel, inside := relWithin(rootAbs, targetAbs)
+	for _, p := range patterns {
+		p, ok := expandGuardPattern(p)
+		if !ok {
+			continue
+		}
+		if filepath.IsAbs(p) {
+			if !inside && matchPattern(filepath.ToSlash(p), filepath.ToSlash(targetAbs)) {
+				return true
+			}
+			continue
+		}
+		if inside && matchPattern(p, filepath.ToSlash(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxGuardWalk bounds the entries a tree check visits. A larger tree is
+// reported as a match: it cannot be shown to be clean.
+const maxGuardWalk = 100000
+
+var errGuardWalkDone = errors.New("guard walk done")
+
+// GuardTreeMatch is MatchGuardPath for an operation on dir and everything
+// beneath it, such as a recursive search or delete. An empty dir is the
+// worktree root. It returns the first matching path it finds: dir itself,
+// the base of an absolute pattern under dir, or an entry in the part of
+// the worktree under dir.
+func GuardTreeMatch(patterns []string, root, dir string) (string, bool) {
+	return guardTreeMatch(patterns, root, dir, nil)
+}
+
+// GuardSearchMatch is GuardTreeMatch for a search rooted at dir that only
+// reads files matching one of globs (a Grep glob or type, a Glob pattern).
+// A glob without a slash matches a file name at any depth. Globs this
+// check cannot interpret, such as braces or negations, match every file.
+func GuardSearchMatch(patterns []string, root, dir string, globs []string) (string, bool) {
+	if strings.TrimSpace(dir) == "" {
+		dir = root
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	var filters []string
+	for _, g := range globs {
+		if g = strings.TrimSpace(g); g != "" {
+			filters = append(filters, g)
+		}
+	}
+	if len(filters) == 0 {
+		return GuardTreeMatch(patterns, root, dir)
+	}
+	for _, g := range filters {
+		g, ok := expandGuardPattern(g)
+		if !ok || strings.ContainsAny(g, "{}[]!\\") {
+			if m, ok := GuardTreeMatch(patterns, root, dir); ok {
+				return m, true
+			}
+			continue
+		}
+		// A glob's literal directory prefix moves the search, possibly
+		// out of dir.
+		base := filepath.FromSlash(patternBase(g))
+		rest := strings.TrimPrefix(strings.TrimPrefix(normalizeScopePath(g), filepath.ToSlash(base)), "/")
+		searchDir := dir
+		if filepath.IsAbs(base) {
+			searchDir = base
+		} else if base != "" {
+			searchDir = filepath.Join(dir, base)
+		}
+		if rest == "" {
+			if m, ok := GuardTreeMatch(patterns, root, searchDir); ok {
+				return m, true
+			}
+			continue
+		}
+		filter := func(rel string) bool {
+			if !strings.Contains(rest, "/") {
+				if ok, _ := path.Match(rest, path.Base(rel)); ok {
+					return true
+				}
+			}
+			return matchPattern(rest, rel)
+		}
+		if m, ok := guardTreeMatch(patterns, root, searchDir, filter); ok {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// guardTreeMatch implements GuardTreeMatch. A non-nil filter limits the
+// walk to files whose path relative to dir it accepts.
+func guardTreeMatch(patterns []string, root, dir string, filter func(rel string) bool) (string, bool) {
+	if strings.TrimSpace(dir) == "" {
+		dir = root
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	dirAbs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir, true
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return dir, true
+	}
+	if MatchGuardPath(patterns, rootAbs, dirAbs) {
+		return dirAbs, true
+	}
+	walkRoot := dirAbs
+	if _, inside := relWithin(rootAbs, dirAbs); !inside {
+		for _, p := range patterns {
+			p, ok := expandGuardPattern(p)
+			if !ok || !filepath.IsAbs(p) {
+				continue
+			}
+			if base := filepath.FromSlash(patternBase(p)); base != "" {
+				if _, ok := relWithin(dirAbs, base); ok {
+					return base, true
+				}
+			}
+		}
+		if _, ok := relWithin(dirAbs, rootAbs); !ok {
+			return "", false
+		}
+		walkRoot = rootAbs
+	}
+	found := ""
+	n := 0
+	err = filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if n++; n > maxGuardWalk {
+			found = walkRoot
+			return errGuardWalkDone
+		}
+		skip := false
+		if filter != nil {
+			rel, _ := filepath.Rel(dirAbs, p)
+			skip = d.IsDir() || !filter(filepath.ToSlash(rel))
+		}
+		if !skip && MatchGuardPath(patterns, rootAbs, p) {
+			found = p
+			return errGuardWalkDone
+		}
+		if d.IsDir() && d.Name() == ".git" && p != walkRoot {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errGuardWalkDone) {
+		return walkRoot, true
+	}
+	return found, found != ""
+}
+
+// expandGuardPattern expands a leading "~/"; ok is false when the user's
+// home directory is unknown.
+func expandGuardPattern(p string) (string, bool) {
+	p = strings.TrimSpace(p)
+	if !strings.HasPrefix(p, "~/") {
+		return p, true
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p, false
+	}
+	return filepath.Join(home, p[2:]), true
+}
+
+// relWithin returns target relative to base and whether target is base or
+// lies beneath it. Both paths must be absolute.
+func relWithin(base, target string) (string, bool) {
+	rel, err := filepath.Rel(base, target)
+	return rel, err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
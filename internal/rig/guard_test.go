@@ -0,0 +1,76 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGuardPath(t *testing.T) {
+	home := "/mf"
+	wt := CellWorktreeDir(home, "r", "api")
+	other := CellWorktreeDir(home, "r", "web")
+	userHome, _ := os.UserHomeDir()
+	deny := DefaultReadDeny(home, "r")
+	cases := []struct {
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{deny, ".env", true},
+		{deny, "svc/api/.env.local", true},
+		{deny, wt + "/kubeconfig.yaml", true},
+		{deny, "svc/api/main.go", false},
+		{deny, filepath.Join(other, "svc/web/main.go"), true},
+		{deny, filepath.Join(wt, "svc/api/main.go"), false},
+		{deny, filepath.Join(home, "run", "mforge-r-api-builder.env"), true},
+		{deny, SecretsPath(home), true},
+		{deny, filepath.Join(userHome, ".ssh", "id_ed25519"), true},
+		{CellConfig{}.WritePatterns(home), ".mf/active-agent.json", true},
+		{CellConfig{}.WritePatterns(home), wt + "/.claude/settings.json", true},
+		{CellConfig{}.WritePatterns(home), "mail/outbox/mf-1.md", true},
+		{CellConfig{}.WritePatterns(home), "svc/api/mail.go", false},
+		{CellConfig{Protected: DefaultProtectedPaths}.WritePatterns(home), ".git/config", true},
+		{CellConfig{}.WritePatterns(home), "../.mf/policy.json", true},
+		{CellConfig{}.WritePatterns(home), CellConfigPath(home, "r", "api"), true},
+		{CellConfig{}.WritePatterns(home), "/tmp/out.txt", false},
+	}
+	for _, c := range cases {
+		if got := MatchGuardPath(c.patterns, wt, c.path); got != c.want {
+			t.Errorf("MatchGuardPath(%q) = %v, want %v", c.path, got, c.want)
+		}
+	}
+}
+
+func TestGuardTreeAndSearchMatch(t *testing.T) {
+	home := t.TempDir()
+	wt := CellWorktreeDir(home, "r", "api")
+	for _, f := range []string{"svc/.env", "svc/main.go"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(wt, f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(wt, f), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deny := DefaultReadDeny(home, "r")
+	cases := []struct {
+		dir   string
+		globs []string
+		want  bool
+	}{
+		{"", nil, true},
+		{"svc", nil, true},
+		{"svc", []string{"*.go"}, false},
+		{"", []string{"**/.env"}, true},
+		{"", []string{"svc/*"}, true},
+		{"", []string{"*.{go,env}"}, true},
+		{CellsDir(home, "r"), []string{"*.go"}, true},
+		{t.TempDir(), nil, false},
+	}
+	for _, c := range cases {
+		if _, got := GuardSearchMatch(deny, wt, c.dir, c.globs); got != c.want {
+			t.Errorf("GuardSearchMatch(%q, %v) = %v, want %v", c.dir, c.globs, got, c.want)
+		}
+	}
+}
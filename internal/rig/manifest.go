@@ -0,0 +1,236 @@
+package rig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the manifest's conventional name at the monorepo root.
+const ManifestFile = "microforge.yaml"
+
+// Manifest is microforge.yaml: the desired state of one rig and its cells,
+// converged by `mforge plan` and `mforge apply`.
+type Manifest struct {
+	Rig          string                `yaml:"rig"`
+	Repo         string                `yaml:"repo,omitempty"`
+	Beads        string                `yaml:"beads,omitempty"`
+	TmuxPrefix   string                `yaml:"tmux_prefix,omitempty"`
+	Runtime      ManifestRuntime       `yaml:"runtime,omitempty"`
+	Library      ManifestLibrary       `yaml:"library,omitempty"`
+	CellDefaults *CellOverrides        `yaml:"cell_defaults,omitempty"`
+	Roles        []RoleSpec            `yaml:"roles,omitempty"`
+	Hooks        map[string][]HookSpec `yaml:"hooks,omitempty"`
+	Cells        []ManifestCell        `yaml:"cells"`
+
+	// Path is where the manifest was loaded from.
+	Path string `yaml:"-"`
+}
+
+// ManifestRuntime mirrors the runtime fields of rig.json.
+type ManifestRuntime struct {
+	Provider string                 `yaml:"provider,omitempty"`
+	Cmd      string                 `yaml:"cmd,omitempty"`
+	Args     []string               `yaml:"args,omitempty"`
+	Roles    map[string]RuntimeSpec `yaml:"roles,omitempty"`
+}
+
+// ManifestLibrary mirrors the library fields of rig.json.
+type ManifestLibrary struct {
+	Addr string   `yaml:"addr,omitempty"`
+	Docs []string `yaml:"docs,omitempty"`
+}
+
+// HookSpec is one .mf/hooks.json action, written to every cell worktree.
+type HookSpec struct {
+	Command         string   `yaml:"command" json:"command"`
+	OnlyRoles       []string `yaml:"only_roles,omitempty" json:"only_roles,omitempty"`
+	OnlyCells       []string `yaml:"only_cells,omitempty" json:"only_cells,omitempty"`
+	TimeoutSec      int      `yaml:"timeout_sec,omitempty" json:"timeout_sec,omitempty"`
+	ContinueOnError bool     `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+}
+
+// ManifestCell declares one cell. Roles defaults to builder, monitor and
+// reviewer; listing "cell" bootstraps a single-agent cell.
+type ManifestCell struct {
+	Name      string         `yaml:"name"`
+	Scope     string         `yaml:"scope"`
+	Include   []string       `yaml:"include,omitempty"`
+	Exclude   []string       `yaml:"exclude,omitempty"`
+	Roles     []string       `yaml:"roles,omitempty"`
+	Overrides *CellOverrides `yaml:"overrides,omitempty"`
+	Protected []string       `yaml:"protected,omitempty"`
+	ReadDeny  []string       `yaml:"read_deny,omitempty"`
+}
+
+// LoadManifest reads and validates a manifest. A relative repo is resolved
+// against the manifest's directory, which is also the default.
+func LoadManifest(path string) (Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("reading manifest %s: %w", path, err)
+	}
+	var m Manifest
+	dec := yaml.NewDecoder(strings.NewReader(string(b)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	m.Path = abs
+	repo := strings.TrimSpace(m.Repo)
+	if repo == "" {
+		repo = "."
+	}
+	if !filepath.IsAbs(repo) {
+		repo = filepath.Join(filepath.Dir(abs), repo)
+	}
+	m.Repo = filepath.Clean(repo)
+	if err := m.validate(); err != nil {
+		return Manifest{}, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func (m Manifest) validate() error {
+	if strings.TrimSpace(m.Rig) == "" {
+		return fmt.Errorf("missing rig name")
+	}
+	switch m.Beads {
+	case "", "bd", "jsonl":
+	default:
+		return fmt.Errorf("unknown beads backend %q (want bd or jsonl)", m.Beads)
+	}
+	if err := ValidateRoles(m.Roles); err != nil {
+		return err
+	}
+	custom := map[string]bool{}
+	for _, r := range m.Roles {
+		if !isBuiltinRole(r.Name) {
+			custom[r.Name] = true
+		}
+	}
+	seen := map[string]bool{}
+	for i, c := range m.Cells {
+		if strings.TrimSpace(c.Name) == "" {
+			return fmt.Errorf("cells[%d]: missing name", i)
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("cells[%d]: duplicate cell %q", i, c.Name)
+		}
+		seen[c.Name] = true
+		if strings.TrimSpace(c.Scope) == "" {
+			return fmt.Errorf("cell %s: missing scope", c.Name)
+		}
+		if err := validateCellRoles(c.Roles, custom); err != nil {
+			return fmt.Errorf("cell %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// CellRoles returns the roles to bootstrap for c.
+func (c ManifestCell) CellRoles() []string {
+	if len(c.Roles) == 0 {
+		return []string{"builder", "monitor", "reviewer"}
+	}
+	return c.Roles
+}
+
+// Apply returns cfg with the manifest's rig settings written over it.
+// Settings the manifest leaves out keep their rig.json values. The beads
+// backend is left alone; changing it needs a migration.
+func (m Manifest) Apply(cfg RigConfig) RigConfig {
+	cfg.RepoPath = m.Repo
+	if m.TmuxPrefix != "" {
+		cfg.TmuxPrefix = m.TmuxPrefix
+	}
+	if m.Runtime.Provider != "" {
+		cfg.RuntimeProvider = m.Runtime.Provider
+	}
+	if m.Runtime.Cmd != "" {
+		cfg.RuntimeCmd = m.Runtime.Cmd
+	}
+	if len(m.Runtime.Args) > 0 {
+		cfg.RuntimeArgs = append([]string{}, m.Runtime.Args...)
+	}
+	if m.Runtime.Roles != nil {
+		cfg.RuntimeRoles = map[string]RuntimeSpec{}
+		for role, spec := range m.Runtime.Roles {
+			cfg.RuntimeRoles[role] = spec
+		}
+	}
+	if m.Library.Addr != "" {
+		cfg.LibraryAddr = m.Library.Addr
+	}
+	if m.Library.Docs != nil {
+		cfg.LibraryDocs = append([]string{}, m.Library.Docs...)
+	}
+	if m.CellDefaults != nil {
+		cfg.CellDefaults = m.CellDefaults
+	}
+	if m.Roles != nil {
+		cfg.Roles = append([]RoleSpec{}, m.Roles...)
+	}
+	return cfg
+}
+
+// CellConfig returns the cell.json the manifest asks for, keeping the
+// existing worktree path and creation time.
+func (c ManifestCell) CellConfig(existing CellConfig) CellConfig {
+	existing.Name = c.Name
+	existing.ScopePrefix = c.Scope
+	existing.Include = c.Include
+	existing.Exclude = c.Exclude
+	existing.Overrides = c.Overrides
+	// Guard lists left out of the manifest keep what bootstrap generated.
+	if c.Protected != nil {
+		existing.Protected = c.Protected
+	}
+	if c.ReadDeny != nil {
+		existing.ReadDeny = c.ReadDeny
+	}
+	return existing
+}
+
+// validateCellRoles accepts the role sets `cell bootstrap` can produce:
+// builder, monitor and reviewer, or cell alone, each optionally with
+// architect and any roles the manifest defines.
+func validateCellRoles(roles []string, custom map[string]bool) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	set := map[string]bool{}
+	for _, role := range roles {
+		if custom[role] {
+			continue
+		}
+		if !isBuiltinRole(role) {
+			return fmt.Errorf("unknown role %q (define it under roles)", role)
+		}
+		set[role] = true
+	}
+	delete(set, "architect")
+	team := set["builder"] && set["monitor"] && set["reviewer"] && len(set) == 3
+	single := set["cell"] && len(set) == 1
+	if !team && !single {
+		return fmt.Errorf("roles must be builder, monitor and reviewer, or cell, optionally with architect and custom roles")
+	}
+	return nil
+}
+
+// HasRole reports whether the cell bootstraps role.
+func (c ManifestCell) HasRole(role string) bool {
+	for _, r := range c.CellRoles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
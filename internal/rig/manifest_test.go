@@ -0,0 +1,71 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifestValidates(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"rig: r\ncells:\n  - name: a\n":                                      "missing scope",
+		"rig: r\ncells:\n  - {name: a, scope: x}\n  - {name: a, scope: y}\n": "duplicate cell",
+		"rig: r\ncells:\n  - {name: a, scope: x, roles: [builder]}\n":        "roles must be",
+		"rig: r\nbeads: sqlite\n":                                            "unknown beads backend",
+		"rig: r\ncells:\n  - {name: a, scope: x, scopes: [y]}\n":             "field scopes not found",
+		"rig: r\ncells:\n  - {name: a, scope: x, roles: [cell, auditor]}\n":  "unknown role",
+		"rig: r\nroles:\n  - {name: auditor, bash: some}\n":                  "bash must be",
+	}
+	for body, want := range cases {
+		path := filepath.Join(dir, ManifestFile)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadManifest(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: expected error containing %q, got %v", body, want, err)
+		}
+	}
+
+	body := "rig: r\nrepo: ../mono\ncells:\n  - {name: a, scope: x, roles: [cell, architect]}\n"
+	path := filepath.Join(dir, ManifestFile)
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Repo != filepath.Join(filepath.Dir(dir), "mono") {
+		t.Fatalf("expected repo relative to the manifest, got %s", m.Repo)
+	}
+	if !m.Cells[0].HasRole("architect") || m.Cells[0].HasRole("builder") {
+		t.Fatalf("unexpected roles %v", m.Cells[0].CellRoles())
+	}
+}
+
+func TestManifestApplyKeepsUnsetSettings(t *testing.T) {
+	cfg := RigConfig{
+		RepoPath:     "/old",
+		TmuxPrefix:   "mf",
+		RuntimeCmd:   "claude",
+		CellDefaults: &CellOverrides{Model: "opus"},
+		Roles:        []RoleSpec{{Name: "security-auditor", Access: "readonly"}},
+	}
+	got := Manifest{Rig: "r", Repo: "/mono"}.Apply(cfg)
+	if got.RepoPath != "/mono" || got.TmuxPrefix != "mf" || got.RuntimeCmd != "claude" {
+		t.Fatalf("unexpected rig settings %+v", got)
+	}
+	if got.CellDefaults == nil || got.CellDefaults.Model != "opus" {
+		t.Fatalf("expected cell_defaults kept, got %+v", got.CellDefaults)
+	}
+	if len(got.Roles) != 1 || got.Roles[0].Name != "security-auditor" {
+		t.Fatalf("expected roles kept, got %+v", got.Roles)
+	}
+
+	got = Manifest{Rig: "r", Repo: "/mono", Roles: []RoleSpec{}, CellDefaults: &CellOverrides{Model: "haiku"}}.Apply(cfg)
+	if len(got.Roles) != 0 || got.CellDefaults.Model != "haiku" {
+		t.Fatalf("expected an explicit manifest to replace roles and defaults, got %+v", got)
+	}
+}
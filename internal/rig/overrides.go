@@ -0,0 +1,228 @@
+package rig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultAllowedCommands are the commands a builder may run through Bash
+// when neither the rig nor the cell sets allowed_commands.
+var DefaultAllowedCommands = []string{"cat", "env", "git", "go", "grep", "ls", "make", "pwd", "rg", "sed"}
+
+// Default heartbeat ages past which a running agent is reported stale or idle.
+const (
+	DefaultStaleAfter = 15 * time.Minute
+	DefaultIdleAfter  = 5 * time.Minute
+)
+
+// DefaultDenialsPerTurn is how many guardrail denials an agent may hit in
+// one turn before a guardrail_denials event is emitted.
+const DefaultDenialsPerTurn = 5
+
+// CellOverrides are optional settings that replace rig-wide behavior. They
+// appear as cell_defaults in rig.json and as overrides in cell.json; Resolve
+// merges them field by field, cell over rig over built-in defaults.
+type CellOverrides struct {
+	RuntimeRoles    map[string]RuntimeSpec `json:"runtime_roles,omitempty" yaml:"runtime_roles,omitempty"`
+	Model           string                 `json:"model,omitempty" yaml:"model,omitempty"`
+	Env             map[string]string      `json:"env,omitempty" yaml:"env,omitempty"`
+	TestCmd         []string               `json:"test_cmd,omitempty" yaml:"test_cmd,omitempty"`
+	LintCmd         []string               `json:"lint_cmd,omitempty" yaml:"lint_cmd,omitempty"`
+	BuildCmd        []string               `json:"build_cmd,omitempty" yaml:"build_cmd,omitempty"`
+	AllowedCommands []string               `json:"allowed_commands,omitempty" yaml:"allowed_commands,omitempty"`
+	Health          *HealthThresholds      `json:"health,omitempty" yaml:"health,omitempty"`
+}
+
+// HealthThresholds are heartbeat ages in Go duration syntax ("20m") and the
+// guardrail denials per turn that raise an event.
+type HealthThresholds struct {
+	StaleAfter     string `json:"stale_after,omitempty" yaml:"stale_after,omitempty"`
+	IdleAfter      string `json:"idle_after,omitempty" yaml:"idle_after,omitempty"`
+	DenialsPerTurn int    `json:"denials_per_turn,omitempty" yaml:"denials_per_turn,omitempty"`
+}
+
+// Resolved is the effective configuration for one cell. Source records
+// which layer ("default", "rig", "cell") supplied each overridable field.
+type Resolved struct {
+	Rig             RigConfig
+	Cell            CellConfig
+	Model           string
+	Env             map[string]string
+	TestCmd         []string
+	LintCmd         []string
+	BuildCmd        []string
+	AllowedCommands []string
+	StaleAfter      time.Duration
+	IdleAfter       time.Duration
+	DenialsPerTurn  int
+	Source          map[string]string
+}
+
+// Resolve merges the rig's cell_defaults and the cell's overrides over the
+// rig configuration, starting from each registered role's runtime. Every
+// command that needs per-cell behavior reads it from the result rather than
+// from RigConfig directly.
+func Resolve(rc RigConfig, cc CellConfig) (Resolved, error) {
+	return ResolveRole(rc, cc, "")
+}
+
+// ResolveRole is Resolve for an agent in role. The role's allowed_commands
+// are part of the rig layer, applied over cell_defaults, so the cell's
+// overrides still win.
+func ResolveRole(rc RigConfig, cc CellConfig, role string) (Resolved, error) {
+	r := Resolved{
+		Rig:             rc,
+		Cell:            cc,
+		Env:             map[string]string{},
+		AllowedCommands: append([]string{}, DefaultAllowedCommands...),
+		StaleAfter:      DefaultStaleAfter,
+		IdleAfter:       DefaultIdleAfter,
+		DenialsPerTurn:  DefaultDenialsPerTurn,
+		Source:          map[string]string{},
+	}
+	for _, k := range []string{"model", "env", "test_cmd", "lint_cmd", "build_cmd", "allowed_commands", "health.stale_after", "health.idle_after", "health.denials_per_turn"} {
+		r.Source[k] = "default"
+	}
+	roles := map[string]RuntimeSpec{}
+	for _, spec := range rc.RoleSpecs() {
+		if spec.Runtime != nil {
+			roles[spec.Name] = *spec.Runtime
+		}
+	}
+	for role, spec := range rc.RuntimeRoles {
+		roles[role] = spec
+	}
+	r.Rig.RuntimeRoles = roles
+	if rc.CellDefaults != nil {
+		if err := r.apply(*rc.CellDefaults, "rig"); err != nil {
+			return Resolved{}, fmt.Errorf("rig.json cell_defaults: %w", err)
+		}
+	}
+	if spec, ok := rc.Role(role); ok && len(spec.AllowedCommands) > 0 {
+		r.AllowedCommands = append([]string{}, spec.AllowedCommands...)
+		r.Source["allowed_commands"] = "rig"
+	}
+	if cc.Overrides != nil {
+		if err := r.apply(*cc.Overrides, "cell"); err != nil {
+			return Resolved{}, fmt.Errorf("cell.json overrides for %s: %w", cc.Name, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *Resolved) apply(o CellOverrides, layer string) error {
+	for role, spec := range o.RuntimeRoles {
+		base := r.Rig.RuntimeRoles[role]
+		if strings.TrimSpace(spec.Cmd) != "" {
+			base.Cmd = spec.Cmd
+		}
+		if len(spec.Args) > 0 {
+			base.Args = append([]string{}, spec.Args...)
+		}
+		r.Rig.RuntimeRoles[role] = base
+		r.Source["runtime_roles."+role] = layer
+	}
+	if m := strings.TrimSpace(o.Model); m != "" {
+		r.Model = m
+		r.Source["model"] = layer
+	}
+	if len(o.Env) > 0 {
+		for k, v := range o.Env {
+			r.Env[k] = v
+		}
+		r.Source["env"] = layer
+	}
+	setCmd := func(dst *[]string, src []string, key string) {
+		if len(src) > 0 {
+			*dst = append([]string{}, src...)
+			r.Source[key] = layer
+		}
+	}
+	setCmd(&r.TestCmd, o.TestCmd, "test_cmd")
+	setCmd(&r.LintCmd, o.LintCmd, "lint_cmd")
+	setCmd(&r.BuildCmd, o.BuildCmd, "build_cmd")
+	setCmd(&r.AllowedCommands, o.AllowedCommands, "allowed_commands")
+	if o.Health != nil {
+		if v := strings.TrimSpace(o.Health.StaleAfter); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("health.stale_after: %w", err)
+			}
+			r.StaleAfter = d
+			r.Source["health.stale_after"] = layer
+		}
+		if v := strings.TrimSpace(o.Health.IdleAfter); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("health.idle_after: %w", err)
+			}
+			r.IdleAfter = d
+			r.Source["health.idle_after"] = layer
+		}
+		if n := o.Health.DenialsPerTurn; n != 0 {
+			if n < 0 {
+				return fmt.Errorf("health.denials_per_turn must be positive")
+			}
+			r.DenialsPerTurn = n
+			r.Source["health.denials_per_turn"] = layer
+		}
+	}
+	return nil
+}
+
+// Runtime returns the command and arguments for role, before provider
+// specific flags are added. A resolved model is passed as --model unless
+// the arguments already choose one.
+func (r Resolved) Runtime(role string) (string, []string) {
+	cmd := r.Rig.RuntimeCmd
+	args := r.Rig.RuntimeArgs
+	if spec, ok := r.Rig.RuntimeRoles[role]; ok {
+		if strings.TrimSpace(spec.Cmd) != "" {
+			cmd = spec.Cmd
+		}
+		if len(spec.Args) > 0 {
+			args = spec.Args
+		}
+	}
+	args = append([]string{}, args...)
+	if r.Model != "" && !containsArg(args, "--model") {
+		args = append(args, "--model", r.Model)
+	}
+	return cmd, args
+}
+
+// AllowsCommand reports whether program is on the resolved allowlist. An
+// entry of "*" allows every command.
+func (r Resolved) AllowsCommand(program string) bool {
+	for _, c := range r.AllowedCommands {
+		if c == "*" || c == program {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvList returns the resolved environment as sorted KEY=VALUE pairs.
+func (r Resolved) EnvList() []string {
+	keys := make([]string, 0, len(r.Env))
+	for k := range r.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, k+"="+r.Env[k])
+	}
+	return out
+}
+
+func containsArg(args []string, needle string) bool {
+	for _, a := range args {
+		if a == needle || strings.HasPrefix(a, needle+"=") {
+			return true
+		}
+	}
+	return false
+}
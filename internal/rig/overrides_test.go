@@ -0,0 +1,75 @@
+package rig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveLayersCellOverRig(t *testing.T) {
+	rc := DefaultRigConfig("r", "/repo")
+	rc.RuntimeRoles["builder"] = RuntimeSpec{Cmd: "claude", Args: []string{"--rig"}}
+	rc.CellDefaults = &CellOverrides{
+		Model:   "rig-model",
+		Env:     map[string]string{"A": "rig", "B": "rig"},
+		TestCmd: []string{"make", "test"},
+		Health:  &HealthThresholds{StaleAfter: "30m"},
+	}
+	cc := CellConfig{Name: "payments", ScopePrefix: "services/payments", Overrides: &CellOverrides{
+		RuntimeRoles:    map[string]RuntimeSpec{"builder": {Args: []string{"--cell"}}},
+		Env:             map[string]string{"B": "cell"},
+		TestCmd:         []string{"go", "test", "./services/payments/..."},
+		AllowedCommands: []string{"go", "buf"},
+		Health:          &HealthThresholds{IdleAfter: "1m"},
+	}}
+	res, err := Resolve(rc, cc)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	cmd, args := res.Runtime("builder")
+	if cmd != "claude" || len(args) != 3 || args[0] != "--cell" || args[1] != "--model" || args[2] != "rig-model" {
+		t.Fatalf("unexpected builder runtime %s %v", cmd, args)
+	}
+	if _, args := res.Runtime("reviewer"); args[0] != "--dangerously-skip-permissions" {
+		t.Fatalf("reviewer should use rig args, got %v", args)
+	}
+	if res.Env["A"] != "rig" || res.Env["B"] != "cell" {
+		t.Fatalf("unexpected env %v", res.Env)
+	}
+	if res.TestCmd[0] != "go" || res.Source["test_cmd"] != "cell" {
+		t.Fatalf("cell test_cmd should win, got %v (%s)", res.TestCmd, res.Source["test_cmd"])
+	}
+	if !res.AllowsCommand("buf") || res.AllowsCommand("make") {
+		t.Fatalf("cell allowlist should replace the default, got %v", res.AllowedCommands)
+	}
+	if res.StaleAfter != 30*time.Minute || res.IdleAfter != time.Minute {
+		t.Fatalf("unexpected thresholds stale=%s idle=%s", res.StaleAfter, res.IdleAfter)
+	}
+	if rc.RuntimeRoles["builder"].Args[0] != "--rig" {
+		t.Fatalf("resolve must not mutate the rig config")
+	}
+
+	cc.Overrides.Health = &HealthThresholds{StaleAfter: "soon"}
+	if _, err := Resolve(rc, cc); err == nil {
+		t.Fatalf("expected invalid duration to fail")
+	}
+}
+
+func TestResolveRoleAllowlistIsRigLayer(t *testing.T) {
+	rc := DefaultRigConfig("r", "/repo")
+	rc.Roles = []RoleSpec{{Name: "auditor", Access: RoleReadOnly, Bash: BashAllowlist, AllowedCommands: []string{"rg"}}}
+	cc := CellConfig{Name: "api", ScopePrefix: "svc/api"}
+	res, err := ResolveRole(rc, cc, "auditor")
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !res.AllowsCommand("rg") || res.AllowsCommand("go") || res.Source["allowed_commands"] != "rig" {
+		t.Fatalf("role allowlist should apply as the rig layer, got %v (%s)", res.AllowedCommands, res.Source["allowed_commands"])
+	}
+	cc.Overrides = &CellOverrides{AllowedCommands: []string{"go"}}
+	if res, err = ResolveRole(rc, cc, "auditor"); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !res.AllowsCommand("go") || res.AllowsCommand("rg") || res.Source["allowed_commands"] != "cell" {
+		t.Fatalf("cell allowlist should win over the role's, got %v (%s)", res.AllowedCommands, res.Source["allowed_commands"])
+	}
+}
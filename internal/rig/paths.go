@@ -34,7 +34,32 @@ func CellConfigPath(home, rig, cell string) string {
 	return filepath.Join(CellDir(home, rig, cell), "cell.json")
 }
 func TurnStatePath(home, rig string) string  { return filepath.Join(RigDir(home, rig), "turn.json") }
+func BeadsStorePath(home, rig string) string { return filepath.Join(RigDir(home, rig), "beads.jsonl") }
+func BeadsHistoryPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), "bead-history.jsonl")
+}
+func BeadsFailuresPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), "bead-failures.jsonl")
+}
+func BeadsCapabilitiesPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), "bd-capabilities.json")
+}
+func TelemetryJournalPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), "telemetry.jsonl")
+}
+func GuardrailsAuditPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), "guardrails.jsonl")
+}
+func ClaimLockPath(home, rig, id string) string {
+	return filepath.Join(RigDir(home, rig), "claims", id+".lock")
+}
 func TurnHistoryDir(home, rig string) string { return filepath.Join(RigDir(home, rig), "turns") }
 func TurnHistoryPath(home, rig, id string) string {
 	return filepath.Join(TurnHistoryDir(home, rig), "turn-"+id+".json")
 }
+func RigPolicyPath(home, rig string) string {
+	return filepath.Join(RigDir(home, rig), ".mf", "policy.json")
+}
+func CellPolicyPath(home, rig, cell string) string {
+	return filepath.Join(CellMetaDir(home, rig, cell), "policy.json")
+}
@@ -13,6 +13,7 @@ import (
 // RigConfig represents the configuration for a Microforge rig, stored in rig.json.
 // It defines the monorepo path, tmux naming, runtime provider, and remote execution settings.
 type RigConfig struct {
+	SchemaVersion        int                    `json:"schema_version"`
 	Name                 string                 `json:"name"`
 	RepoPath             string                 `json:"repo_path"`
 	TmuxPrefix           string                 `json:"tmux_prefix"`
@@ -29,27 +30,92 @@ type RigConfig struct {
 	LibraryDocs          []string               `json:"library_docs"`
 	LibraryContext7URL   string                 `json:"library_context7_url"`
 	LibraryContext7Token string                 `json:"library_context7_token"`
+	BeadsBackend         string                 `json:"beads_backend,omitempty"`
+	BeadsPath            string                 `json:"beads_path,omitempty"`
+	Retention            *RetentionConfig       `json:"retention,omitempty"`
+	Schemas              map[string]BeadSchema  `json:"schemas,omitempty"`
+	CellDefaults         *CellOverrides         `json:"cell_defaults,omitempty"`
+	Roles                []RoleSpec             `json:"roles,omitempty"`
 	CreatedAt            string                 `json:"created_at"`
 }
 
+// BeadSchema overrides the built-in schema for one bead type. Required and
+// Allowed name front-matter keys ("priority" checks the bead priority);
+// Sections are headings the body must contain.
+type BeadSchema struct {
+	Required []string            `json:"required,omitempty"`
+	Allowed  map[string][]string `json:"allowed,omitempty"`
+	Sections []string            `json:"sections,omitempty"`
+}
+
+// RetentionConfig controls `mforge bead gc` and where high-frequency
+// telemetry is recorded. Durations use Go syntax with a "d" suffix for days.
+type RetentionConfig struct {
+	Policies []RetentionPolicy `json:"policies,omitempty"`
+	// Journal routes the listed event kinds to telemetry.jsonl in the rig
+	// directory instead of creating beads for them.
+	Journal      bool     `json:"journal,omitempty"`
+	JournalKinds []string `json:"journal_kinds,omitempty"`
+	// JournalKeep is how long telemetry.jsonl entries survive `mforge bead
+	// gc`; empty keeps seven days.
+	JournalKeep string `json:"journal_keep,omitempty"`
+}
+
+// RetentionPolicy is the rig.json form of a per-kind event retention rule.
+type RetentionPolicy struct {
+	Kind        string `json:"kind"`
+	KeepLast    int    `json:"keep_last,omitempty"`
+	CloseAfter  string `json:"close_after,omitempty"`
+	DeleteAfter string `json:"delete_after,omitempty"`
+	Rollup      bool   `json:"rollup,omitempty"`
+}
+
+// JournalsKind reports whether events of kind go to the telemetry journal.
+func (c *RetentionConfig) JournalsKind(kind string) bool {
+	if c == nil || !c.Journal {
+		return false
+	}
+	kinds := c.JournalKinds
+	if len(kinds) == 0 {
+		kinds = []string{"agent_status", "hook_idle"}
+	}
+	for _, k := range kinds {
+		if k == kind || k == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // RuntimeSpec defines the command and arguments for a specific role's runtime.
 type RuntimeSpec struct {
-	Cmd  string   `json:"cmd"`
-	Args []string `json:"args"`
+	Cmd  string   `json:"cmd" yaml:"cmd,omitempty"`
+	Args []string `json:"args" yaml:"args,omitempty"`
 }
 
 // CellConfig represents the configuration for a cell within a rig, stored in cell.json.
 // It defines the cell name, scope prefix for path restrictions, and worktree location.
+// Include and Exclude add glob patterns to the scope prefix (see Scope), and
+// Overrides replace rig-wide settings for this cell (see Resolve).
+// Protected and ReadDeny are guard patterns (see MatchGuardPath) for paths
+// agents may never write and may not read with Read/Grep/Glob.
 type CellConfig struct {
-	Name         string `json:"name"`
-	ScopePrefix  string `json:"scope_prefix"`
-	WorktreePath string `json:"worktree_path"`
-	CreatedAt    string `json:"created_at"`
+	SchemaVersion int            `json:"schema_version"`
+	Name          string         `json:"name"`
+	ScopePrefix   string         `json:"scope_prefix"`
+	Include       []string       `json:"include,omitempty"`
+	Exclude       []string       `json:"exclude,omitempty"`
+	Overrides     *CellOverrides `json:"overrides,omitempty"`
+	Protected     []string       `json:"protected,omitempty"`
+	ReadDeny      []string       `json:"read_deny,omitempty"`
+	WorktreePath  string         `json:"worktree_path"`
+	CreatedAt     string         `json:"created_at"`
 }
 
 // DefaultRigConfig returns a RigConfig with sensible defaults for local Claude execution.
 func DefaultRigConfig(name, repo string) RigConfig {
 	return RigConfig{
+		SchemaVersion:   RigSchemaVersion,
 		Name:            name,
 		RepoPath:        repo,
 		TmuxPrefix:      "mforge",
@@ -65,6 +131,7 @@ func DefaultRigConfig(name, repo string) RigConfig {
 
 // SaveRigConfig writes the rig configuration to the specified path as JSON.
 func SaveRigConfig(path string, cfg RigConfig) error {
+	cfg.SchemaVersion = RigSchemaVersion
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling rig config: %w", err)
@@ -76,11 +143,12 @@ func SaveRigConfig(path string, cfg RigConfig) error {
 }
 
 // LoadRigConfig reads and parses the rig configuration from the specified path.
-// It applies defaults for missing optional fields.
+// Older schema versions are migrated in place (see Migrations); a newer one
+// is refused with a *SchemaTooNewError.
 func LoadRigConfig(path string) (RigConfig, error) {
-	b, err := os.ReadFile(path)
+	b, err := MigrateFile(SchemaRig, path)
 	if err != nil {
-		return RigConfig{}, fmt.Errorf("reading rig config %s: %w", path, err)
+		return RigConfig{}, err
 	}
 	var cfg RigConfig
 	if err := json.Unmarshal(b, &cfg); err != nil {
@@ -89,32 +157,20 @@ func LoadRigConfig(path string) (RigConfig, error) {
 	if cfg.Name == "" {
 		return RigConfig{}, fmt.Errorf("invalid rig.json: missing name")
 	}
-	if cfg.TmuxPrefix == "" {
-		cfg.TmuxPrefix = "mforge"
-	}
-	if cfg.RuntimeProvider == "" {
-		cfg.RuntimeProvider = "claude"
-	}
-	if cfg.RuntimeCmd == "" {
-		cfg.RuntimeCmd = "claude"
-	}
-	if len(cfg.RuntimeArgs) == 0 {
-		cfg.RuntimeArgs = []string{"--dangerously-skip-permissions"}
-	}
 	if cfg.RuntimeRoles == nil {
 		cfg.RuntimeRoles = map[string]RuntimeSpec{}
 	}
-	if cfg.RemotePort == 0 {
-		cfg.RemotePort = 22
-	}
-	if cfg.LibraryAddr == "" {
-		cfg.LibraryAddr = "127.0.0.1:7331"
+	switch cfg.BeadsBackend {
+	case "", "bd", "jsonl":
+	default:
+		return RigConfig{}, fmt.Errorf("invalid rig.json: unknown beads_backend %q (want bd or jsonl)", cfg.BeadsBackend)
 	}
 	return cfg, nil
 }
 
 // SaveCellConfig writes the cell configuration to the specified path as JSON.
 func SaveCellConfig(path string, cfg CellConfig) error {
+	cfg.SchemaVersion = CellSchemaVersion
 	b, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshaling cell config: %w", err)
@@ -125,18 +181,18 @@ func SaveCellConfig(path string, cfg CellConfig) error {
 	return nil
 }
 
-// LoadCellConfig reads and parses the cell configuration from the specified path.
-// Returns an error if name or scope_prefix is missing.
+// LoadCellConfig reads and parses the cell configuration from the specified path,
+// migrating older schema versions in place.
 func LoadCellConfig(path string) (CellConfig, error) {
-	b, err := os.ReadFile(path)
+	b, err := MigrateFile(SchemaCell, path)
 	if err != nil {
-		return CellConfig{}, fmt.Errorf("reading cell config %s: %w", path, err)
+		return CellConfig{}, err
 	}
 	var cfg CellConfig
 	if err := json.Unmarshal(b, &cfg); err != nil {
 		return CellConfig{}, fmt.Errorf("parsing cell config %s: %w", path, err)
 	}
-	if cfg.Name == "" || cfg.ScopePrefix == "" {
+	if cfg.Name == "" || (cfg.ScopePrefix == "" && len(cfg.Include) == 0) {
 		return CellConfig{}, fmt.Errorf("invalid cell.json: missing name or scope")
 	}
 	return cfg, nil
@@ -0,0 +1,198 @@
+package rig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role access levels.
+const (
+	RoleWrite    = "write"
+	RoleReadOnly = "readonly"
+)
+
+// Role Bash policies: allowlist runs only the resolved allowed_commands,
+// any runs everything, none denies Bash.
+const (
+	BashAllowlist = "allowlist"
+	BashAny       = "any"
+	BashNone      = "none"
+)
+
+// RoleSpec declares a role's capabilities. The built-in roles are always
+// present; entries in rig.json "roles" add new roles or replace a built-in
+// one by name.
+type RoleSpec struct {
+	Name            string       `json:"name" yaml:"name"`
+	Access          string       `json:"access,omitempty" yaml:"access,omitempty"`
+	Bash            string       `json:"bash,omitempty" yaml:"bash,omitempty"`
+	AllowedCommands []string     `json:"allowed_commands,omitempty" yaml:"allowed_commands,omitempty"`
+	Guide           string       `json:"guide,omitempty" yaml:"guide,omitempty"`
+	Runtime         *RuntimeSpec `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	TaskKinds       []string     `json:"task_kinds,omitempty" yaml:"task_kinds,omitempty"`
+
+	// guideText is the built-in guide used when Guide is empty.
+	guideText string
+}
+
+// BuiltinRoles are the roles `cell bootstrap` creates without any rig
+// configuration.
+var BuiltinRoles = []string{"builder", "monitor", "reviewer", "architect", "cell"}
+
+func builtinRoleSpecs() []RoleSpec {
+	return []RoleSpec{
+		{Name: "builder", Access: RoleWrite, Bash: BashAllowlist, TaskKinds: []string{"improve", "fix", "task"},
+			guideText: "Builder role\n\n- Implement assigned tasks strictly in-scope.\n- Update tests for changes.\n- If out-of-scope work is needed, emit a request bead and stop.\n- When complete, include the promise token in the outbox message."},
+		{Name: "monitor", Access: RoleReadOnly, Bash: BashNone,
+			guideText: "Monitor role\n\n- Run scoped tests and checks.\n- Only emit events/requests; do not modify code.\n- If flakiness or regression is detected, emit MonitorRequest.\n- Report signals with scope and impact."},
+		{Name: "reviewer", Access: RoleReadOnly, Bash: BashNone,
+			guideText: "Reviewer role\n\n- Validate acceptance criteria and scope boundaries.\n- Reject shortcuts (no silent contract changes, no skipped tests).\n- Emit ReviewRequest for missing tests or defects.\n- Approve only when changes are correct and safe."},
+		{Name: "architect", Access: RoleReadOnly, Bash: BashNone, TaskKinds: []string{"doc", "docs", "plan", "design"},
+			guideText: "Architect role\n\n- Update docs and cross-service contracts.\n- Enforce compatibility plans for shared interfaces.\n- Emit DocUpdateNeeded when documentation or design is missing."},
+		{Name: "cell", Access: RoleWrite, Bash: BashAny,
+			guideText: "Cell role (merged triad)\n\nModes to time-slice within a single agent:\n- Build: implement tasks and update tests.\n- Review: self-review with reviewer-level strictness.\n- Monitor: run checks and emit MonitorRequest as needed.\n\nRules:\n- No out-of-scope edits.\n- Emit events instead of direct cross-cell changes.\n- Keep notes in the outbox with the promise token when done."},
+	}
+}
+
+// RoleSpecs returns the role registry: rig-defined roles first, in rig.json
+// order, then the built-in roles they do not replace. Task routing walks it
+// in this order, so a custom role claiming a kind wins over a built-in one.
+func (c RigConfig) RoleSpecs() []RoleSpec {
+	out := make([]RoleSpec, 0, len(c.Roles)+len(BuiltinRoles))
+	custom := map[string]bool{}
+	builtins := builtinRoleSpecs()
+	for _, spec := range c.Roles {
+		name := strings.TrimSpace(spec.Name)
+		if name == "" || custom[name] {
+			continue
+		}
+		custom[name] = true
+		for _, b := range builtins {
+			if b.Name == name {
+				spec = mergeRoleSpec(b, spec)
+			}
+		}
+		out = append(out, spec.withDefaults())
+	}
+	for _, b := range builtins {
+		if !custom[b.Name] {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// RoleNames lists every registered role.
+func (c RigConfig) RoleNames() []string {
+	specs := c.RoleSpecs()
+	out := make([]string, 0, len(specs))
+	for _, s := range specs {
+		out = append(out, s.Name)
+	}
+	return out
+}
+
+// Role looks up a registered role by name.
+func (c RigConfig) Role(name string) (RoleSpec, bool) {
+	for _, s := range c.RoleSpecs() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return RoleSpec{}, false
+}
+
+// ValidateRoles checks the rig-defined roles.
+func ValidateRoles(specs []RoleSpec) error {
+	seen := map[string]bool{}
+	for i, s := range specs {
+		name := strings.TrimSpace(s.Name)
+		if name == "" {
+			return fmt.Errorf("roles[%d]: missing name", i)
+		}
+		if strings.ContainsAny(name, " /\\:") {
+			return fmt.Errorf("role %q: name may not contain spaces, slashes, or colons", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("role %q is defined twice", name)
+		}
+		seen[name] = true
+		switch s.Access {
+		case "", RoleWrite, RoleReadOnly:
+		default:
+			return fmt.Errorf("role %s: access must be %s or %s", name, RoleWrite, RoleReadOnly)
+		}
+		switch s.Bash {
+		case "", BashAllowlist, BashAny, BashNone:
+		default:
+			return fmt.Errorf("role %s: bash must be %s, %s, or %s", name, BashAllowlist, BashAny, BashNone)
+		}
+	}
+	return nil
+}
+
+// IsBuiltin reports whether the role is one `cell bootstrap` always knows.
+func (r RoleSpec) IsBuiltin() bool { return isBuiltinRole(r.Name) }
+
+// ReadOnly reports whether the role may not write files.
+func (r RoleSpec) ReadOnly() bool { return r.Access == RoleReadOnly }
+
+// HandlesKind reports whether tasks of kind route to this role by default.
+func (r RoleSpec) HandlesKind(kind string) bool {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	for _, k := range r.TaskKinds {
+		if strings.ToLower(k) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// GuideText returns the built-in guide for the role, if it has one.
+func (r RoleSpec) GuideText() string { return r.guideText }
+
+// withDefaults fills access and bash for a custom role: write roles use the
+// allowlist, read-only roles get no Bash.
+func (r RoleSpec) withDefaults() RoleSpec {
+	if r.Access == "" {
+		r.Access = RoleWrite
+	}
+	if r.Bash == "" {
+		r.Bash = BashAllowlist
+		if r.Access == RoleReadOnly {
+			r.Bash = BashNone
+		}
+	}
+	return r
+}
+
+func mergeRoleSpec(base, o RoleSpec) RoleSpec {
+	if o.Access != "" {
+		base.Access = o.Access
+	}
+	if o.Bash != "" {
+		base.Bash = o.Bash
+	}
+	if len(o.AllowedCommands) > 0 {
+		base.AllowedCommands = o.AllowedCommands
+	}
+	if o.Guide != "" {
+		base.Guide = o.Guide
+	}
+	if o.Runtime != nil {
+		base.Runtime = o.Runtime
+	}
+	if o.TaskKinds != nil {
+		base.TaskKinds = o.TaskKinds
+	}
+	return base
+}
+
+func isBuiltinRole(role string) bool {
+	for _, r := range BuiltinRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package rig
+
+import "testing"
+
+func TestRoleRegistryCustomAndOverride(t *testing.T) {
+	cfg := DefaultRigConfig("r", "/repo")
+	cfg.Roles = []RoleSpec{
+		{Name: "security-auditor", Access: RoleReadOnly, Bash: BashAllowlist, AllowedCommands: []string{"rg"}, TaskKinds: []string{"security", "review"}},
+		{Name: "doc-writer", TaskKinds: []string{"doc"}, Runtime: &RuntimeSpec{Cmd: "writer"}},
+		{Name: "reviewer", Bash: BashAllowlist},
+	}
+	names := cfg.RoleNames()
+	want := []string{"security-auditor", "doc-writer", "reviewer", "builder", "monitor", "architect", "cell"}
+	if len(names) != len(want) {
+		t.Fatalf("unexpected roles %v", names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("unexpected roles %v", names)
+		}
+	}
+	doc, _ := cfg.Role("doc-writer")
+	if doc.ReadOnly() || doc.Bash != BashAllowlist || !doc.HandlesKind("DOC") {
+		t.Fatalf("custom role defaults not applied: %+v", doc)
+	}
+	rev, _ := cfg.Role("reviewer")
+	if !rev.ReadOnly() || rev.Bash != BashAllowlist || rev.GuideText() == "" {
+		t.Fatalf("override should keep built-in fields: %+v", rev)
+	}
+	res, err := Resolve(cfg, CellConfig{Name: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd, _ := res.Runtime("doc-writer"); cmd != "writer" {
+		t.Fatalf("role runtime not used, got %s", cmd)
+	}
+}
+
+func TestValidateRoles(t *testing.T) {
+	bad := [][]RoleSpec{
+		{{Name: ""}},
+		{{Name: "a b"}},
+		{{Name: "x"}, {Name: "x"}},
+		{{Name: "x", Access: "admin"}},
+		{{Name: "x", Bash: "some"}},
+	}
+	for _, roles := range bad {
+		if err := ValidateRoles(roles); err == nil {
+			t.Fatalf("expected %+v to be rejected", roles)
+		}
+	}
+	if err := ValidateRoles([]RoleSpec{{Name: "perf-tuner", Access: RoleWrite, Bash: BashAny}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
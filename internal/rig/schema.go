@@ -0,0 +1,252 @@
+package rig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// Config kinds with a schema_version.
+const (
+	SchemaRig   = "rig.json"
+	SchemaCell  = "cell.json"
+	SchemaAgent = "active-agent.json"
+)
+
+// Current schema versions written by this binary.
+const (
+	RigSchemaVersion   = 2
+	CellSchemaVersion  = 1
+	AgentSchemaVersion = 1
+)
+
+// Migration upgrades a decoded config document from version From to From+1.
+type Migration struct {
+	From  int
+	Name  string
+	Apply func(doc map[string]any) error
+}
+
+// Migrations is the ordered registry applied on load, per config kind.
+// Append new steps at the end and bump the matching version constant.
+var Migrations = map[string][]Migration{
+	SchemaRig: {
+		{From: 0, Name: "fill rig defaults", Apply: migrateRigDefaults},
+		{From: 1, Name: "normalize runtime args", Apply: migrateRigRuntimeArgs},
+	},
+	SchemaCell: {
+		{From: 0, Name: "clean scope prefix", Apply: migrateCellScope},
+	},
+	SchemaAgent: {
+		{From: 0, Name: "fill mail paths", Apply: migrateAgentMail},
+	},
+}
+
+// SchemaTooNewError reports a config written by a newer mforge.
+type SchemaTooNewError struct {
+	Kind    string
+	Path    string
+	Version int
+	Max     int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("%s has schema_version %d but this mforge only understands up to %d; upgrade mforge to use it", e.Path, e.Version, e.Max)
+}
+
+// CurrentSchemaVersion returns the version this binary writes for kind.
+func CurrentSchemaVersion(kind string) int {
+	switch kind {
+	case SchemaRig:
+		return RigSchemaVersion
+	case SchemaCell:
+		return CellSchemaVersion
+	case SchemaAgent:
+		return AgentSchemaVersion
+	}
+	return 0
+}
+
+// MigrateBytes upgrades a config document to the current schema version. It
+// returns the (possibly unchanged) document, the version it started at, and
+// whether any migration ran.
+func MigrateBytes(kind, path string, b []byte) ([]byte, int, bool, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, 0, false, fmt.Errorf("parsing %s %s: %w", kind, path, err)
+	}
+	from := 0
+	if v, ok := doc["schema_version"].(float64); ok {
+		from = int(v)
+	}
+	max := CurrentSchemaVersion(kind)
+	if from > max {
+		return nil, from, false, &SchemaTooNewError{Kind: kind, Path: path, Version: from, Max: max}
+	}
+	if from == max {
+		return b, from, false, nil
+	}
+	for _, m := range Migrations[kind] {
+		if m.From < from {
+			continue
+		}
+		if err := m.Apply(doc); err != nil {
+			return nil, from, false, fmt.Errorf("migrating %s %s (%s): %w", kind, path, m.Name, err)
+		}
+	}
+	doc["schema_version"] = max
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, from, false, err
+	}
+	return out, from, true, nil
+}
+
+// MigrateFile upgrades the config at path in place, keeping the original as
+// <path>.v<N>.bak, and returns the current document.
+func MigrateFile(kind, path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s %s: %w", kind, path, err)
+	}
+	out, from, changed, err := MigrateBytes(kind, path, b)
+	if err != nil || !changed {
+		return out, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := util.AtomicWriteFile(backup, b, 0o644); err != nil {
+			return nil, fmt.Errorf("backing up %s: %w", path, err)
+		}
+	}
+	if err := util.AtomicWriteFile(path, out, 0o644); err != nil {
+		return nil, fmt.Errorf("writing migrated %s: %w", path, err)
+	}
+	return out, nil
+}
+
+func setDefault(doc map[string]any, key string, value any) {
+	switch v := doc[key].(type) {
+	case nil:
+		doc[key] = value
+	case string:
+		if v == "" {
+			doc[key] = value
+		}
+	case float64:
+		if v == 0 {
+			doc[key] = value
+		}
+	case []any:
+		if len(v) == 0 {
+			doc[key] = value
+		}
+	}
+}
+
+// migrateRigDefaults persists the defaults LoadRigConfig used to patch in
+// on every load.
+func migrateRigDefaults(doc map[string]any) error {
+	setDefault(doc, "tmux_prefix", "mforge")
+	setDefault(doc, "runtime_provider", "claude")
+	setDefault(doc, "runtime_cmd", "claude")
+	setDefault(doc, "runtime_args", []any{"--dangerously-skip-permissions"})
+	setDefault(doc, "runtime_roles", map[string]any{})
+	setDefault(doc, "remote_port", 22)
+	setDefault(doc, "library_addr", "127.0.0.1:7331")
+	return nil
+}
+
+// migrateRigRuntimeArgs applies NormalizeRuntimeArgs to the rig and per-role
+// runtime args, replacing the one-off fix in `mforge migrate rig`.
+func migrateRigRuntimeArgs(doc map[string]any) error {
+	provider, _ := doc["runtime_provider"].(string)
+	cmd, _ := doc["runtime_cmd"].(string)
+	if args, ok := stringList(doc["runtime_args"]); ok {
+		out, _ := NormalizeRuntimeArgs(provider, cmd, args)
+		doc["runtime_args"] = out
+	}
+	roles, _ := doc["runtime_roles"].(map[string]any)
+	for role, raw := range roles {
+		spec, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		roleCmd, _ := spec["cmd"].(string)
+		if strings.TrimSpace(roleCmd) == "" {
+			roleCmd = cmd
+		}
+		if args, ok := stringList(spec["args"]); ok && len(args) > 0 {
+			spec["args"], _ = NormalizeRuntimeArgs(provider, roleCmd, args)
+			roles[role] = spec
+		}
+	}
+	return nil
+}
+
+func migrateCellScope(doc map[string]any) error {
+	if scope, ok := doc["scope_prefix"].(string); ok {
+		scope = strings.TrimSpace(scope)
+		if scope != "/" {
+			scope = strings.TrimSuffix(scope, "/")
+		}
+		doc["scope_prefix"] = scope
+	}
+	return nil
+}
+
+func migrateAgentMail(doc map[string]any) error {
+	setDefault(doc, "inbox", "mail/inbox")
+	setDefault(doc, "outbox", "mail/outbox")
+	setDefault(doc, "archive", "mail/archive")
+	return nil
+}
+
+func stringList(v any) ([]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, s)
+	}
+	return out, true
+}
+
+// NormalizeRuntimeArgs drops session flags that must not be shared between
+// launches and makes sure Claude runs with --dangerously-skip-permissions.
+// It reports whether anything changed.
+func NormalizeRuntimeArgs(provider, cmd string, args []string) ([]string, bool) {
+	changed := false
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--resume" || args[i] == "--continue" || args[i] == "--fork-session" {
+			changed = true
+			continue
+		}
+		if args[i] == "--session-id" {
+			changed = true
+			if i+1 < len(args) {
+				i++
+			}
+			continue
+		}
+		out = append(out, args[i])
+	}
+	isClaude := strings.EqualFold(provider, "claude") || strings.Contains(strings.ToLower(cmd), "claude")
+	if isClaude {
+		if !containsArg(out, "--dangerously-skip-permissions") {
+			out = append(out, "--dangerously-skip-permissions")
+			changed = true
+		}
+	}
+	return out, changed
+}
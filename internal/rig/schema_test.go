@@ -0,0 +1,68 @@
+package rig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRigConfigMigratesOldSchema(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "rig.json")
+	old := `{"name":"r","repo_path":"/repo","runtime_args":["--resume","--session-id","abc"]}`
+	if err := os.WriteFile(p, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadRigConfig(p)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.SchemaVersion != RigSchemaVersion {
+		t.Fatalf("expected schema %d, got %d", RigSchemaVersion, cfg.SchemaVersion)
+	}
+	if cfg.TmuxPrefix != "mforge" || cfg.RuntimeCmd != "claude" {
+		t.Fatalf("defaults not filled: %+v", cfg)
+	}
+	if len(cfg.RuntimeArgs) != 1 || cfg.RuntimeArgs[0] != "--dangerously-skip-permissions" {
+		t.Fatalf("session args not normalized: %v", cfg.RuntimeArgs)
+	}
+	backup, err := os.ReadFile(p + ".v0.bak")
+	if err != nil || string(backup) != old {
+		t.Fatalf("expected original in backup, got %q (%v)", backup, err)
+	}
+	migrated, _ := os.ReadFile(p)
+	if _, err := LoadRigConfig(p); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	again, _ := os.ReadFile(p)
+	if string(again) != string(migrated) {
+		t.Fatalf("second load rewrote the file")
+	}
+}
+
+func TestLoadCellConfigRefusesNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "cell.json")
+	if err := os.WriteFile(p, []byte(`{"schema_version":99,"name":"c","scope_prefix":"a"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadCellConfig(p)
+	var tooNew *SchemaTooNewError
+	if !errors.As(err, &tooNew) || tooNew.Version != 99 || tooNew.Max != CellSchemaVersion {
+		t.Fatalf("expected SchemaTooNewError, got %v", err)
+	}
+	if _, err := os.Stat(p + ".v99.bak"); !os.IsNotExist(err) {
+		t.Fatalf("refused config should not be backed up")
+	}
+}
+
+func TestMigrateAgentFillsMailPaths(t *testing.T) {
+	out, from, changed, err := MigrateBytes(SchemaAgent, "active-agent.json", []byte(`{"rig_name":"r","role":"builder"}`))
+	if err != nil || from != 0 || !changed {
+		t.Fatalf("unexpected result from=%d changed=%v err=%v", from, changed, err)
+	}
+	if _, _, changed, err := MigrateBytes(SchemaAgent, "active-agent.json", out); err != nil || changed {
+		t.Fatalf("current document should be left alone (changed=%v err=%v)", changed, err)
+	}
+}
@@ -0,0 +1,259 @@
+package rig
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scope is the set of repo paths a cell owns: paths matching any Include
+// pattern and no Exclude pattern. Patterns are slash-separated and relative
+// to the repo root; "*" and "?" match within one segment and "**" matches
+// any number of segments, including none. A pattern without wildcards is a
+// path prefix, so the legacy scope_prefix "services/payments" behaves like
+// "services/payments/**".
+type Scope struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Scope returns the cell's matcher: its scope_prefix plus any include
+// patterns, minus its exclude patterns.
+func (c CellConfig) Scope() Scope {
+	s := Scope{Exclude: cleanPatterns(c.Exclude)}
+	if p := strings.TrimSpace(c.ScopePrefix); p != "" {
+		s.Include = append(s.Include, p)
+	}
+	s.Include = append(s.Include, cleanPatterns(c.Include)...)
+	s.Include = cleanPatterns(s.Include)
+	return s
+}
+
+func cleanPatterns(in []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range in {
+		p = strings.TrimSpace(filepath.ToSlash(p))
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out
+}
+
+// String renders the scope as comma-separated patterns with excludes
+// prefixed by "!".
+func (s Scope) String() string {
+	parts := append([]string{}, s.Include...)
+	for _, p := range s.Exclude {
+		parts = append(parts, "!"+p)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Match reports whether the repo-relative path p is in scope. An empty
+// scope, or an include of "." or "**", matches everything not excluded.
+func (s Scope) Match(p string) bool {
+	return s.Specificity(p) >= 0
+}
+
+// Specificity returns the length of the literal base of the longest
+// include pattern matching p, or -1 when p is out of scope. Routing uses it
+// to prefer the cell whose patterns name p most precisely.
+func (s Scope) Specificity(p string) int {
+	p = normalizeScopePath(p)
+	for _, ex := range s.Exclude {
+		if matchPattern(ex, p) {
+			return -1
+		}
+	}
+	if len(s.Include) == 0 {
+		return 0
+	}
+	best := -1
+	for _, inc := range s.Include {
+		if matchPattern(inc, p) {
+			if n := len(patternBase(inc)); n > best {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+// MatchPath resolves fp against root (a worktree) and matches the result
+// relative to root. Absolute include patterns match the resolved path.
+func (s Scope) MatchPath(root, fp string) bool {
+	target := fp
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	targetAbs, err := filepath.Abs(target)
+	if err != nil {
+		return false
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(rootAbs, targetAbs)
+	if err != nil {
+		return false
+	}
+	if s.Match(rel) {
+		return true
+	}
+	abs := filepath.ToSlash(targetAbs)
+	for _, inc := range s.Include {
+		if filepath.IsAbs(inc) && matchPattern(inc, abs) {
+			return s.Match(abs)
+		}
+	}
+	return false
+}
+
+// MatchCell returns the cell whose scope matches p most specifically, or
+// nil. Ties go to the earlier cell.
+func MatchCell(cells []CellConfig, p string) *CellConfig {
+	if strings.TrimSpace(p) == "" {
+		return nil
+	}
+	var best *CellConfig
+	bestN := -1
+	for i := range cells {
+		if n := cells[i].Scope().Specificity(p); n > bestN {
+			best, bestN = &cells[i], n
+		}
+	}
+	return best
+}
+
+// Overlap is a path claimed by two cells.
+type Overlap struct {
+	A, B string
+	Path string
+}
+
+// Overlaps reports cell pairs whose scopes both match the literal base of
+// one of their include patterns. It is a heuristic: patterns that only
+// intersect below a wildcard segment are not detected.
+func Overlaps(cells []CellConfig) []Overlap {
+	var out []Overlap
+	for i := 0; i < len(cells); i++ {
+		for j := i + 1; j < len(cells); j++ {
+			a, b := cells[i].Scope(), cells[j].Scope()
+			seen := map[string]bool{}
+			var witnesses []string
+			for _, inc := range append(append([]string{}, a.Include...), b.Include...) {
+				base := patternBase(inc)
+				if seen[base] {
+					continue
+				}
+				seen[base] = true
+				if a.Match(base) && b.Match(base) {
+					witnesses = append(witnesses, base)
+				}
+			}
+			sort.Strings(witnesses)
+			for _, w := range witnesses {
+				if w == "" {
+					w = "."
+				}
+				out = append(out, Overlap{A: cells[i].Name, B: cells[j].Name, Path: w})
+			}
+		}
+	}
+	return out
+}
+
+func normalizeScopePath(p string) string {
+	p = strings.TrimSpace(filepath.ToSlash(p))
+	if p == "" || p == "." {
+		return ""
+	}
+	abs := strings.HasPrefix(p, "/")
+	p = path.Clean(p)
+	if !abs {
+		p = strings.TrimPrefix(p, "./")
+	}
+	return p
+}
+
+// patternBase is the literal directory prefix of a pattern, before its first
+// wildcard segment.
+func patternBase(pattern string) string {
+	pattern = normalizeScopePath(pattern)
+	var out []string
+	for _, seg := range strings.Split(pattern, "/") {
+		if strings.ContainsAny(seg, "*?") {
+			break
+		}
+		out = append(out, seg)
+	}
+	return strings.Join(out, "/")
+}
+
+var patternCache sync.Map // pattern -> *regexp.Regexp
+
+func matchPattern(pattern, p string) bool {
+	pattern = normalizeScopePath(pattern)
+	if pattern == "" || pattern == "**" {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?") {
+		return p == pattern || strings.HasPrefix(p, strings.TrimSuffix(pattern, "/")+"/")
+	}
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(p)
+	}
+	re := compilePattern(pattern)
+	patternCache.Store(pattern, re)
+	return re.MatchString(p)
+}
+
+// compilePattern translates a glob into an anchored regexp. Whatever the
+// last segment matches is owned together with everything beneath it.
+func compilePattern(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	segs := strings.Split(pattern, "/")
+	for i, seg := range segs {
+		last := i == len(segs)-1
+		if seg == "**" {
+			switch {
+			case last && i == 0:
+				b.WriteString(".*")
+			case last:
+				b.WriteString("(?:/.*)?")
+			case i == 0:
+				b.WriteString("(?:[^/]+/)*")
+			default:
+				b.WriteString("/(?:[^/]+/)*")
+			}
+			continue
+		}
+		if i > 0 && segs[i-1] != "**" {
+			b.WriteString("/")
+		}
+		for _, r := range seg {
+			switch r {
+			case '*':
+				b.WriteString("[^/]*")
+			case '?':
+				b.WriteString("[^/]")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		if last {
+			b.WriteString("(?:/.*)?")
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
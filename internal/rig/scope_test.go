@@ -0,0 +1,88 @@
+package rig
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScopeMatch(t *testing.T) {
+	cell := CellConfig{
+		Name:        "payments",
+		ScopePrefix: "services/payments",
+		Include:     []string{"proto/payments/**", "deploy/helm/payments/**", "docs/*.md"},
+		Exclude:     []string{"services/payments/vendor/**"},
+	}
+	s := cell.Scope()
+	cases := map[string]bool{
+		"services/payments":                 true,
+		"services/payments/api/handler.go":  true,
+		"services/payments/vendor/x/y.go":   false,
+		"services/paymentsx/main.go":        false,
+		"proto/payments":                    true,
+		"proto/payments/v1/payments.proto":  true,
+		"deploy/helm/payments/values.yaml":  true,
+		"deploy/helm/ledger/values.yaml":    false,
+		"docs/payments.md":                  true,
+		"docs/sub/payments.md":              false,
+		"./services/payments/../payments/a": true,
+		"services/ledger/main.go":           false,
+	}
+	for p, want := range cases {
+		if got := s.Match(p); got != want {
+			t.Errorf("Match(%q) = %v, want %v", p, got, want)
+		}
+	}
+	if got := (Scope{}).Match("anything/at/all"); !got {
+		t.Fatalf("empty scope should match everything")
+	}
+	if got := (Scope{Include: []string{"**/*.go"}}).Match("a/b/c.go"); !got {
+		t.Fatalf("**/*.go should match nested files")
+	}
+}
+
+func TestScopeMatchPath(t *testing.T) {
+	root := t.TempDir()
+	s := Scope{Include: []string{"services/payments"}, Exclude: []string{"services/payments/vendor/**"}}
+	if !s.MatchPath(root, "services/payments/main.go") {
+		t.Fatalf("relative path in scope should match")
+	}
+	if !s.MatchPath(root, filepath.Join(root, "services", "payments", "main.go")) {
+		t.Fatalf("absolute path in scope should match")
+	}
+	if s.MatchPath(root, "services/payments/vendor/lib.go") {
+		t.Fatalf("excluded path should not match")
+	}
+	if s.MatchPath(root, "../outside/main.go") {
+		t.Fatalf("path outside the worktree should not match")
+	}
+}
+
+func TestMatchCellAndOverlaps(t *testing.T) {
+	cells := []CellConfig{
+		{Name: "services", ScopePrefix: "services"},
+		{Name: "payments", ScopePrefix: "services/payments", Include: []string{"proto/**"}},
+		{Name: "proto", ScopePrefix: "proto/ledger"},
+	}
+	if got := MatchCell(cells, "services/payments/api"); got == nil || got.Name != "payments" {
+		t.Fatalf("expected payments to win, got %#v", got)
+	}
+	if got := MatchCell(cells, "services/ledger"); got == nil || got.Name != "services" {
+		t.Fatalf("expected services, got %#v", got)
+	}
+	if got := MatchCell(cells, "proto/ledger/v1"); got == nil || got.Name != "proto" {
+		t.Fatalf("expected proto to win on specificity, got %#v", got)
+	}
+	if got := MatchCell(cells, "web/app"); got != nil {
+		t.Fatalf("expected no match, got %#v", got)
+	}
+	overlaps := Overlaps(cells)
+	want := map[string]bool{"services|payments|services/payments": true, "payments|proto|proto/ledger": true}
+	if len(overlaps) != len(want) {
+		t.Fatalf("unexpected overlaps: %#v", overlaps)
+	}
+	for _, o := range overlaps {
+		if !want[o.A+"|"+o.B+"|"+o.Path] {
+			t.Fatalf("unexpected overlap %#v", o)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package rig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/example/microforge/internal/util"
+)
+
+// SecretsPassphraseEnv unlocks the local secrets file for one shell session.
+const SecretsPassphraseEnv = "MF_SECRETS_PASSPHRASE"
+
+// Secret reference prefixes accepted wherever rig.json or cell.json holds a
+// credential: env:NAME reads the caller's environment, file:PATH reads a
+// file (relative to the microforge home), and secret:NAME reads the local
+// encrypted secrets file.
+const (
+	SecretRefEnv   = "env:"
+	SecretRefFile  = "file:"
+	SecretRefStore = "secret:"
+)
+
+const secretsKDFIterations = 210000
+
+// ErrSecretsLocked is returned when the secrets file is needed but
+// MF_SECRETS_PASSPHRASE is not set.
+var ErrSecretsLocked = errors.New("secrets file is locked; export " + SecretsPassphraseEnv + " for this session")
+
+// SecretsPath is the encrypted secrets file. It lives outside rigs/ so
+// `rig backup` never archives it.
+func SecretsPath(home string) string { return filepath.Join(home, "secrets.enc.json") }
+
+// IsSecretRef reports whether v is a secret reference rather than a literal.
+func IsSecretRef(v string) bool {
+	v = strings.TrimSpace(v)
+	return strings.HasPrefix(v, SecretRefEnv) || strings.HasPrefix(v, SecretRefFile) || strings.HasPrefix(v, SecretRefStore)
+}
+
+// ResolveSecret returns the value a reference points to. Values that are not
+// references are returned unchanged so existing plaintext configs keep
+// working; `mforge doctor` warns about them.
+func ResolveSecret(home, v string) (string, error) {
+	ref := strings.TrimSpace(v)
+	switch {
+	case strings.HasPrefix(ref, SecretRefEnv):
+		name := strings.TrimPrefix(ref, SecretRefEnv)
+		val, ok := os.LookupEnv(name)
+		if !ok || val == "" {
+			return "", fmt.Errorf("secret %s: environment variable %s is not set", ref, name)
+		}
+		return val, nil
+	case strings.HasPrefix(ref, SecretRefFile):
+		p := expandSecretPath(home, strings.TrimPrefix(ref, SecretRefFile))
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", ref, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case strings.HasPrefix(ref, SecretRefStore):
+		name := strings.TrimPrefix(ref, SecretRefStore)
+		store, err := OpenSecretStore(home)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", ref, err)
+		}
+		val, ok := store.Get(name)
+		if !ok {
+			return "", fmt.Errorf("secret %s: not in %s", ref, SecretsPath(home))
+		}
+		return val, nil
+	}
+	return v, nil
+}
+
+func expandSecretPath(home, p string) string {
+	p = strings.TrimSpace(p)
+	if strings.HasPrefix(p, "~/") {
+		if dir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(dir, p[2:])
+		}
+	}
+	if !filepath.IsAbs(p) {
+		return filepath.Join(home, p)
+	}
+	return p
+}
+
+// SecretStore is the decrypted view of the local secrets file.
+type SecretStore struct {
+	path   string
+	pass   string
+	salt   []byte
+	values map[string]string
+}
+
+type secretsFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// OpenSecretStore unlocks the secrets file with MF_SECRETS_PASSPHRASE. A
+// missing file opens as an empty store.
+func OpenSecretStore(home string) (*SecretStore, error) {
+	pass := os.Getenv(SecretsPassphraseEnv)
+	if pass == "" {
+		return nil, ErrSecretsLocked
+	}
+	s := &SecretStore{path: SecretsPath(home), pass: pass, values: map[string]string{}}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	var f secretsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	if f.Iterations <= 0 {
+		f.Iterations = secretsKDFIterations
+	}
+	gcm, err := secretsCipher(pass, f.Salt, f.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unlocking %s: wrong passphrase or corrupt file", s.path)
+	}
+	if err := json.Unmarshal(plain, &s.values); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	s.salt = f.Salt
+	return s, nil
+}
+
+// Get returns the named secret.
+func (s *SecretStore) Get(name string) (string, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Set stores a secret; call Save to persist it.
+func (s *SecretStore) Set(name, value string) { s.values[name] = value }
+
+// Delete removes a secret and reports whether it existed.
+func (s *SecretStore) Delete(name string) bool {
+	_, ok := s.values[name]
+	delete(s.values, name)
+	return ok
+}
+
+// Names lists the stored secret names, sorted.
+func (s *SecretStore) Names() []string {
+	out := make([]string, 0, len(s.values))
+	for k := range s.values {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Save encrypts the store with a fresh nonce and writes it with 0600
+// permissions.
+func (s *SecretStore) Save() error {
+	if len(s.salt) == 0 {
+		s.salt = make([]byte, 16)
+		if _, err := rand.Read(s.salt); err != nil {
+			return err
+		}
+	}
+	gcm, err := secretsCipher(s.pass, s.salt, secretsKDFIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	plain, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(secretsFile{
+		Version:    1,
+		Iterations: secretsKDFIterations,
+		Salt:       s.salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := util.EnsureDir(filepath.Dir(s.path)); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(s.path, append(b, '\n'), 0o600)
+}
+
+func secretsCipher(pass string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secretsKey(pass, salt, iterations))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretsKey derives the AES-256 key with PBKDF2-HMAC-SHA256 (RFC 8018).
+func secretsKey(pass string, salt []byte, iterations int) []byte {
+	return pbkdf2.Key([]byte(pass), salt, iterations, 32, sha256.New)
+}
+
+// SecretEnv returns the resolved environment with every secret reference
+// replaced by its value. Call it only when launching the agent.
+func (r Resolved) SecretEnv(home string) (map[string]string, error) {
+	out := make(map[string]string, len(r.Env))
+	for k, v := range r.Env {
+		val, err := ResolveSecret(home, v)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		out[k] = val
+	}
+	return out, nil
+}
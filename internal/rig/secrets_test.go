@@ -0,0 +1,72 @@
+package rig
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecretRefs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MF_TEST_TOKEN", "from-env")
+	if err := os.WriteFile(filepath.Join(home, "token"), []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"env:MF_TEST_TOKEN": "from-env",
+		"file:token":        "from-file",
+		"plain":             "plain",
+	}
+	for ref, want := range cases {
+		got, err := ResolveSecret(home, ref)
+		if err != nil || got != want {
+			t.Fatalf("%s: got %q (%v), want %q", ref, got, err, want)
+		}
+	}
+	if _, err := ResolveSecret(home, "env:MF_TEST_UNSET"); err == nil {
+		t.Fatalf("expected error for unset variable")
+	}
+}
+
+func TestSecretStoreRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(SecretsPassphraseEnv, "")
+	if _, err := ResolveSecret(home, "secret:ctx7"); !errors.Is(err, ErrSecretsLocked) {
+		t.Fatalf("expected locked error, got %v", err)
+	}
+	t.Setenv(SecretsPassphraseEnv, "correct horse")
+	store, err := OpenSecretStore(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("ctx7", "tok-123")
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(SecretsPath(home))
+	if strings.Contains(string(b), "tok-123") {
+		t.Fatalf("secrets file holds plaintext")
+	}
+	if info, _ := os.Stat(SecretsPath(home)); info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600, got %v", info.Mode().Perm())
+	}
+	got, err := ResolveSecret(home, "secret:ctx7")
+	if err != nil || got != "tok-123" {
+		t.Fatalf("got %q (%v)", got, err)
+	}
+	t.Setenv(SecretsPassphraseEnv, "wrong")
+	if _, err := OpenSecretStore(home); err == nil {
+		t.Fatalf("expected wrong passphrase to fail")
+	}
+}
+
+func TestSecretsKeyVector(t *testing.T) {
+	// RFC 7914 section 11, first 32 bytes.
+	got := hex.EncodeToString(secretsKey("passwd", []byte("salt"), 1))
+	if got != "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" {
+		t.Fatalf("unexpected key %s", got)
+	}
+}
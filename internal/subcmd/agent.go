@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -78,14 +80,11 @@ func Agent(home string, args []string) error {
 		}
 	}
 
-	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	resolved, err := resolveCell(home, rigName, cellName)
 	if err != nil {
-		return fmt.Errorf("loading rig %s: %w", rigName, err)
-	}
-	cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName))
-	if err != nil {
-		return fmt.Errorf("loading cell %s: %w", cellName, err)
+		return err
 	}
+	cfg, cellCfg := resolved.Rig, resolved.Cell
 	session := fmt.Sprintf("%s-%s-%s-%s", cfg.TmuxPrefix, rigName, cellName, role)
 	worktree := cellCfg.WorktreePath
 
@@ -107,22 +106,21 @@ func Agent(home string, args []string) error {
 			fmt.Printf("Session already running: %s\n", session)
 			return nil
 		}
-		cmd, cmdArgs := runtimeForRole(cfg, role)
+		cmd, cmdArgs := runtimeForCell(resolved, role)
 		cmdArgs = ensureSessionID(cfg, cmd, cmdArgs)
 		remoteWorktree := resolveRemoteWorkdir(cfg, worktree, cellName)
-		targs := []string{"new-session", "-d", "-s", session}
-		if !remote && strings.TrimSpace(cfg.RemoteHost) == "" {
-			targs = append(targs, awsEnvArgs()...)
+		launch, err := agentLaunchArgs(home, session, resolved, remote, remoteWorktree, cmd, cmdArgs)
+		if err != nil {
+			return err
 		}
-		targs = append(targs, "-c", remoteWorktree, "--", cmd)
-		targs = append(targs, cmdArgs...)
+		targs := append([]string{"new-session", "-d", "-s", session}, launch...)
 		if _, err := runTmux(cfg, remote, false, targs...); err != nil {
 			return err
 		}
 		_ = ensureAgentLogPipe(home, rigName, cellName, role, session, cfg, remote)
 		writeHeartbeat(home, rigName, cellName, role, "spawned", "", "")
 		maybeAcceptTrust(cfg, remote, session)
-		emitOrchestrationEvent(cfg.RepoPath, beads.Meta{
+		emitOrchestrationEvent(beadsClient(home, cfg), beads.Meta{
 			Cell:  cellName,
 			Role:  role,
 			Scope: cellCfg.ScopePrefix,
@@ -167,7 +165,7 @@ func Agent(home string, args []string) error {
 		if err := sendWakePrompt(cfg, remote, session, prompt); err != nil {
 			return err
 		}
-		emitOrchestrationEvent(cfg.RepoPath, beads.Meta{
+		emitOrchestrationEvent(beadsClient(home, cfg), beads.Meta{
 			Cell:  cellName,
 			Role:  role,
 			Scope: cellCfg.ScopePrefix,
@@ -194,15 +192,14 @@ func Agent(home string, args []string) error {
 				return err
 			}
 		}
-		cmd, cmdArgs := runtimeForRole(cfg, role)
+		cmd, cmdArgs := runtimeForCell(resolved, role)
 		cmdArgs = ensureSessionID(cfg, cmd, cmdArgs)
 		remoteWorktree := resolveRemoteWorkdir(cfg, worktree, cellName)
-		targs := []string{"new-session", "-d", "-s", session}
-		if !remote && strings.TrimSpace(cfg.RemoteHost) == "" {
-			targs = append(targs, awsEnvArgs()...)
+		launch, err := agentLaunchArgs(home, session, resolved, remote, remoteWorktree, cmd, cmdArgs)
+		if err != nil {
+			return err
 		}
-		targs = append(targs, "-c", remoteWorktree, "--", cmd)
-		targs = append(targs, cmdArgs...)
+		targs := append([]string{"new-session", "-d", "-s", session}, launch...)
 		if _, err := runTmux(cfg, remote, false, targs...); err != nil {
 			return err
 		}
@@ -214,7 +211,7 @@ func Agent(home string, args []string) error {
 			return err
 		}
 		writeHeartbeat(home, rigName, cellName, role, "woke", "", "")
-		emitOrchestrationEvent(cfg.RepoPath, beads.Meta{
+		emitOrchestrationEvent(beadsClient(home, cfg), beads.Meta{
 			Cell:  cellName,
 			Role:  role,
 			Scope: cellCfg.ScopePrefix,
@@ -285,31 +282,102 @@ func ensureCellBootstrapped(home, rigName, cellName, role string, auto bool) err
 		return fmt.Errorf("cell %q is not bootstrapped; run: mforge cell bootstrap %s %s", cellName, rigName, cellName)
 	}
 	args := []string{"bootstrap", rigName, cellName}
-	switch strings.ToLower(role) {
-	case "cell":
+	wt := cellCfg.WorktreePath
+	if strings.EqualFold(role, "cell") || roleExists(wt, "cell") {
 		args = append(args, "--single")
-	case "architect":
+	}
+	if strings.EqualFold(role, "architect") || roleExists(wt, "architect") {
 		args = append(args, "--architect")
 	}
+	if !hasArg(rig.BuiltinRoles, role) {
+		args = append(args, "--role", role)
+	}
 	return Cell(home, args)
 }
 
-func awsEnvArgs() []string {
-	keys := []string{
-		"AWS_PROFILE", "AWS_DEFAULT_PROFILE",
-		"AWS_REGION", "AWS_DEFAULT_REGION",
-		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
-		"AWS_SDK_LOAD_CONFIG",
-	}
-	out := []string{}
-	for _, key := range keys {
-		val := strings.TrimSpace(os.Getenv(key))
-		if val == "" {
+var awsEnvKeys = []string{
+	"AWS_PROFILE", "AWS_DEFAULT_PROFILE",
+	"AWS_REGION", "AWS_DEFAULT_REGION",
+	"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	"AWS_SDK_LOAD_CONFIG",
+}
+
+// agentEnvScript sources the env file named by $0, deletes it, and execs
+// the runtime.
+const agentEnvScript = `set -a; . "$0"; set +a; rm -f "$0"; exec "$@"`
+
+// agentLaunchArgs returns the tmux new-session arguments after the session
+// name. Locally, AWS credentials from the caller's environment and the
+// cell's env, with secret references resolved, are written to a private
+// env file that the session sources and removes, so no value appears on a
+// command line. Remote sessions get the cell's plain env values as -e flags
+// and skip secret references.
+func agentLaunchArgs(home, session string, res rig.Resolved, remote bool, workdir, cmd string, cmdArgs []string) ([]string, error) {
+	if remote || strings.TrimSpace(res.Rig.RemoteHost) != "" {
+		var out []string
+		for _, kv := range res.EnvList() {
+			key, val, _ := strings.Cut(kv, "=")
+			if rig.IsSecretRef(val) {
+				fmt.Fprintf(os.Stderr, "warning: not passing secret env %s to remote session %s\n", key, session)
+				continue
+			}
+			out = append(out, "-e", kv)
+		}
+		out = append(out, "-c", workdir, "--", cmd)
+		return append(out, cmdArgs...), nil
+	}
+	env, err := res.SecretEnv(home)
+	if err != nil {
+		return nil, fmt.Errorf("resolving env for %s: %w", session, err)
+	}
+	for _, key := range awsEnvKeys {
+		if _, set := env[key]; set {
 			continue
 		}
-		out = append(out, "-e", fmt.Sprintf("%s=%s", key, val))
+		if val := strings.TrimSpace(os.Getenv(key)); val != "" {
+			env[key] = val
+		}
 	}
-	return out
+	out := []string{"-c", workdir, "--"}
+	if len(env) == 0 {
+		return append(append(out, cmd), cmdArgs...), nil
+	}
+	envPath, err := writeAgentEnvFile(home, session, env)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, "sh", "-c", agentEnvScript, envPath, cmd)
+	return append(out, cmdArgs...), nil
+}
+
+// envKeyPattern matches names the env file can assign; anything else would
+// be run as shell code when the file is sourced.
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// writeAgentEnvFile writes env as shell assignments readable only by the
+// current user.
+func writeAgentEnvFile(home, session string, env map[string]string) (string, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		if !envKeyPattern.MatchString(k) {
+			return "", fmt.Errorf("invalid env name %q: must match %s", k, envKeyPattern)
+		}
+		keys = append(keys, k)
+	}
+	dir := filepath.Join(home, "run")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s='%s'\n", k, strings.ReplaceAll(env[k], "'", `'\''`))
+	}
+	p := filepath.Join(dir, session+".env")
+	if err := util.AtomicWriteFile(p, []byte(b.String()), 0o600); err != nil {
+		return "", fmt.Errorf("writing agent env file: %w", err)
+	}
+	return p, nil
 }
 
 func verifyWorktreeReady(worktree string) error {
@@ -412,7 +480,7 @@ func agentStatus(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	roles := cfg.RoleNames()
 	rows := make([]map[string]string, 0)
 	for _, c := range cells {
 		if cellName != "" && c.Name != cellName {
@@ -528,7 +596,7 @@ func agentCreate(home string, args []string) error {
 	if strings.TrimSpace(spec.Class) == "" {
 		spec.Class = "worker"
 	}
-	agentID, err := ensureAgentBead(cfg.RepoPath, spec)
+	agentID, err := ensureAgentBead(beadsClient(home, cfg), spec)
 	if err != nil {
 		return err
 	}
@@ -702,20 +770,29 @@ func agentHeartbeat(home string, args []string) error {
 }
 
 func runtimeForRole(cfg rig.RigConfig, role string) (string, []string) {
-	cmd := cfg.RuntimeCmd
-	args := cfg.RuntimeArgs
-	if spec, ok := cfg.RuntimeRoles[role]; ok {
-		if strings.TrimSpace(spec.Cmd) != "" {
-			cmd = spec.Cmd
-		}
-		if len(spec.Args) > 0 {
-			args = spec.Args
-		}
-	}
-	args = ensureDangerousSkip(cfg, cmd, args)
+	return runtimeForCell(rig.Resolved{Rig: cfg}, role)
+}
+
+// runtimeForCell is runtimeForRole with the cell's overrides applied.
+func runtimeForCell(res rig.Resolved, role string) (string, []string) {
+	cmd, args := res.Runtime(role)
+	args = ensureDangerousSkip(res.Rig, cmd, args)
 	return cmd, args
 }
 
+// resolveCell loads and merges the rig and cell configuration.
+func resolveCell(home, rigName, cellName string) (rig.Resolved, error) {
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return rig.Resolved{}, fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName))
+	if err != nil {
+		return rig.Resolved{}, fmt.Errorf("loading cell %s: %w", cellName, err)
+	}
+	return rig.Resolve(cfg, cellCfg)
+}
+
 func ensureSessionID(cfg rig.RigConfig, cmd string, args []string) []string {
 	if !strings.EqualFold(cfg.RuntimeProvider, "claude") && !strings.Contains(strings.ToLower(cmd), "claude") {
 		return args
@@ -931,7 +1008,8 @@ func listAgentLogTargets(home, rigName string) ([]logTarget, error) {
 	if err != nil {
 		return nil, err
 	}
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	cfg, _ := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	roles := cfg.RoleNames()
 	out := make([]logTarget, 0)
 	for _, cell := range cells {
 		for _, role := range roles {
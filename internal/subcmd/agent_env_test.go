@@ -0,0 +1,56 @@
+package subcmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+func TestAgentLaunchArgsKeepsSecretsOffArgv(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+	t.Setenv("MF_TEST_API_TOKEN", "api-secret")
+	res := rig.Resolved{
+		Rig: rig.DefaultRigConfig("r", "/repo"),
+		Env: map[string]string{"API_TOKEN": "env:MF_TEST_API_TOKEN", "MODE": "it's"},
+	}
+	args, err := agentLaunchArgs(home, "mforge-r-c-builder", res, false, "/wt", "claude", []string{"--x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(args, " ")
+	if strings.Contains(joined, "secret") {
+		t.Fatalf("secret on argv: %v", args)
+	}
+	if args[3] != "sh" || args[len(args)-2] != "claude" {
+		t.Fatalf("expected sh wrapper, got %v", args)
+	}
+	envPath := args[6]
+	info, err := os.Stat(envPath)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("env file missing or not private: %v %v", info, err)
+	}
+	b, _ := os.ReadFile(envPath)
+	for _, want := range []string{"API_TOKEN='api-secret'", "AWS_SECRET_ACCESS_KEY='aws-secret'", `MODE='it'\''s'`} {
+		if !strings.Contains(string(b), want) {
+			t.Fatalf("env file missing %s:\n%s", want, b)
+		}
+	}
+
+	bad := res
+	bad.Env = map[string]string{"X;touch /tmp/pwned;Y": "v"}
+	if _, err := agentLaunchArgs(home, "mforge-r-c-builder", bad, false, "/wt", "claude", nil); err == nil || !strings.Contains(err.Error(), "invalid env name") {
+		t.Fatalf("expected an invalid env name to be rejected, got %v", err)
+	}
+
+	res.Rig.RemoteHost = "box"
+	args, err = agentLaunchArgs(home, "mforge-r-c-builder", res, true, "/wt", "claude", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.Join(args, " "), "API_TOKEN") || args[1] != "MODE=it's" {
+		t.Fatalf("remote args should carry only plain env: %v", args)
+	}
+}
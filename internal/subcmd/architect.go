@@ -61,7 +61,7 @@ func architectRequest(home string, args []string, title string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	turnID := ""
 	if state, err := turn.Load(rig.TurnStatePath(home, rigName)); err == nil {
 		turnID = strings.TrimSpace(state.ID)
@@ -59,7 +59,7 @@ func Assign(home string, args []string) error {
 	if err := ensureCellBootstrapped(home, rigName, cellName, role, true); err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -78,11 +78,9 @@ func Assign(home string, args []string) error {
 		if meta.Role != "" && !strings.EqualFold(meta.Role, role) {
 			continue
 		}
-		for _, dep := range issue.Deps {
-			if dep == "related:"+taskID {
-				fmt.Printf("Assignment already exists for task %s\n", taskID)
-				return nil
-			}
+		if beads.HasDep(issue.Deps, beads.EdgeRelated, taskID) {
+			fmt.Printf("Assignment already exists for task %s\n", taskID)
+			return nil
 		}
 		if strings.Contains(issue.Title, taskID) {
 			fmt.Printf("Assignment already exists for task %s\n", taskID)
@@ -116,6 +114,9 @@ func Assign(home string, args []string) error {
 		}
 	}
 	desc := beads.RenderMeta(meta) + "\n\n" + body
+	if err := checkBeadSchema(cfg, beads.Issue{Type: "assignment", Priority: "p2", Description: desc}); err != nil {
+		return err
+	}
 	req := beads.CreateRequest{
 		Title:       "Assignment " + taskID,
 		Type:        "assignment",
@@ -129,10 +130,10 @@ func Assign(home string, args []string) error {
 		return err
 	}
 	if task, err := client.Show(nil, taskID); err == nil {
-		mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, promise, task)
+		mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, promise, task, issueThread(client, task.ID))
 		_ = createMailBead(client, meta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
 	}
-	emitOrchestrationEvent(cfg.RepoPath, beads.Meta{
+	emitOrchestrationEvent(client, beads.Meta{
 		Cell:      cellName,
 		Role:      role,
 		Scope:     cellCfg.ScopePrefix,
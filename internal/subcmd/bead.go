@@ -2,6 +2,7 @@ package subcmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/example/microforge/internal/beads"
@@ -11,7 +12,7 @@ import (
 
 func Bead(home string, args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: mforge bead <create|list|show|close|triage|dep|status> ...")
+		return fmt.Errorf("usage: mforge bead <create|list|show|close|triage|dep|status|comment|history|capabilities|watch|gc|lint|export|import> ...")
 	}
 	op := args[0]
 	rest := args[1:]
@@ -31,6 +32,22 @@ func Bead(home string, args []string) error {
 		return beadDep(home, rest)
 	case "status":
 		return beadStatus(home, rest)
+	case "comment":
+		return beadComment(home, rest)
+	case "history":
+		return beadHistory(home, rest)
+	case "capabilities":
+		return beadCapabilities(home, rest)
+	case "watch":
+		return beadWatch(home, rest)
+	case "gc":
+		return beadGC(home, rest)
+	case "lint":
+		return beadLint(home, rest)
+	case "export":
+		return beadExport(home, rest)
+	case "import":
+		return beadImport(home, rest)
 	default:
 		return fmt.Errorf("unknown bead subcommand: %s", op)
 	}
@@ -131,7 +148,7 @@ func beadCreate(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{
 		Cell:     cell,
 		Role:     role,
@@ -140,11 +157,14 @@ func beadCreate(home string, rest []string) error {
 		Severity: severity,
 	}
 	fullDesc := beads.RenderMeta(meta)
-	body := renderTemplate(beadType, desc, acceptance, compat, links)
+	body := renderTemplate(beadSchemas(cfg), beadType, desc, acceptance, compat, links)
 	if strings.TrimSpace(body) != "" {
 		fullDesc += "\n\n" + body
 	}
 	deps := splitCSV(depsCSV)
+	if err := checkBeadSchema(cfg, beads.Issue{Type: beadType, Priority: priority, Description: fullDesc}); err != nil {
+		return err
+	}
 	issue, err := client.Create(nil, beads.CreateRequest{
 		Title:       title,
 		Type:        beadType,
@@ -162,10 +182,11 @@ func beadCreate(home string, rest []string) error {
 
 func beadList(home string, rest []string) error {
 	if len(rest) < 1 {
-		return fmt.Errorf("usage: mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--priority <p>] [--turn <id>]")
+		return fmt.Errorf("usage: mforge bead list [--type <type>] [--status <status>] [--cell <cell>] [--priority <p>] [--turn <id>] " + listQueryUsage)
 	}
 	rigName := rest[0]
 	var beadType, status, cell, priority, turnID string
+	var query listQuery
 	for i := 1; i < len(rest); i++ {
 		switch rest[i] {
 		case "--type":
@@ -193,13 +214,19 @@ func beadList(home string, rest []string) error {
 				turnID = rest[i+1]
 				i++
 			}
+		default:
+			next, err := query.consume(rest, i)
+			if err != nil {
+				return fmt.Errorf("%w (usage: mforge bead list <rig> [--type <type>] [--status <status>] [--cell <cell>] [--priority <p>] [--turn <id>] %s)", err, listQueryUsage)
+			}
+			i = next
 		}
 	}
 	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return fmt.Errorf("listing beads: %w", err)
@@ -224,6 +251,13 @@ func beadList(home string, rest []string) error {
 		}
 		filtered = append(filtered, issue)
 	}
+	filtered, err = query.apply(filtered)
+	if err != nil {
+		return err
+	}
+	if query.custom() {
+		return query.print(os.Stdout, filtered)
+	}
 	printIssuesGrouped(filtered)
 	return nil
 }
@@ -237,7 +271,7 @@ func beadShow(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issue, err := client.Show(nil, id)
 	if err != nil {
 		return fmt.Errorf("showing bead %s: %w", id, err)
@@ -263,7 +297,7 @@ func beadClose(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	_, err = client.Close(nil, id, reason)
 	if err != nil {
 		return fmt.Errorf("closing bead %s: %w", id, err)
@@ -287,7 +321,7 @@ func beadStatus(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	if strings.EqualFold(status, "done") || strings.EqualFold(status, "closed") {
 		_, err = client.Close(nil, id, reason)
 		if err != nil {
@@ -295,7 +329,7 @@ func beadStatus(home string, rest []string) error {
 		}
 		return nil
 	}
-	_, err = client.UpdateStatus(nil, id, status)
+	_, err = client.Update(nil, id, beads.UpdateRequest{Status: status, Reason: reason})
 	if err != nil {
 		return fmt.Errorf("updating bead %s status: %w", id, err)
 	}
@@ -353,7 +387,7 @@ func beadTriage(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issue, err := client.Show(nil, id)
 	if err != nil {
 		return fmt.Errorf("showing bead %s: %w", id, err)
@@ -386,9 +420,9 @@ func beadTriage(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("creating assignment: %w", err)
 	}
-	mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, "DONE", issue)
+	mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, "DONE", issue, issueThread(client, issue.ID))
 	_ = createMailBead(client, meta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
-	_, _ = client.UpdateStatus(nil, issue.ID, "in_progress")
+	client.TryUpdate(nil, issue.ID, beads.UpdateRequest{Status: "in_progress", From: issue.Status})
 	fmt.Printf("Triaged bead %s -> assignment for %s/%s\n", issue.ID, cell, role)
 	return nil
 }
@@ -410,17 +444,25 @@ func beadDep(home string, rest []string) error {
 	if err != nil {
 		return fmt.Errorf("loading rig %s: %w", rigName, err)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
+	issues, err := client.List(nil)
+	if err != nil {
+		return fmt.Errorf("listing beads: %w", err)
+	}
+	if err := beads.NewGraph(issues).CheckDep(id, dep); err != nil {
+		return fmt.Errorf("adding dep %s to %s: %w", dep, id, err)
+	}
 	if err := client.DepAdd(nil, id, dep); err != nil {
 		return fmt.Errorf("adding dep %s to %s: %w", dep, id, err)
 	}
 	return nil
 }
 
-func renderTemplate(beadType, desc, acceptance, compat, links string) string {
+// renderTemplate lays out the body of a bead whose schema requires sections;
+// other types get the description as is.
+func renderTemplate(schemas map[string]beads.Schema, beadType, desc, acceptance, compat, links string) string {
 	body := strings.TrimSpace(desc)
-	needsSections := beadType == "contract" || beadType == "contractproposal" || beadType == "decision" || beadType == "migrationstep"
-	if !needsSections {
+	if len(schemas[strings.ToLower(beadType)].Sections) == 0 {
 		return body
 	}
 	lines := []string{}
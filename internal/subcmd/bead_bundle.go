@@ -0,0 +1,385 @@
+package subcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func beadExport(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead export (--epic <id>|--turn <id>|--cell <cell>) [--out <path>]")
+	}
+	rigName := rest[0]
+	var epicID, turnID, cellName, out string
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--epic":
+			if i+1 < len(rest) {
+				epicID = rest[i+1]
+				i++
+			}
+		case "--turn":
+			if i+1 < len(rest) {
+				turnID = rest[i+1]
+				i++
+			}
+		case "--cell":
+			if i+1 < len(rest) {
+				cellName = rest[i+1]
+				i++
+			}
+		case "--out":
+			if i+1 < len(rest) {
+				out = rest[i+1]
+				i++
+			}
+		}
+	}
+	selectors := 0
+	for _, v := range []string{epicID, turnID, cellName} {
+		if strings.TrimSpace(v) != "" {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		return fmt.Errorf("exactly one of --epic, --turn, or --cell is required")
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	client := beadsClient(home, cfg)
+	issues, err := client.List(nil)
+	if err != nil {
+		return fmt.Errorf("listing beads: %w", err)
+	}
+	var seeds []string
+	selector := ""
+	switch {
+	case epicID != "":
+		seeds = []string{epicID}
+		selector = "epic:" + epicID
+	default:
+		selector = "turn:" + turnID
+		if cellName != "" {
+			selector = "cell:" + cellName
+		}
+		for _, issue := range issues {
+			if strings.EqualFold(issue.Type, "event") {
+				continue
+			}
+			meta := beads.ParseMeta(issue.Description)
+			if (turnID != "" && meta.TurnID == turnID) || (cellName != "" && strings.EqualFold(meta.Cell, cellName)) {
+				seeds = append(seeds, issue.ID)
+			}
+		}
+	}
+	selected := beads.Closure(issues, seeds)
+	if len(selected) == 0 {
+		return fmt.Errorf("no beads match %s", selector)
+	}
+	bundle := beads.Bundle{
+		Header: beads.BundleHeader{Rig: rigName, Selector: selector, ExportedAt: time.Now().UTC().Format(time.RFC3339)},
+		Issues: selected,
+	}
+	for _, issue := range selected {
+		bundle.Files = append(bundle.Files, mailFiles(issue)...)
+	}
+	var w io.Writer = os.Stdout
+	if strings.TrimSpace(out) != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := beads.WriteBundle(w, bundle); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	if w != os.Stdout {
+		fmt.Printf("Exported %d bead(s) and %d mail file(s) to %s\n", len(bundle.Issues), len(bundle.Files), out)
+	}
+	return nil
+}
+
+// mailFiles reads the inbox and outbox of a bead that points at a worktree,
+// falling back to the archived copy once reconcile has moved them. Paths that
+// leave the worktree are skipped with a warning.
+func mailFiles(issue beads.Issue) []beads.BundleFile {
+	meta := beads.ParseMeta(issue.Description)
+	if strings.TrimSpace(meta.Worktree) == "" {
+		return nil
+	}
+	var out []beads.BundleFile
+	for _, rel := range []string{meta.Inbox, meta.Outbox} {
+		if strings.TrimSpace(rel) == "" {
+			continue
+		}
+		if !bundlePathWithin(rel) {
+			fmt.Fprintf(os.Stderr, "warning: not exporting %s for %s: it escapes the worktree\n", rel, issue.ID)
+			continue
+		}
+		for _, candidate := range []string{rel, filepath.Join("mail", "archive", filepath.Base(rel))} {
+			b, err := os.ReadFile(filepath.Join(meta.Worktree, candidate))
+			if err != nil {
+				continue
+			}
+			out = append(out, beads.BundleFile{Bead: issue.ID, Path: candidate, Content: string(b)})
+			break
+		}
+	}
+	return out
+}
+
+func beadImport(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead import --file <path> [--dry-run]")
+	}
+	rigName := rest[0]
+	var file string
+	dryRun := false
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--file":
+			if i+1 < len(rest) {
+				file = rest[i+1]
+				i++
+			}
+		case "--dry-run":
+			dryRun = true
+		}
+	}
+	if strings.TrimSpace(file) == "" {
+		return fmt.Errorf("--file is required")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("opening bundle: %w", err)
+	}
+	bundle, err := beads.ReadBundle(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	client := beadsClient(home, cfg)
+	existing, err := client.List(nil)
+	if err != nil {
+		return fmt.Errorf("listing beads: %w", err)
+	}
+	existingIDs := map[string]bool{}
+	byTitle := map[string]string{}
+	byContent := map[string]string{}
+	for _, issue := range existing {
+		existingIDs[issue.ID] = true
+		byTitle[strings.ToLower(issue.Type)+"|"+issue.Title] = issue.ID
+		byContent[importKey(issue)] = issue.ID
+	}
+	inBundle := map[string]bool{}
+	for _, issue := range bundle.Issues {
+		inBundle[issue.ID] = true
+	}
+
+	for _, f := range bundle.Files {
+		if !bundlePathWithin(f.Path) {
+			return fmt.Errorf("bundle file %q escapes the worktree", f.Path)
+		}
+	}
+
+	ids := map[string]string{}
+	created, reused := 0, 0
+	var pending []beads.Issue
+	for _, issue := range importOrder(bundle.Issues) {
+		next := beads.RewriteRefs(issue, ids)
+		next.Description = retargetWorktree(home, rigName, next.Description)
+		// Only a bead identical in type, title, and description (front
+		// matter included) is reused; a title match alone is a different
+		// bead that happens to share a name.
+		if match, ok := byContent[importKey(next)]; ok {
+			fmt.Printf("collision: %s %q matches existing %s; reusing it\n", issue.ID, issue.Title, match)
+			ids[issue.ID] = match
+			reused++
+			continue
+		}
+		if match, ok := byTitle[strings.ToLower(next.Type)+"|"+next.Title]; ok {
+			fmt.Printf("collision: %s %q has the title of existing %s but a different description; importing it as a new bead\n", issue.ID, issue.Title, match)
+		}
+		var deps, later []string
+		for _, dep := range next.Deps {
+			_, target := beads.ParseDep(dep)
+			switch {
+			case inBundle[target] && ids[target] == "":
+				later = append(later, dep)
+			case !inBundle[target] && !existingIDs[target]:
+				fmt.Printf("dangling: %s depends on %s, which is not in the bundle or this rig; dropping it\n", issue.ID, dep)
+			default:
+				deps = append(deps, dep)
+			}
+		}
+		if dryRun {
+			fmt.Printf("create: %s %s %q\n", issue.ID, issue.Type, next.Title)
+			ids[issue.ID] = issue.ID
+			created++
+			continue
+		}
+		status := next.Status
+		if beads.IsClosed(status) {
+			status = "open"
+		}
+		bead, err := client.Create(nil, beads.CreateRequest{
+			Title:       next.Title,
+			Type:        next.Type,
+			Priority:    next.Priority,
+			Status:      status,
+			Description: next.Description,
+			Deps:        deps,
+		})
+		if err != nil {
+			return fmt.Errorf("importing %s: %w", issue.ID, err)
+		}
+		if beads.IsClosed(next.Status) {
+			client.TryClose(nil, bead.ID, "imported")
+		}
+		ids[issue.ID] = bead.ID
+		existingIDs[bead.ID] = true
+		created++
+		if len(later) > 0 {
+			pending = append(pending, beads.Issue{ID: issue.ID, Deps: later})
+		}
+	}
+	for _, issue := range pending {
+		for _, dep := range beads.RewriteRefs(issue, ids).Deps {
+			if err := client.DepAdd(nil, ids[issue.ID], dep); err != nil {
+				return fmt.Errorf("adding dep %s to %s: %w", dep, ids[issue.ID], err)
+			}
+		}
+	}
+
+	written := 0
+	for _, file := range bundle.Files {
+		path := beads.RewriteRefs(beads.Issue{Title: file.Path}, ids).Title
+		worktree := importWorktree(home, rigName, bundle.Issues, file.Bead)
+		if worktree == "" {
+			fmt.Printf("skipped: %s (no matching cell in rig %s)\n", file.Path, rigName)
+			continue
+		}
+		if !bundlePathWithin(path) {
+			return fmt.Errorf("bundle file %q escapes the worktree", file.Path)
+		}
+		dst := filepath.Join(worktree, path)
+		if _, err := os.Stat(dst); err == nil {
+			fmt.Printf("collision: %s already exists; keeping it\n", dst)
+			continue
+		}
+		if dryRun {
+			fmt.Printf("write: %s\n", dst)
+			written++
+			continue
+		}
+		if err := util.EnsureDir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		if err := util.AtomicWriteFile(dst, []byte(file.Content), 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", dst, err)
+		}
+		written++
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d bead(s) (%d reused) and %d mail file(s) from %s\n", verb, created, reused, written, file)
+	return nil
+}
+
+// importKey identifies a bead by content for import collisions.
+func importKey(issue beads.Issue) string {
+	return strings.ToLower(issue.Type) + "|" + issue.Title + "|" + strings.TrimSpace(issue.Description)
+}
+
+// bundlePathWithin reports whether a bundle file path stays inside the
+// worktree it is written to: relative, and not climbing out with "..".
+func bundlePathWithin(path string) bool {
+	if strings.TrimSpace(path) == "" || filepath.IsAbs(path) {
+		return false
+	}
+	rel, err := filepath.Rel(".", filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// importOrder returns issues with every bead after the bundle beads it
+// references, so titles and deps can be rewritten as beads are created.
+// Cycles are broken in listing order.
+func importOrder(issues []beads.Issue) []beads.Issue {
+	byID := map[string]beads.Issue{}
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+	}
+	visited := map[string]bool{}
+	var out []beads.Issue
+	var visit func(id string)
+	visit = func(id string) {
+		issue, ok := byID[id]
+		if !ok || visited[id] {
+			return
+		}
+		visited[id] = true
+		for _, dep := range issue.Deps {
+			_, target := beads.ParseDep(dep)
+			visit(target)
+		}
+		out = append(out, issue)
+	}
+	for _, issue := range issues {
+		visit(issue.ID)
+	}
+	return out
+}
+
+// retargetWorktree points a bead's worktree at the same cell in this rig.
+func retargetWorktree(home, rigName, desc string) string {
+	meta := beads.ParseMeta(desc)
+	if strings.TrimSpace(meta.Worktree) == "" || strings.TrimSpace(meta.Cell) == "" {
+		return desc
+	}
+	cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, meta.Cell))
+	if err != nil || cellCfg.WorktreePath == meta.Worktree {
+		return desc
+	}
+	meta.Worktree = cellCfg.WorktreePath
+	out := beads.RenderMeta(meta)
+	if body := strings.TrimSpace(beads.StripMeta(desc)); body != "" {
+		out += "\n\n" + body
+	}
+	return out
+}
+
+func importWorktree(home, rigName string, issues []beads.Issue, id string) string {
+	for _, issue := range issues {
+		if issue.ID != id {
+			continue
+		}
+		meta := beads.ParseMeta(issue.Description)
+		if strings.TrimSpace(meta.Cell) == "" {
+			return ""
+		}
+		cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, meta.Cell))
+		if err != nil {
+			return ""
+		}
+		return cellCfg.WorktreePath
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+func beadCapabilities(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead capabilities [--refresh] [--json]")
+	}
+	rigName := rest[0]
+	refresh, asJSON := false, false
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--refresh":
+			refresh = true
+		case "--json":
+			asJSON = true
+		}
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	client := beadsClient(home, cfg)
+	probe := client.Capabilities
+	if refresh {
+		probe = client.ProbeCapabilities
+	}
+	caps, err := probe(nil)
+	if err != nil {
+		return fmt.Errorf("probing beads backend: %w", err)
+	}
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(caps)
+	}
+	yesNo := func(ok bool) string {
+		if ok {
+			return "yes"
+		}
+		return "no"
+	}
+	version := caps.Version
+	if version == "" {
+		version = "-"
+	}
+	fmt.Printf("Backend:            %s\n", caps.Backend)
+	fmt.Printf("Version:            %s\n", version)
+	fmt.Printf("Update description: %s\n", yesNo(caps.UpdateDescription))
+	fmt.Printf("Comments:           %s\n", yesNo(caps.Comments))
+	shape := caps.ListShape
+	if shape == "" {
+		shape = "unknown"
+	}
+	fmt.Printf("List JSON shape:    %s\n", shape)
+	types := "any"
+	if caps.CustomTypes != nil {
+		types = strings.Join(caps.CustomTypes, ",")
+		if types == "" {
+			types = "(none)"
+		}
+	}
+	fmt.Printf("Custom types:       %s\n", types)
+	if caps.ProbedAt != "" {
+		fmt.Printf("Probed at:          %s\n", caps.ProbedAt)
+	}
+	return nil
+}
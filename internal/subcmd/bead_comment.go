@@ -0,0 +1,75 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func beadComment(home string, rest []string) error {
+	if len(rest) < 2 {
+		return fmt.Errorf("usage: mforge bead comment <id> [--body <text>] [--author <name>] [--json]")
+	}
+	rigName := rest[0]
+	id := rest[1]
+	var body, author string
+	asJSON := false
+	for i := 2; i < len(rest); i++ {
+		switch rest[i] {
+		case "--body":
+			if i+1 < len(rest) {
+				body = rest[i+1]
+				i++
+			}
+		case "--author":
+			if i+1 < len(rest) {
+				author = rest[i+1]
+				i++
+			}
+		case "--json":
+			asJSON = true
+		}
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	client := beadsClient(home, cfg)
+	if strings.TrimSpace(body) != "" {
+		if strings.TrimSpace(author) == "" {
+			author = client.Actor
+		}
+		c, err := client.AddComment(nil, id, author, body)
+		if err != nil {
+			return fmt.Errorf("commenting on %s: %w", id, err)
+		}
+		if asJSON {
+			return json.NewEncoder(os.Stdout).Encode(c)
+		}
+		fmt.Printf("Commented on %s as %s\n", id, c.Author)
+		return nil
+	}
+	comments, err := client.Comments(nil, id)
+	if err != nil {
+		return fmt.Errorf("reading comments on %s: %w", id, err)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, c := range comments {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(comments) == 0 {
+		fmt.Printf("No comments on %s\n", id)
+		return nil
+	}
+	fmt.Print(beads.FormatThread(comments))
+	return nil
+}
@@ -0,0 +1,240 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func beadGC(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead gc [--dry-run] [--kind <kind>]")
+	}
+	rigName := rest[0]
+	dryRun := false
+	kind := ""
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--dry-run":
+			dryRun = true
+		case "--kind":
+			if i+1 < len(rest) {
+				kind = rest[i+1]
+				i++
+			}
+		}
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	policies, err := retentionPolicies(cfg)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(kind) != "" {
+		policies = policiesForKind(policies, kind)
+	}
+	client := beadsClient(home, cfg)
+	issues, err := client.List(nil)
+	if err != nil {
+		return fmt.Errorf("listing beads: %w", err)
+	}
+	now := time.Now().UTC()
+	keep, err := journalKeep(cfg)
+	if err != nil {
+		return err
+	}
+	plan := beads.PlanGC(issues, policies, now)
+	if dryRun {
+		pruned, err := pruneTelemetry(rig.TelemetryJournalPath(home, rigName), now.Add(-keep), true)
+		if err != nil {
+			return fmt.Errorf("reading telemetry journal: %w", err)
+		}
+		fmt.Printf("Would close %d event(s), delete %d event(s), write %d rollup(s), prune %d telemetry entr(ies)\n", len(plan.Close), len(plan.Delete), len(plan.Rollups), pruned)
+		for _, r := range plan.Rollups {
+			fmt.Printf("- rollup %s %s: %d\n", r.Day, r.Kind, r.Count)
+		}
+		return nil
+	}
+	closed := 0
+	for _, issue := range plan.Close {
+		if _, err := client.Close(nil, issue.ID, "retention"); err != nil {
+			return fmt.Errorf("closing %s: %w", issue.ID, err)
+		}
+		closed++
+	}
+	// Delete before writing rollups: a rerun after a failed rollup write
+	// under-counts the day, but can never count the same event twice.
+	deleted, err := deleteEvents(client, plan.Delete)
+	if err != nil {
+		return err
+	}
+	if err := writeRollups(client, issues, plan.Rollups); err != nil {
+		return err
+	}
+	pruned, err := pruneTelemetry(rig.TelemetryJournalPath(home, rigName), now.Add(-keep), false)
+	if err != nil {
+		return fmt.Errorf("pruning telemetry journal: %w", err)
+	}
+	fmt.Printf("Closed %d event(s), deleted %d event(s), wrote %d rollup(s), pruned %d telemetry entr(ies)\n", closed, deleted, len(plan.Rollups), pruned)
+	return nil
+}
+
+// deleteEvents hard-deletes issues in batches, so bd leaves no tombstones
+// behind for the events a rollup now counts.
+func deleteEvents(client beads.Client, issues []beads.Issue) (int, error) {
+	deleted := 0
+	for start := 0; start < len(issues); start += 100 {
+		end := min(start+100, len(issues))
+		ids := make([]string, 0, end-start)
+		for _, issue := range issues[start:end] {
+			ids = append(ids, issue.ID)
+		}
+		if _, err := client.Delete(nil, ids, beads.DeleteOptions{Force: true, Hard: true}); err != nil {
+			return deleted, fmt.Errorf("deleting events: %w", err)
+		}
+		deleted += len(ids)
+	}
+	return deleted, nil
+}
+
+// writeRollups folds each rollup into the existing bead for its day and
+// kind. A new bead is created only when there is none, or when the backend
+// cannot rewrite descriptions; any other update failure is returned rather
+// than papered over with a duplicate.
+func writeRollups(client beads.Client, issues []beads.Issue, rollups []beads.Rollup) error {
+	existing := map[string]beads.Issue{}
+	for _, issue := range issues {
+		meta := beads.ParseMeta(issue.Description)
+		if meta.Kind == beads.RollupKind {
+			existing[meta.Get("rollup_day")+"|"+meta.Get("rollup_kind")] = issue
+		}
+	}
+	canUpdate := client.Require(nil, beads.CapUpdateDescription) == nil
+	for _, r := range rollups {
+		if prev, ok := existing[r.Day+"|"+r.Kind]; ok && canUpdate {
+			if _, err := client.UpdateDescription(nil, prev.ID, beads.RenderRollup(r, prev.Description)); err != nil {
+				return fmt.Errorf("updating rollup %s for %s %s: %w", prev.ID, r.Kind, r.Day, err)
+			}
+			continue
+		}
+		created, err := client.Create(nil, beads.CreateRequest{
+			Title:       fmt.Sprintf("Event rollup %s %s", r.Kind, r.Day),
+			Type:        "event",
+			Priority:    "p3",
+			Status:      "open",
+			Description: beads.RenderRollup(r, ""),
+		})
+		if err != nil {
+			return fmt.Errorf("writing rollup for %s %s: %w", r.Kind, r.Day, err)
+		}
+		client.TryClose(nil, created.ID, "event rollup")
+	}
+	return nil
+}
+
+// defaultJournalKeep is how long telemetry entries are kept when rig.json
+// does not set retention.journal_keep.
+const defaultJournalKeep = 7 * 24 * time.Hour
+
+func journalKeep(cfg rig.RigConfig) (time.Duration, error) {
+	if cfg.Retention == nil || strings.TrimSpace(cfg.Retention.JournalKeep) == "" {
+		return defaultJournalKeep, nil
+	}
+	d, err := beads.ParseDuration(cfg.Retention.JournalKeep)
+	if err != nil {
+		return 0, fmt.Errorf("retention journal_keep: %w", err)
+	}
+	return d, nil
+}
+
+// pruneTelemetry drops journal entries timestamped before cutoff and reports
+// how many. It holds the lock journal writers append under and replaces the
+// file atomically; lines without a readable timestamp are kept.
+func pruneTelemetry(path string, cutoff time.Time, dryRun bool) (int, error) {
+	if !dryRun {
+		unlock, err := util.LockFile(nil, path+".lock")
+		if err != nil {
+			return 0, err
+		}
+		defer unlock()
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var keep []byte
+	pruned := 0
+	for _, line := range strings.SplitAfter(string(b), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var rec struct {
+			Timestamp string `json:"timestamp"`
+		}
+		if json.Unmarshal([]byte(line), &rec) == nil {
+			if at, err := time.Parse(time.RFC3339, rec.Timestamp); err == nil && at.Before(cutoff) {
+				pruned++
+				continue
+			}
+		}
+		keep = append(keep, line...)
+	}
+	if dryRun || pruned == 0 {
+		return pruned, nil
+	}
+	return pruned, util.AtomicWriteFile(path, keep, 0o644)
+}
+
+// retentionPolicies converts rig.json retention rules, falling back to the
+// built-in telemetry policies when none are configured.
+func retentionPolicies(cfg rig.RigConfig) ([]beads.RetentionPolicy, error) {
+	if cfg.Retention == nil || len(cfg.Retention.Policies) == 0 {
+		return beads.DefaultRetentionPolicies(), nil
+	}
+	out := make([]beads.RetentionPolicy, 0, len(cfg.Retention.Policies))
+	for _, p := range cfg.Retention.Policies {
+		policy := beads.RetentionPolicy{Kind: p.Kind, KeepLast: p.KeepLast, Rollup: p.Rollup}
+		if strings.TrimSpace(p.CloseAfter) != "" {
+			d, err := beads.ParseDuration(p.CloseAfter)
+			if err != nil {
+				return nil, fmt.Errorf("retention policy %s close_after: %w", p.Kind, err)
+			}
+			policy.CloseAfter = d
+		}
+		if strings.TrimSpace(p.DeleteAfter) != "" {
+			d, err := beads.ParseDuration(p.DeleteAfter)
+			if err != nil {
+				return nil, fmt.Errorf("retention policy %s delete_after: %w", p.Kind, err)
+			}
+			policy.DeleteAfter = d
+		}
+		out = append(out, policy)
+	}
+	return out, nil
+}
+
+// policiesForKind narrows policies to the one that applies to kind.
+func policiesForKind(policies []beads.RetentionPolicy, kind string) []beads.RetentionPolicy {
+	var fallback []beads.RetentionPolicy
+	for _, p := range policies {
+		if strings.EqualFold(p.Kind, kind) {
+			return []beads.RetentionPolicy{p}
+		}
+		if p.Kind == "*" {
+			p.Kind = kind
+			fallback = []beads.RetentionPolicy{p}
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,119 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func TestBeadGCFoldsRollupsOnceAndPrunesTelemetry(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	if err := Init(home, []string{"rig", "--repo", repo, "--beads", "jsonl"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	path := rig.RigConfigPath(home, "rig")
+	cfg, err := rig.LoadRigConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Retention = &rig.RetentionConfig{
+		Policies:    []rig.RetentionPolicy{{Kind: "agent_status", KeepLast: 1, Rollup: true}},
+		JournalKeep: "1d",
+	}
+	if err := rig.SaveRigConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	fresh := time.Now().UTC().Format(time.RFC3339)
+	journal := `{"timestamp":"` + old + `","kind":"agent_status"}` + "\n" + `{"timestamp":"` + fresh + `","kind":"agent_status"}` + "\n"
+	if err := os.WriteFile(rig.TelemetryJournalPath(home, "rig"), []byte(journal), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := beadsClient(home, cfg)
+	event := func() {
+		desc := beads.RenderMeta(beads.Meta{Kind: "agent_status", AgentID: "api/builder"})
+		if _, err := client.Create(nil, beads.CreateRequest{Title: "status", Type: "event", Status: "open", Description: desc}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rollups := func() []beads.Issue {
+		ResetBeadsCache()
+		issues, err := beadsClient(home, cfg).List(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []beads.Issue
+		for _, issue := range issues {
+			if beads.ParseMeta(issue.Description).Kind == beads.RollupKind {
+				out = append(out, issue)
+			}
+		}
+		return out
+	}
+
+	event()
+	event()
+	for i := 0; i < 2; i++ {
+		if err := Bead(home, []string{"gc", "rig"}); err != nil {
+			t.Fatalf("gc: %v", err)
+		}
+	}
+	count := func(got []beads.Issue) int {
+		if len(got) != 1 {
+			return -1
+		}
+		n, _ := beads.ParseMeta(got[0].Description).Int("rollup_count")
+		return n
+	}
+	if got := rollups(); count(got) != 1 {
+		t.Fatalf("expected one rollup counting one event after two passes, got %+v", got)
+	}
+
+	client = beadsClient(home, cfg)
+	event()
+	if err := Bead(home, []string{"gc", "rig"}); err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if got := rollups(); count(got) != 2 {
+		t.Fatalf("expected the rollup bead updated in place, got %+v", got)
+	}
+
+	b, err := os.ReadFile(rig.TelemetryJournalPath(home, "rig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), old) || !strings.Contains(string(b), fresh) {
+		t.Fatalf("expected only the old telemetry entry pruned:\n%s", b)
+	}
+}
+
+func TestDeleteEventsHardDeletesThroughBD(t *testing.T) {
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "calls")
+	script := "#!/bin/sh\necho \"$*\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "bd"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	repo := t.TempDir()
+	client := beads.Client{RepoPath: repo, Store: beads.BDStore{RepoPath: repo}}
+	n, err := deleteEvents(client, []beads.Issue{{ID: "mf-1"}, {ID: "mf-2"}})
+	if err != nil || n != 2 {
+		t.Fatalf("deleteEvents = %d, %v", n, err)
+	}
+	b, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(b)); got != "delete mf-1 mf-2 --hard --force" {
+		t.Fatalf("unexpected bd args %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func beadHistory(home string, rest []string) error {
+	if len(rest) < 2 {
+		return fmt.Errorf("usage: mforge bead history <id> [--json]")
+	}
+	rigName := rest[0]
+	id := rest[1]
+	asJSON := false
+	for i := 2; i < len(rest); i++ {
+		if rest[i] == "--json" {
+			asJSON = true
+		}
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	client := beadsClient(home, cfg)
+	transitions, err := client.History(nil, id)
+	if err != nil {
+		return fmt.Errorf("reading history of %s: %w", id, err)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, t := range transitions {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(transitions) == 0 {
+		fmt.Printf("No recorded status changes for %s\n", id)
+		return nil
+	}
+	for _, t := range transitions {
+		fmt.Println(beads.FormatTransition(t))
+	}
+	issue, err := client.Show(nil, id)
+	if err != nil {
+		return nil
+	}
+	tl := beads.NewTimeline(issue, transitions, time.Now().UTC())
+	if cycle := tl.Cycle(); cycle > 0 {
+		fmt.Printf("Cycle time: %s\n", cycle.Round(time.Second))
+	}
+	if tl.Blocked > 0 {
+		fmt.Printf("Blocked: %s\n", tl.Blocked.Round(time.Second))
+	}
+	return nil
+}
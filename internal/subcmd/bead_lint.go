@@ -0,0 +1,169 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func beadLint(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead lint [--type <type>] [--all] [--fix] [--json]")
+	}
+	rigName := rest[0]
+	var beadType string
+	all := false
+	fix := false
+	asJSON := false
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--type":
+			if i+1 < len(rest) {
+				beadType = rest[i+1]
+				i++
+			}
+		case "--all":
+			all = true
+		case "--fix":
+			fix = true
+		case "--json":
+			asJSON = true
+		}
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	schemas := beadSchemas(cfg)
+	client := beadsClient(home, cfg)
+	issues, err := client.List(nil)
+	if err != nil {
+		return fmt.Errorf("listing beads: %w", err)
+	}
+	var found []beads.Violation
+	repaired := 0
+	for _, issue := range issues {
+		if beadType != "" && !strings.EqualFold(issue.Type, beadType) {
+			continue
+		}
+		if !all && beads.IsClosed(issue.Status) {
+			continue
+		}
+		violations := beads.Validate(schemas, issue)
+		if len(violations) == 0 {
+			continue
+		}
+		if fix {
+			if desc, ok := repairBead(home, rigName, issue, violations); ok {
+				if _, err := client.UpdateDescription(nil, issue.ID, desc); err != nil {
+					return fmt.Errorf("repairing %s: %w", issue.ID, err)
+				}
+				issue.Description = desc
+				repaired++
+				violations = beads.Validate(schemas, issue)
+			}
+		}
+		found = append(found, violations...)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, v := range found {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, v := range found {
+			fmt.Printf("%s\t%s\t%s\n", v.ID, v.Type, v.String())
+		}
+		if fix {
+			fmt.Printf("Repaired %d bead(s)\n", repaired)
+		}
+	}
+	if len(found) > 0 {
+		return fmt.Errorf("%d schema violation(s)", len(found))
+	}
+	return nil
+}
+
+// beadSchemas returns the built-in schemas with rig.json overrides applied.
+// An override replaces the built-in schema for its type.
+func beadSchemas(cfg rig.RigConfig) map[string]beads.Schema {
+	schemas := beads.DefaultSchemas()
+	for beadType, s := range cfg.Schemas {
+		key := strings.ToLower(strings.TrimSpace(beadType))
+		schemas[key] = beads.Schema{Type: key, Required: s.Required, Allowed: s.Allowed, Sections: s.Sections}
+	}
+	return schemas
+}
+
+// checkBeadSchema rejects a bead about to be created or rewritten when it
+// breaks the rig's schema for its type.
+func checkBeadSchema(cfg rig.RigConfig, issue beads.Issue) error {
+	return beads.Check(beadSchemas(cfg), issue)
+}
+
+// repairBead fills front-matter keys that can be derived from the bead's deps
+// and its cell config. Violations it cannot repair are left for a human.
+func repairBead(home, rigName string, issue beads.Issue, violations []beads.Violation) (string, bool) {
+	meta := beads.ParseMeta(issue.Description)
+	var cellCfg rig.CellConfig
+	haveCell := false
+	if strings.TrimSpace(meta.Cell) != "" {
+		if c, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, meta.Cell)); err == nil {
+			cellCfg = c
+			haveCell = true
+		}
+	}
+	taskID := ""
+	for _, dep := range issue.Deps {
+		if kind, id := beads.ParseDep(dep); kind == beads.EdgeRelated {
+			taskID = id
+			break
+		}
+	}
+	changed := false
+	for _, v := range violations {
+		value := ""
+		switch v.Field {
+		case "worktree":
+			if haveCell {
+				value = cellCfg.WorktreePath
+			}
+		case "scope":
+			if haveCell {
+				value = cellCfg.ScopePrefix
+			}
+		case "inbox":
+			if taskID != "" {
+				value = filepath.Join("mail/inbox", taskID+".md")
+			}
+		case "outbox":
+			if taskID != "" {
+				value = filepath.Join("mail/outbox", taskID+".md")
+			}
+		case "promise":
+			if strings.EqualFold(issue.Type, "assignment") {
+				value = "DONE"
+			}
+		}
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		meta.Set(v.Field, value)
+		changed = true
+	}
+	if !changed {
+		return "", false
+	}
+	desc := beads.RenderMeta(meta)
+	if body := strings.TrimSpace(beads.StripMeta(issue.Description)); body != "" {
+		desc += "\n\n" + body
+	}
+	return desc, true
+}
@@ -1,8 +1,11 @@
 package subcmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/example/microforge/internal/beads"
@@ -32,3 +35,118 @@ func printIssuesGrouped(issues []beads.Issue) {
 		}
 	}
 }
+
+var defaultListFields = []string{"id", "type", "status", "priority", "title"}
+
+// listQuery holds the --where/--sort/--fields/--format/--limit flags shared
+// by bead list, task list, and request list.
+type listQuery struct {
+	where  string
+	sort   string
+	fields []string
+	format string
+	limit  int
+}
+
+const listQueryUsage = "[--where <expr>] [--sort <field,-field>] [--fields <a,b>] [--format text|json|tsv] [--limit <n>]"
+
+// consume parses a list-query flag at args[i], returning the index of the
+// last argument it used. Unknown flags, missing values, and a non-numeric
+// --limit are errors.
+func (q *listQuery) consume(args []string, i int) (int, error) {
+	switch args[i] {
+	case "--where", "--query", "--sort", "--fields", "--format", "--limit":
+		if i+1 >= len(args) {
+			return i, fmt.Errorf("%s requires a value", args[i])
+		}
+	default:
+		return i, fmt.Errorf("unknown flag %q", args[i])
+	}
+	switch args[i] {
+	case "--where", "--query":
+		q.where = args[i+1]
+	case "--sort":
+		q.sort = args[i+1]
+	case "--fields":
+		q.fields = splitCSV(args[i+1])
+	case "--format":
+		q.format = strings.ToLower(strings.TrimSpace(args[i+1]))
+	case "--limit":
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 0 {
+			return i, fmt.Errorf("--limit must be a non-negative number, got %q", args[i+1])
+		}
+		q.limit = n
+	}
+	return i + 1, nil
+}
+
+// custom reports whether output should bypass the command's default layout.
+func (q listQuery) custom() bool {
+	return len(q.fields) > 0 || (q.format != "" && q.format != "text")
+}
+
+// apply filters, sorts, and limits issues.
+func (q listQuery) apply(issues []beads.Issue) ([]beads.Issue, error) {
+	query, err := beads.ParseQuery(q.where)
+	if err != nil {
+		return nil, err
+	}
+	out := query.Filter(issues)
+	beads.SortIssues(out, beads.ParseSort(q.sort))
+	if q.limit > 0 && len(out) > q.limit {
+		out = out[:q.limit]
+	}
+	return out, nil
+}
+
+// print writes issues as JSON or TSV with the selected fields.
+func (q listQuery) print(w io.Writer, issues []beads.Issue) error {
+	fields := q.fields
+	if len(fields) == 0 {
+		fields = defaultListFields
+	}
+	switch q.format {
+	case "json":
+		if len(q.fields) == 0 {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(issues)
+		}
+		rows := make([]map[string]any, 0, len(issues))
+		for _, issue := range issues {
+			meta := beads.ParseMeta(issue.Description)
+			row := map[string]any{}
+			for _, f := range fields {
+				vals := beads.FieldValues(issue, meta, f)
+				switch {
+				case len(vals) == 1:
+					row[f] = vals[0]
+				case len(vals) > 1:
+					row[f] = vals
+				default:
+					row[f] = ""
+				}
+			}
+			rows = append(rows, row)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "", "text", "tsv":
+		if q.format == "tsv" {
+			fmt.Fprintln(w, strings.Join(fields, "\t"))
+		}
+		for _, issue := range issues {
+			meta := beads.ParseMeta(issue.Description)
+			vals := make([]string, 0, len(fields))
+			for _, f := range fields {
+				vals = append(vals, strings.NewReplacer("\t", " ", "\n", " ").Replace(beads.FieldValue(issue, meta, f)))
+			}
+			fmt.Fprintln(w, strings.Join(vals, "\t"))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want text, json, or tsv)", q.format)
+	}
+}
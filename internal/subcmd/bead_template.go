@@ -64,9 +64,9 @@ func BeadTemplate(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Cell: cell, Scope: scope, TurnID: turnID}
-	body := renderTemplate(beadType, "", "", "", "")
+	body := renderTemplate(beadSchemas(cfg), beadType, "", "", "", "")
 	issue, err := client.Create(nil, beads.CreateRequest{
 		Title:       title,
 		Type:        beadType,
@@ -0,0 +1,157 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func TestBeadCommandsWithJSONLBackend(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	if err := Init(home, []string{"rig", "--repo", repo, "--beads", "jsonl"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, "rig"))
+	if err != nil {
+		t.Fatalf("load rig: %v", err)
+	}
+	if err := Bead(home, []string{"create", "rig", "--type", "task", "--title", "Add healthz", "--cell", "alpha"}); err != nil {
+		t.Fatalf("bead create: %v", err)
+	}
+	issues, err := beadsClient(home, cfg).List(nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Title != "Add healthz" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	if err := Bead(home, []string{"close", "rig", issues[0].ID}); err != nil {
+		t.Fatalf("bead close: %v", err)
+	}
+	for _, list := range []func() error{
+		func() error { return Bead(home, []string{"list", "rig", "--limit", "ten"}) },
+		func() error { return Request(home, []string{"list", "rig", "--limit", "ten"}) },
+		func() error { return Task(home, []string{"list", "rig", "--limit", "ten"}) },
+		func() error { return Request(home, []string{"list", "rig", "--wher", "status=open"}) },
+	} {
+		if err := list(); err == nil || !strings.Contains(err.Error(), "usage:") {
+			t.Fatalf("expected a bad list flag to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestBeadExportImportRemapsIDs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	for _, name := range []string{"src", "dst"} {
+		if err := Init(home, []string{name, "--repo", t.TempDir(), "--beads", "jsonl"}); err != nil {
+			t.Fatalf("init %s: %v", name, err)
+		}
+	}
+	srcCfg, _ := rig.LoadRigConfig(rig.RigConfigPath(home, "src"))
+	src := beadsClient(home, srcCfg)
+	epic, _ := src.Create(nil, beads.CreateRequest{Title: "Epic", Type: "epic"})
+	first, _ := src.Create(nil, beads.CreateRequest{Title: "First", Type: "task", Deps: []string{"related:" + epic.ID}})
+	second, _ := src.Create(nil, beads.CreateRequest{Title: "Second", Type: "task", Deps: []string{"related:" + epic.ID, first.ID}})
+	_, _ = src.Create(nil, beads.CreateRequest{Title: "Assignment " + second.ID, Type: "assignment", Deps: []string{"related:" + second.ID}})
+	_, _ = src.Create(nil, beads.CreateRequest{Title: "Unrelated", Type: "task"})
+
+	bundle := filepath.Join(t.TempDir(), "epic.jsonl")
+	if err := Bead(home, []string{"export", "src", "--epic", epic.ID, "--out", bundle}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	dstCfg, _ := rig.LoadRigConfig(rig.RigConfigPath(home, "dst"))
+	dst := beadsClient(home, dstCfg)
+	_, _ = dst.Create(nil, beads.CreateRequest{Title: "Placeholder", Type: "task"})
+	if err := Bead(home, []string{"import", "dst", "--file", bundle}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	dst.Invalidate()
+	issues, err := dst.List(nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	byTitle := map[string]beads.Issue{}
+	for _, issue := range issues {
+		byTitle[issue.Title] = issue
+	}
+	if len(issues) != 5 {
+		t.Fatalf("expected 4 imported beads plus placeholder, got %+v", issues)
+	}
+	newSecond := byTitle["Second"]
+	if !beads.HasDep(newSecond.Deps, beads.EdgeBlocks, byTitle["First"].ID) || !beads.HasDep(newSecond.Deps, beads.EdgeRelated, byTitle["Epic"].ID) {
+		t.Fatalf("deps not remapped: %+v", newSecond)
+	}
+	if _, ok := byTitle["Assignment "+newSecond.ID]; !ok {
+		t.Fatalf("assignment title not remapped: %+v", issues)
+	}
+
+	if err := Bead(home, []string{"import", "dst", "--file", bundle}); err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	dst.Invalidate()
+	if again, _ := dst.List(nil); len(again) != len(issues) {
+		t.Fatalf("re-import should reuse colliding beads, got %d beads", len(again))
+	}
+}
+
+func TestBeadImportReusesOnlyIdenticalBeadsAndContainsFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	for _, name := range []string{"src", "dst"} {
+		if err := Init(home, []string{name, "--repo", t.TempDir(), "--beads", "jsonl"}); err != nil {
+			t.Fatalf("init %s: %v", name, err)
+		}
+	}
+	srcCfg, _ := rig.LoadRigConfig(rig.RigConfigPath(home, "src"))
+	epic, _ := beadsClient(home, srcCfg).Create(nil, beads.CreateRequest{Title: "Epic", Type: "epic", Description: "ship v2"})
+	bundle := filepath.Join(t.TempDir(), "epic.jsonl")
+	if err := Bead(home, []string{"export", "src", "--epic", epic.ID, "--out", bundle}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	dstCfg, _ := rig.LoadRigConfig(rig.RigConfigPath(home, "dst"))
+	dst := beadsClient(home, dstCfg)
+	_, _ = dst.Create(nil, beads.CreateRequest{Title: "Epic", Type: "epic", Description: "a different epic"})
+	if err := Bead(home, []string{"import", "dst", "--file", bundle}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	dst.Invalidate()
+	if issues, _ := dst.List(nil); len(issues) != 2 {
+		t.Fatalf("an epic with a different description should not be reused, got %+v", issues)
+	}
+
+	for _, p := range []string{"../../etc/passwd", "/etc/passwd", "mail/../../x"} {
+		if bundlePathWithin(p) {
+			t.Fatalf("%s should be rejected", p)
+		}
+	}
+	if !bundlePathWithin("mail/outbox/mf-1.md") {
+		t.Fatal("mail path should be accepted")
+	}
+}
+
+func TestMailFilesStayInsideWorktree(t *testing.T) {
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktree")
+	for path, body := range map[string]string{filepath.Join(wt, "mail", "inbox.md"): "in", filepath.Join(root, "secret"): "key"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, outbox := range []string{"../secret", filepath.Join(root, "secret")} {
+		desc := beads.RenderMeta(beads.Meta{Worktree: wt, Inbox: "mail/inbox.md", Outbox: outbox})
+		files := mailFiles(beads.Issue{ID: "mf-1", Description: desc})
+		if len(files) != 1 || files[0].Path != "mail/inbox.md" {
+			t.Fatalf("outbox %q: expected only the inbox, got %+v", outbox, files)
+		}
+	}
+}
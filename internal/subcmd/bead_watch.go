@@ -0,0 +1,89 @@
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+)
+
+func beadWatch(home string, rest []string) error {
+	if len(rest) < 1 {
+		return fmt.Errorf("usage: mforge bead watch [--interval <seconds>] [--type <type>] [--where <expr>] [--initial]")
+	}
+	rigName := rest[0]
+	interval := 2 * time.Second
+	var beadType, where string
+	initial := false
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case "--interval":
+			if i+1 < len(rest) {
+				if v, err := strconv.Atoi(rest[i+1]); err == nil && v > 0 {
+					interval = time.Duration(v) * time.Second
+				}
+				i++
+			}
+		case "--type":
+			if i+1 < len(rest) {
+				beadType = rest[i+1]
+				i++
+			}
+		case "--where":
+			if i+1 < len(rest) {
+				where = rest[i+1]
+				i++
+			}
+		case "--initial":
+			initial = true
+		}
+	}
+	query, err := beads.ParseQuery(where)
+	if err != nil {
+		return err
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	if err != nil {
+		return fmt.Errorf("loading rig %s: %w", rigName, err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	feed := beads.Watch(ctx, beadsClient(home, cfg), beads.WatchOptions{
+		Interval: interval,
+		Initial:  initial,
+		OnError: func(err error) {
+			fmt.Fprintf(os.Stderr, "bead watch: %v\n", err)
+		},
+	})
+	return streamChanges(os.Stdout, feed, beadType, query)
+}
+
+// streamChanges writes matching changes as JSON lines until feed closes.
+func streamChanges(w io.Writer, feed <-chan beads.Change, beadType string, query *beads.Query) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for change := range feed {
+		if strings.TrimSpace(beadType) != "" && !strings.EqualFold(change.Type, beadType) {
+			continue
+		}
+		subject := change.New
+		if subject == nil {
+			subject = change.Old
+		}
+		if query.Active() && !query.Match(*subject) {
+			continue
+		}
+		if err := enc.Encode(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -59,7 +59,7 @@ func Build(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{TurnID: turnID}
 	body := fmt.Sprintf("service=%s\nimage=%s\nstatus=%s", service, image, defaultIfEmpty(status, "built"))
 	issue, err := client.Create(nil, beads.CreateRequest{
@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/turn"
 	"github.com/example/microforge/internal/util"
@@ -16,21 +15,32 @@ import (
 
 func Cell(home string, args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: mforge cell <add|bootstrap|agent-file> ...")
+		return fmt.Errorf("usage: mforge cell <add|bootstrap|agent-file|config> ...")
 	}
 	op := args[0]
 	rest := args[1:]
 	switch op {
 	case "add":
 		if len(rest) < 2 {
-			return fmt.Errorf("usage: mforge cell add <rig> <cell> --scope <path-prefix>")
+			return fmt.Errorf("usage: mforge cell add <rig> <cell> --scope <path-prefix> [--include <glob>]... [--exclude <glob>]...")
 		}
 		rigName, cellName := rest[0], rest[1]
 		scope := ""
+		var include, exclude []string
 		for i := 2; i < len(rest); i++ {
-			if rest[i] == "--scope" && i+1 < len(rest) {
+			if i+1 >= len(rest) {
+				continue
+			}
+			switch rest[i] {
+			case "--scope":
 				scope = rest[i+1]
 				i++
+			case "--include":
+				include = append(include, splitCSV(rest[i+1])...)
+				i++
+			case "--exclude":
+				exclude = append(exclude, splitCSV(rest[i+1])...)
+				i++
 			}
 		}
 		if strings.TrimSpace(scope) == "" {
@@ -41,6 +51,8 @@ func Cell(home string, args []string) error {
 		cellCfg := rig.CellConfig{
 			Name:         cellName,
 			ScopePrefix:  scope,
+			Include:      include,
+			Exclude:      exclude,
 			WorktreePath: worktree,
 			CreatedAt:    time.Now().UTC().Format(time.RFC3339),
 		}
@@ -50,33 +62,59 @@ func Cell(home string, args []string) error {
 		if err := rig.SaveCellConfig(rig.CellConfigPath(home, rigName, cellName), cellCfg); err != nil {
 			return fmt.Errorf("saving cell config: %w", err)
 		}
-		fmt.Printf("Created cell %q (scope=%q)\n", cellName, scope)
+		fmt.Printf("Created cell %q (scope=%q)\n", cellName, cellCfg.Scope().String())
 		fmt.Printf("Worktree path: %s\n", worktree)
+		if cells, err := rig.ListCellConfigs(home, rigName); err == nil {
+			printOverlaps(cells, cellName)
+		}
 		return nil
 
 	case "bootstrap":
 		if len(rest) < 2 {
-			return fmt.Errorf("usage: mforge cell bootstrap <rig> <cell> [--architect] [--single]")
+			return fmt.Errorf("usage: mforge cell bootstrap <rig> <cell> [--architect] [--single] [--role <name>]")
 		}
 		rigName, cellName := rest[0], rest[1]
 		withArchitect := false
 		single := false
+		var extra []string
 		for i := 2; i < len(rest); i++ {
-			if rest[i] == "--architect" {
+			switch rest[i] {
+			case "--architect":
 				withArchitect = true
-			}
-			if rest[i] == "--single" {
+			case "--single":
 				single = true
+			case "--role":
+				if i+1 < len(rest) {
+					extra = append(extra, splitCSV(rest[i+1])...)
+					i++
+				}
 			}
 		}
 		cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
+		if err := rig.ValidateRoles(cfg.Roles); err != nil {
+			return fmt.Errorf("rig.json roles: %w", err)
+		}
+		roles := bootstrapRoles(single, withArchitect)
+		for _, role := range extra {
+			if _, ok := cfg.Role(role); !ok {
+				return fmt.Errorf("unknown role %q; define it under roles in rig.json", role)
+			}
+			if !hasArg(roles, role) {
+				roles = append(roles, role)
+			}
+		}
 		cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName))
 		if err != nil {
 			return fmt.Errorf("loading cell %s: %w", cellName, err)
 		}
+		if applyGuardDefaults(home, rigName, &cellCfg) {
+			if err := rig.SaveCellConfig(rig.CellConfigPath(home, rigName, cellName), cellCfg); err != nil {
+				return fmt.Errorf("saving cell config: %w", err)
+			}
+		}
 
 		wt := cellCfg.WorktreePath
 		repoHasGit := false
@@ -102,7 +140,7 @@ func Cell(home string, args []string) error {
 						return fmt.Errorf("git worktree add failed: %w", err)
 					}
 				} else {
-					fmt.Printf("Warning: worktree %s is not a git worktree (non-empty directory)\n", wt)
+					fmt.Printf("Warning: worktree %s is not a git worktree (non-empty directory); see `mforge doctor`\n", wt)
 				}
 			}
 		}
@@ -116,16 +154,9 @@ func Cell(home string, args []string) error {
 		}
 		ensureClaudeSymlink(cfg.RepoPath, wt)
 
-		roles := []string{"builder", "monitor", "reviewer"}
-		if single {
-			roles = []string{"cell"}
-		}
-		if withArchitect {
-			roles = append(roles, "architect")
-		}
-		writeRoleGuides(wt, single, withArchitect)
+		writeRoleGuides(wt, cfg, roles)
 
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, _ := client.List(nil)
 		agentID := ""
 		agentClass := "worker"
@@ -155,22 +186,23 @@ func Cell(home string, args []string) error {
 			issues = updated
 
 			identity := map[string]any{
-				"rig_name":      rigName,
-				"rig_home":      home,
-				"repo_path":     cfg.RepoPath,
-				"cell_name":     cellName,
-				"role":          role,
-				"scope":         cellCfg.ScopePrefix,
-				"worktree_path": wt,
-				"tmux_session":  tmuxSession,
-				"inbox":         "mail/inbox",
-				"outbox":        "mail/outbox",
-				"archive":       "mail/archive",
-				"agent_id":      agentID,
-				"role_id":       roleIssue.ID,
-				"mailbox_id":    mailIssue.ID,
-				"hook_id":       hookIssue.ID,
-				"class":         agentClass,
+				"schema_version": rig.AgentSchemaVersion,
+				"rig_name":       rigName,
+				"rig_home":       home,
+				"repo_path":      cfg.RepoPath,
+				"cell_name":      cellName,
+				"role":           role,
+				"scope":          cellCfg.ScopePrefix,
+				"worktree_path":  wt,
+				"tmux_session":   tmuxSession,
+				"inbox":          "mail/inbox",
+				"outbox":         "mail/outbox",
+				"archive":        "mail/archive",
+				"agent_id":       agentID,
+				"role_id":        roleIssue.ID,
+				"mailbox_id":     mailIssue.ID,
+				"hook_id":        hookIssue.ID,
+				"class":          agentClass,
 			}
 			b, _ := json.MarshalIndent(identity, "", "  ")
 			metaDir := rig.CellMetaDir(home, rigName, cellName)
@@ -197,10 +229,14 @@ func Cell(home string, args []string) error {
 %s
     ],
     "PreToolUse": [
-      { "matcher": "Write|Edit", "hooks": [ { "type": "command", "command": "mforge hook guardrails" }, { "type": "command", "command": "mforge hook emit --event claude_pre_tool" } ] }
+      { "matcher": "*", "hooks": [ { "type": "command", "command": "mforge hook guardrails" } ] },
+      { "matcher": "Write|Edit", "hooks": [ { "type": "command", "command": "mforge hook emit --event claude_pre_tool" } ] }
     ],
     "PermissionRequest": [
       { "matcher": "Bash", "hooks": [ { "type": "command", "command": "mforge hook guardrails" }, { "type": "command", "command": "mforge hook emit --event claude_permission" } ] }
+    ],
+    "PostToolUse": [
+      { "hooks": [ { "type": "command", "command": "mforge hook heartbeat" } ] }
     ]
   }
 }`, strings.Join(stopHooks, ",\n"))
@@ -219,18 +255,26 @@ func Cell(home string, args []string) error {
 		copyKubeconfig(wt)
 
 		fmt.Printf("Bootstrapped cell %q at %s\n", cellName, wt)
-		switch {
-		case single && withArchitect:
-			fmt.Println("Created agents: cell, architect")
-		case single:
-			fmt.Println("Created agents: cell")
-		case withArchitect:
-			fmt.Println("Created agents: builder, monitor, reviewer, architect")
-		default:
-			fmt.Println("Created agents: builder, monitor, reviewer")
-		}
+		fmt.Println("Created agents: " + strings.Join(roles, ", "))
 		return nil
 
+	case "config":
+		if len(rest) < 2 {
+			return fmt.Errorf("usage: mforge cell config <rig> <cell> [--json]")
+		}
+		rigName, cellName := rest[0], rest[1]
+		jsonOut := false
+		for i := 2; i < len(rest); i++ {
+			if rest[i] == "--json" {
+				jsonOut = true
+			}
+		}
+		resolved, err := resolveCell(home, rigName, cellName)
+		if err != nil {
+			return err
+		}
+		return printResolvedCell(resolved, jsonOut)
+
 	case "agent-file":
 		if len(rest) < 2 {
 			return fmt.Errorf("usage: mforge cell agent-file <rig> <cell> --role <role>")
@@ -256,17 +300,18 @@ func Cell(home string, args []string) error {
 		}
 		tmuxSession := fmt.Sprintf("%s-%s-%s-%s", cfg.TmuxPrefix, rigName, cellName, role)
 		identity := map[string]any{
-			"rig_name":      rigName,
-			"rig_home":      home,
-			"repo_path":     cfg.RepoPath,
-			"cell_name":     cellName,
-			"role":          role,
-			"scope":         cellCfg.ScopePrefix,
-			"worktree_path": cellCfg.WorktreePath,
-			"tmux_session":  tmuxSession,
-			"inbox":         "mail/inbox",
-			"outbox":        "mail/outbox",
-			"archive":       "mail/archive",
+			"schema_version": rig.AgentSchemaVersion,
+			"rig_name":       rigName,
+			"rig_home":       home,
+			"repo_path":      cfg.RepoPath,
+			"cell_name":      cellName,
+			"role":           role,
+			"scope":          cellCfg.ScopePrefix,
+			"worktree_path":  cellCfg.WorktreePath,
+			"tmux_session":   tmuxSession,
+			"inbox":          "mail/inbox",
+			"outbox":         "mail/outbox",
+			"archive":        "mail/archive",
 		}
 		b, _ := json.MarshalIndent(identity, "", "  ")
 		metaDir := rig.CellMetaDir(home, rigName, cellName)
@@ -285,6 +330,22 @@ func Cell(home string, args []string) error {
 	}
 }
 
+// applyGuardDefaults fills a cell's missing protected and read_deny lists
+// with the defaults and reports whether it changed anything. Lists already
+// in cell.json are kept.
+func applyGuardDefaults(home, rigName string, cell *rig.CellConfig) bool {
+	changed := false
+	if cell.Protected == nil {
+		cell.Protected = append([]string{}, rig.DefaultProtectedPaths...)
+		changed = true
+	}
+	if cell.ReadDeny == nil {
+		cell.ReadDeny = rig.DefaultReadDeny(home, rigName)
+		changed = true
+	}
+	return changed
+}
+
 func copyKubeconfig(worktree string) {
 	src := strings.TrimSpace(os.Getenv("MF_KUBECONFIG"))
 	if src == "" {
@@ -328,9 +389,10 @@ func cellBranchName(home, rigName, cellName string) string {
 	return strings.Join(parts, "/")
 }
 
-func writeRoleGuides(worktree string, single bool, withArchitect bool) {
-	dir := filepath.Join(worktree, ".mf", "roles")
-	_ = util.EnsureDir(dir)
+// bootstrapRoles is the built-in role set for a cell: the builder, monitor
+// and reviewer team, or a single merged cell agent, optionally with an
+// architect.
+func bootstrapRoles(single, withArchitect bool) []string {
 	roles := []string{"builder", "monitor", "reviewer"}
 	if single {
 		roles = []string{"cell"}
@@ -338,12 +400,33 @@ func writeRoleGuides(worktree string, single bool, withArchitect bool) {
 	if withArchitect {
 		roles = append(roles, "architect")
 	}
+	return roles
+}
+
+// writeRoleGuides writes .mf/roles/<role>.md for each role. A guide file
+// declared in the registry (relative to the repo) is copied every time;
+// otherwise the built-in or generated guide is written once so local edits
+// survive re-bootstrapping.
+func writeRoleGuides(worktree string, cfg rig.RigConfig, roles []string) {
+	dir := filepath.Join(worktree, ".mf", "roles")
+	_ = util.EnsureDir(dir)
 	for _, role := range roles {
+		spec, _ := cfg.Role(role)
 		path := filepath.Join(dir, role+".md")
+		if src := strings.TrimSpace(spec.Guide); src != "" {
+			if !filepath.IsAbs(src) {
+				src = filepath.Join(cfg.RepoPath, src)
+			}
+			if b, err := os.ReadFile(src); err == nil {
+				_ = util.AtomicWriteFile(path, b, 0o644)
+				continue
+			}
+			fmt.Printf("Warning: guide %s for role %s not found; using the default\n", src, role)
+		}
 		if _, err := os.Stat(path); err == nil {
 			continue
 		}
-		content := defaultRoleGuide(role)
+		content := defaultRoleGuide(spec)
 		if strings.TrimSpace(content) == "" {
 			continue
 		}
@@ -360,21 +443,36 @@ func readRoleGuide(worktree, role string) string {
 	return string(b)
 }
 
-func defaultRoleGuide(role string) string {
-	switch role {
-	case "builder":
-		return "Builder role\n\n- Implement assigned tasks strictly in-scope.\n- Update tests for changes.\n- If out-of-scope work is needed, emit a request bead and stop.\n- When complete, include the promise token in the outbox message."
-	case "reviewer":
-		return "Reviewer role\n\n- Validate acceptance criteria and scope boundaries.\n- Reject shortcuts (no silent contract changes, no skipped tests).\n- Emit ReviewRequest for missing tests or defects.\n- Approve only when changes are correct and safe."
-	case "monitor":
-		return "Monitor role\n\n- Run scoped tests and checks.\n- Only emit events/requests; do not modify code.\n- If flakiness or regression is detected, emit MonitorRequest.\n- Report signals with scope and impact."
-	case "architect":
-		return "Architect role\n\n- Update docs and cross-service contracts.\n- Enforce compatibility plans for shared interfaces.\n- Emit DocUpdateNeeded when documentation or design is missing."
-	case "cell":
-		return "Cell role (merged triad)\n\nModes to time-slice within a single agent:\n- Build: implement tasks and update tests.\n- Review: self-review with reviewer-level strictness.\n- Monitor: run checks and emit MonitorRequest as needed.\n\nRules:\n- No out-of-scope edits.\n- Emit events instead of direct cross-cell changes.\n- Keep notes in the outbox with the promise token when done."
-	default:
+// defaultRoleGuide returns the built-in guide, or a short one generated from
+// the role's declared capabilities.
+func defaultRoleGuide(spec rig.RoleSpec) string {
+	if text := spec.GuideText(); text != "" {
+		return text
+	}
+	if strings.TrimSpace(spec.Name) == "" {
 		return ""
 	}
+	lines := []string{spec.Name + " role", ""}
+	if spec.ReadOnly() {
+		lines = append(lines, "- Read-only: do not modify files; emit events and requests instead.")
+	} else {
+		lines = append(lines, "- Implement assigned tasks strictly in-scope.")
+	}
+	switch spec.Bash {
+	case rig.BashNone:
+		lines = append(lines, "- Bash is not available to this role.")
+	case rig.BashAllowlist:
+		if len(spec.AllowedCommands) > 0 {
+			lines = append(lines, "- Bash is limited to: "+strings.Join(spec.AllowedCommands, ", ")+".")
+		} else {
+			lines = append(lines, "- Bash is limited to the cell's allowed commands.")
+		}
+	}
+	if len(spec.TaskKinds) > 0 {
+		lines = append(lines, "- Handles task kinds: "+strings.Join(spec.TaskKinds, ", ")+".")
+	}
+	lines = append(lines, "- When complete, include the promise token in the outbox message.")
+	return strings.Join(lines, "\n")
 }
 
 func ensureHookConfig(worktree string) error {
@@ -394,3 +492,65 @@ func ensureHookConfig(worktree string) error {
 }`
 	return util.AtomicWriteFile(path, []byte(config+"\n"), 0o644)
 }
+
+// printResolvedCell shows a cell's effective settings and which layer
+// (default, rig, or cell) supplied each one.
+func printResolvedCell(res rig.Resolved, jsonOut bool) error {
+	roles := res.Rig.RoleNames()
+	runtime := map[string][]string{}
+	for _, role := range roles {
+		cmd, args := runtimeForCell(res, role)
+		runtime[role] = append([]string{cmd}, args...)
+	}
+	if jsonOut {
+		out := map[string]any{
+			"cell":             res.Cell.Name,
+			"scope":            res.Cell.Scope().String(),
+			"runtime":          runtime,
+			"model":            res.Model,
+			"env":              res.Env,
+			"test_cmd":         res.TestCmd,
+			"lint_cmd":         res.LintCmd,
+			"build_cmd":        res.BuildCmd,
+			"allowed_commands": res.AllowedCommands,
+			"stale_after":      res.StaleAfter.String(),
+			"idle_after":       res.IdleAfter.String(),
+			"denials_per_turn": res.DenialsPerTurn,
+			"source":           res.Source,
+		}
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	fmt.Printf("Cell %s (scope=%s)\n", res.Cell.Name, res.Cell.Scope().String())
+	for _, role := range roles {
+		src := res.Source["runtime_roles."+role]
+		if src == "" {
+			src = "rig"
+		}
+		fmt.Printf("  runtime %-9s %s [%s]\n", role, strings.Join(runtime[role], " "), src)
+	}
+	fmt.Printf("  model             %s [%s]\n", res.Model, res.Source["model"])
+	fmt.Printf("  env               %s [%s]\n", strings.Join(envKeys(res.EnvList()), ","), res.Source["env"])
+	fmt.Printf("  test_cmd          %s [%s]\n", strings.Join(res.TestCmd, " "), res.Source["test_cmd"])
+	fmt.Printf("  lint_cmd          %s [%s]\n", strings.Join(res.LintCmd, " "), res.Source["lint_cmd"])
+	fmt.Printf("  build_cmd         %s [%s]\n", strings.Join(res.BuildCmd, " "), res.Source["build_cmd"])
+	fmt.Printf("  allowed_commands  %s [%s]\n", strings.Join(res.AllowedCommands, ","), res.Source["allowed_commands"])
+	fmt.Printf("  stale_after       %s [%s]\n", res.StaleAfter, res.Source["health.stale_after"])
+	fmt.Printf("  idle_after        %s [%s]\n", res.IdleAfter, res.Source["health.idle_after"])
+	fmt.Printf("  denials_per_turn  %d [%s]\n", res.DenialsPerTurn, res.Source["health.denials_per_turn"])
+	return nil
+}
+
+// envKeys strips values from KEY=VALUE pairs so secrets stay off screen.
+func envKeys(pairs []string) []string {
+	out := make([]string, 0, len(pairs))
+	for _, kv := range pairs {
+		k, _, _ := strings.Cut(kv, "=")
+		out = append(out, k)
+	}
+	return out
+}
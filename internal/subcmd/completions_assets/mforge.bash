@@ -314,12 +314,12 @@ _mforge_complete() {
   sub="${COMP_WORDS[2]}"
 
   if [ $COMP_CWORD -eq 1 ]; then
-    _mforge_complete_from_list "$cur" init cell agent task request monitor epic manager turn round checkpoint bead review pr merge wait coordinator digest build deploy contract architect report library scope engine convoy watch quick-assign tui migrate context rig ssh completions hook help
+    _mforge_complete_from_list "$cur" init cell agent task request monitor epic manager turn round checkpoint bead review pr merge wait coordinator digest build deploy contract architect report library scope engine convoy watch quick-assign tui migrate doctor plan apply secret policy guardrails context rig ssh completions hook help
     return
   fi
 
   if [ "$cmd" = "help" ]; then
-    _mforge_complete_from_list "$cur" init cell agent task request monitor epic manager turn round checkpoint bead review pr merge wait coordinator digest build deploy contract architect report library scope engine convoy watch quick-assign tui migrate context rig ssh completions hook
+    _mforge_complete_from_list "$cur" init cell agent task request monitor epic manager turn round checkpoint bead review pr merge wait coordinator digest build deploy contract architect report library scope engine convoy watch quick-assign tui migrate doctor plan apply secret policy guardrails context rig ssh completions hook
     return
   fi
 
@@ -334,7 +334,7 @@ _mforge_complete() {
       ;;
     cell)
       if [ $COMP_CWORD -eq 2 ]; then
-        _mforge_complete_from_list "$cur" add bootstrap agent-file
+        _mforge_complete_from_list "$cur" add bootstrap agent-file config
         return
       fi
       case "$sub" in
@@ -346,7 +346,7 @@ _mforge_complete() {
             _mforge_complete_from_list "$cur" $(_mforge_scopes)
             return
           fi
-          _mforge_complete_from_list "$cur" --scope
+          _mforge_complete_from_list "$cur" --scope --include --exclude
           return
           ;;
         bootstrap)
@@ -354,7 +354,7 @@ _mforge_complete() {
             _mforge_complete_from_list "$cur" $(_mforge_cells)
             return
           fi
-          _mforge_complete_from_list "$cur" --architect --single
+          _mforge_complete_from_list "$cur" --architect --single --role
           return
           ;;
         agent-file)
@@ -369,6 +369,14 @@ _mforge_complete() {
           _mforge_complete_from_list "$cur" --role
           return
           ;;
+        config)
+          if [ $COMP_CWORD -eq 3 ]; then
+            _mforge_complete_from_list "$cur" $(_mforge_cells)
+            return
+          fi
+          _mforge_complete_from_list "$cur" --json
+          return
+          ;;
       esac
       ;;
     agent)
@@ -949,6 +957,37 @@ _mforge_complete() {
       _mforge_complete_from_list "$cur" --all
       return
       ;;
+    doctor)
+      _mforge_complete_from_list "$cur" --fix --json
+      return
+      ;;
+    plan|apply)
+      _mforge_complete_from_list "$cur" --file --json
+      return
+      ;;
+    policy)
+      if [ $COMP_CWORD -eq 2 ]; then
+        _mforge_complete_from_list "$cur" show check
+        return
+      fi
+      _mforge_complete_from_list "$cur" --cell --role --tool --command --path --json
+      return
+      ;;
+    guardrails)
+      if [ $COMP_CWORD -eq 2 ]; then
+        _mforge_complete_from_list "$cur" report
+        return
+      fi
+      _mforge_complete_from_list "$cur" --since --cell --role --recent --json
+      return
+      ;;
+    secret)
+      if [ $COMP_CWORD -eq 2 ]; then
+        _mforge_complete_from_list "$cur" set list rm
+        return
+      fi
+      return
+      ;;
     completions)
       if [ $COMP_CWORD -eq 2 ]; then
         _mforge_complete_from_list "$cur" install path bash zsh
@@ -72,7 +72,7 @@ func Contract(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Cell: cell, Scope: scope, TurnID: turnID}
 	body := "## Acceptance Criteria\n" + defaultIfEmpty(acceptance, "- [ ] Define contract tests\n- [ ] Document rollout plan")
 	body += "\n\n## Compatibility Notes\n" + defaultIfEmpty(compat, "Additive first; deprecation window if needed.")
@@ -53,7 +53,7 @@ func Convoy(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Kind: "convoy", Title: title}
 	convoy, err := client.Create(nil, beads.CreateRequest{
 		Title:       title,
@@ -78,13 +78,10 @@ func Convoy(home string, args []string) error {
 		return err
 	}
 	wakeSet := map[string]struct{}{}
-	for _, issue := range issues {
+	for _, issue := range beads.NewGraph(issues).Children(epicID) {
 		if strings.ToLower(issue.Type) != "task" {
 			continue
 		}
-		if !hasDep(issue, epicID) {
-			continue
-		}
 		meta := beads.ParseMeta(issue.Description)
 		cell := matchCellByScope(cells, meta.Scope)
 		if cell == nil {
@@ -97,7 +94,7 @@ func Convoy(home string, args []string) error {
 		cell, role := parts[0], parts[1]
 		_ = Agent(home, []string{"wake", rigName, cell, role})
 	}
-	emitOrchestrationEvent(cfg.RepoPath, beads.Meta{Kind: "convoy_start", ConvoyID: convoy.ID}, "Convoy start "+convoy.ID, []string{"related:" + epicID})
+	emitOrchestrationEvent(client, beads.Meta{Kind: "convoy_start", ConvoyID: convoy.ID}, "Convoy start "+convoy.ID, []string{"related:" + epicID})
 	fmt.Printf("Convoy started %s\n", convoy.ID)
 	return nil
 }
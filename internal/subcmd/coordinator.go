@@ -38,7 +38,7 @@ func Coordinator(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -50,7 +50,7 @@ func Coordinator(home string, args []string) error {
 			continue
 		}
 		if strings.ToLower(issue.Type) == "pr" && issue.Status == "ready" {
-			_, _ = client.UpdateStatus(nil, issue.ID, "queued")
+			client.TryUpdate(nil, issue.ID, beads.UpdateRequest{Status: "queued", From: issue.Status})
 			count++
 		}
 	}
@@ -59,7 +59,7 @@ func Deploy(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{TurnID: turnID}
 	body := fmt.Sprintf("env=%s\nservice=%s\nstatus=%s", env, service, defaultIfEmpty(status, "deployed"))
 	issue, err := client.Create(nil, beads.CreateRequest{
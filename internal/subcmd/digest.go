@@ -39,7 +39,7 @@ func Digest(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
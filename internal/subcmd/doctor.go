@@ -0,0 +1,551 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+// Doctor check outcomes.
+const (
+	doctorOK    = "ok"
+	doctorWarn  = "warn"
+	doctorFail  = "fail"
+	doctorFixed = "fixed"
+)
+
+// doctorCheck is one preflight result. Repair, when set, is what --fix runs;
+// checks without one print Hint instead.
+type doctorCheck struct {
+	Name   string       `json:"name"`
+	Status string       `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Hint   string       `json:"hint,omitempty"`
+	Repair func() error `json:"-"`
+}
+
+// claudeHookEvents are the settings.json hook events Claude Code accepts.
+var claudeHookEvents = map[string]bool{
+	"PreToolUse": true, "PostToolUse": true, "PermissionRequest": true, "Notification": true,
+	"UserPromptSubmit": true, "Stop": true, "SubagentStop": true, "PreCompact": true,
+	"SessionStart": true, "SessionEnd": true,
+}
+
+func Doctor(home string, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: mforge doctor [--fix] [--json]")
+	}
+	rigName := args[0]
+	fix, asJSON := false, false
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--fix":
+			fix = true
+		case "--json":
+			asJSON = true
+		}
+	}
+	checks := doctorChecks(home, rigName)
+	if fix {
+		for i := range checks {
+			c := &checks[i]
+			if c.Repair == nil || c.Status == doctorOK {
+				continue
+			}
+			if err := c.Repair(); err != nil {
+				c.Detail += fmt.Sprintf(" (repair failed: %v)", err)
+				continue
+			}
+			c.Status = doctorFixed
+		}
+	}
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+	}
+	if asJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(checks); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range checks {
+			line := fmt.Sprintf("[%-5s] %s", c.Status, c.Name)
+			if c.Detail != "" {
+				line += ": " + c.Detail
+			}
+			fmt.Println(line)
+			if c.Status != doctorOK && c.Status != doctorFixed {
+				switch {
+				case c.Repair != nil && !fix:
+					fmt.Println("        fixable with `mforge doctor --fix`")
+				case c.Hint != "":
+					fmt.Println("        " + c.Hint)
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("doctor found %d failing check(s)", failed)
+	}
+	return nil
+}
+
+func doctorChecks(home, rigName string) []doctorCheck {
+	cfgPath := rig.RigConfigPath(home, rigName)
+	cfg, err := rig.LoadRigConfig(cfgPath)
+	if err != nil {
+		return []doctorCheck{{Name: "rig config", Status: doctorFail, Detail: err.Error(), Hint: "run `mforge init " + rigName + " --repo <path>`"}}
+	}
+	var checks []doctorCheck
+	checks = append(checks, checkBinary("git", "git", "--version"))
+	if strings.TrimSpace(cfg.RemoteHost) == "" {
+		checks = append(checks, checkBinary("tmux", "tmux", "-V"))
+	}
+	if cmd := strings.TrimSpace(cfg.RuntimeCmd); cmd != "" {
+		checks = append(checks, checkBinary("runtime "+cmd, cmd, "--version"))
+	}
+	checks = append(checks, checkRepo(cfg))
+	checks = append(checks, checkRuntimeArgs(cfgPath))
+	if len(cfg.Roles) > 0 {
+		c := doctorCheck{Name: "roles", Status: doctorOK, Detail: strings.Join(cfg.RoleNames(), ", ")}
+		if err := rig.ValidateRoles(cfg.Roles); err != nil {
+			c.Status = doctorFail
+			c.Detail = err.Error()
+			c.Hint = "fix roles in rig.json"
+		}
+		checks = append(checks, c)
+	}
+	checks = append(checks, checkBeadsBackend(home, cfg)...)
+	if strings.TrimSpace(cfg.LibraryContext7Token) != "" {
+		checks = append(checks, checkSecrets(home, "secrets", map[string]string{"library_context7_token": cfg.LibraryContext7Token}))
+	}
+
+	checks = append(checks, checkPolicy("policy", home, rigName, ""))
+
+	cells, err := rig.ListCellConfigs(home, rigName)
+	if err != nil {
+		return append(checks, doctorCheck{Name: "cells", Status: doctorFail, Detail: err.Error()})
+	}
+	for _, cell := range cells {
+		checks = append(checks, checkCell(home, rigName, cfg, cell)...)
+	}
+	return checks
+}
+
+func checkBinary(name, bin string, versionArgs ...string) doctorCheck {
+	c := doctorCheck{Name: "binary " + name}
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = bin + " not found on PATH"
+		c.Hint = "install " + bin + " or fix PATH"
+		return c
+	}
+	res, err := util.Run(nil, path, versionArgs...)
+	if err != nil {
+		c.Status = doctorWarn
+		c.Detail = fmt.Sprintf("%s found but `%s %s` failed: %v", path, bin, strings.Join(versionArgs, " "), err)
+		return c
+	}
+	c.Status = doctorOK
+	c.Detail = firstLine(res.Stdout + res.Stderr)
+	return c
+}
+
+func checkRepo(cfg rig.RigConfig) doctorCheck {
+	c := doctorCheck{Name: "repo"}
+	if _, err := util.Run(nil, "git", "-C", cfg.RepoPath, "rev-parse", "--git-dir"); err != nil {
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("%s is not a git repository", cfg.RepoPath)
+		c.Hint = "set repo_path in rig.json to the monorepo root"
+		return c
+	}
+	c.Status = doctorOK
+	c.Detail = cfg.RepoPath
+	return c
+}
+
+func checkRuntimeArgs(cfgPath string) doctorCheck {
+	c := doctorCheck{Name: "runtime args", Status: doctorOK}
+	cfg, err := rig.LoadRigConfig(cfgPath)
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		return c
+	}
+	if normalizeRigRuntime(&cfg) {
+		c.Status = doctorWarn
+		c.Detail = "runtime args carry session flags or miss required flags"
+		c.Repair = func() error {
+			return rig.SaveRigConfig(cfgPath, cfg)
+		}
+	}
+	return c
+}
+
+func checkBeadsBackend(home string, cfg rig.RigConfig) []doctorCheck {
+	client := beadsClient(home, cfg)
+	caps, err := client.ProbeCapabilities(nil)
+	if err != nil {
+		return []doctorCheck{{Name: "beads backend", Status: doctorFail, Detail: err.Error(), Hint: "install bd or set beads_backend to jsonl"}}
+	}
+	detail := caps.Backend
+	if caps.Version != "" {
+		detail += " " + caps.Version
+	}
+	checks := []doctorCheck{{Name: "beads backend", Status: doctorOK, Detail: detail}}
+	if !caps.UpdateDescription {
+		checks[0].Status = doctorWarn
+		checks[0].Detail += " (cannot update descriptions; claims fall back to status only)"
+		checks[0].Hint = "upgrade bd"
+	}
+	if caps.Backend != beads.BackendBD {
+		return checks
+	}
+	types := doctorCheck{Name: "bead custom types", Status: doctorOK, Detail: fmt.Sprintf("%d configured", len(caps.CustomTypes))}
+	// SupportsType treats an unconfigured types.custom as "anything goes";
+	// bd itself then accepts only its built-in types.
+	restricted := caps
+	if restricted.CustomTypes == nil {
+		restricted.CustomTypes = []string{}
+	}
+	var missing []string
+	for _, t := range requiredBeadTypes {
+		if !restricted.SupportsType(t) {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		types.Status = doctorFail
+		types.Detail = "types.custom is missing " + strings.Join(missing, ",")
+		types.Repair = func() error { return ensureBeadsTypes(cfg.RepoPath) }
+	}
+	return append(checks, types)
+}
+
+func checkCell(home, rigName string, cfg rig.RigConfig, cell rig.CellConfig) []doctorCheck {
+	prefix := "cell " + cell.Name + ": "
+	wt := cell.WorktreePath
+	var checks []doctorCheck
+	if wt != rig.CellWorktreeDir(home, rigName, cell.Name) {
+		checks = append(checks, doctorCheck{Name: prefix + "config", Status: doctorWarn, Detail: fmt.Sprintf("worktree_path %s is outside the cell directory", wt)})
+	}
+	tree := doctorCheck{Name: prefix + "worktree", Status: doctorOK, Detail: wt}
+	if _, err := os.Stat(wt); err != nil {
+		tree.Status = doctorFail
+		tree.Detail = wt + " does not exist"
+		tree.Hint = "run `mforge cell bootstrap " + cell.Name + "`"
+		return append(checks, tree)
+	}
+	// A plain directory inside some other checkout would pass
+	// --is-inside-work-tree, so require the worktree to be its own top level.
+	if res, err := util.Run(nil, "git", "-C", wt, "rev-parse", "--show-toplevel"); err != nil || !sameDir(strings.TrimSpace(res.Stdout), wt) {
+		tree.Status = doctorFail
+		tree.Detail = wt + " is not a git worktree"
+		tree.Hint = "move its contents aside and run `mforge cell bootstrap " + cell.Name + "`"
+	}
+	checks = append(checks, tree)
+
+	files := doctorCheck{Name: prefix + "hook config and mail", Status: doctorOK}
+	var missing []string
+	for _, p := range []string{".mf/hooks.json", ".claude/settings.json", "mail/inbox", "mail/outbox", "mail/archive"} {
+		if _, err := os.Stat(filepath.Join(wt, p)); err != nil {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		files.Status = doctorFail
+		files.Detail = "missing " + strings.Join(missing, ", ")
+		files.Repair = func() error { return repairCellFiles(wt) }
+	}
+	checks = append(checks, files)
+	if len(missing) == 0 {
+		checks = append(checks, checkClaudeSettings(prefix, cell.Name, filepath.Join(wt, ".claude", "settings.json")))
+	}
+	checks = append(checks, checkActiveAgent(prefix, rigName, cell))
+	resolved, err := rig.Resolve(cfg, cell)
+	if err != nil {
+		checks = append(checks, doctorCheck{Name: prefix + "overrides", Status: doctorFail, Detail: err.Error(), Hint: "fix the overrides in cell.json or cell_defaults in rig.json"})
+		resolved, _ = rig.Resolve(rig.RigConfig{}, rig.CellConfig{})
+	}
+	if _, err := os.Stat(rig.CellPolicyPath(home, rigName, cell.Name)); err == nil {
+		checks = append(checks, checkPolicy(prefix+"policy", home, rigName, cell.Name))
+	}
+	checks = append(checks, checkGuardPaths(prefix, home, rigName, cell))
+	if len(resolved.Env) > 0 {
+		env := map[string]string{}
+		for k, v := range resolved.Env {
+			env["env "+k] = v
+		}
+		checks = append(checks, checkSecrets(home, prefix+"secrets", env))
+	}
+	checks = append(checks, checkHeartbeats(home, rigName, cell.Name, cfg.RoleNames(), resolved.StaleAfter)...)
+	return checks
+}
+
+// checkPolicy loads the guardrail policy the way GuardrailsHook does; an
+// invalid file makes guardrails deny every tool call.
+func checkPolicy(name, home, rigName, cellName string) doctorCheck {
+	c := doctorCheck{Name: name, Status: doctorOK}
+	p, err := hooks.LoadPolicy(home, rigName, cellName)
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		c.Hint = "guardrails deny every tool call until the policy file is fixed"
+		return c
+	}
+	c.Detail = fmt.Sprintf("%d rule(s)", len(p.Rules))
+	return c
+}
+
+// checkGuardPaths warns when a cell predates the protected and read_deny
+// lists; control paths are protected either way.
+func checkGuardPaths(prefix, home, rigName string, cell rig.CellConfig) doctorCheck {
+	c := doctorCheck{Name: prefix + "protected paths", Status: doctorOK}
+	c.Detail = fmt.Sprintf("%d protected, %d read_deny", len(cell.Protected), len(cell.ReadDeny))
+	if cell.Protected != nil && cell.ReadDeny != nil {
+		return c
+	}
+	c.Status = doctorWarn
+	c.Detail = "cell.json has no protected or read_deny list; agents can read .env files, keys, and other cells' worktrees"
+	c.Hint = "run `mforge cell bootstrap " + cell.Name + "` or `mforge doctor --fix` to add the defaults"
+	c.Repair = func() error {
+		applyGuardDefaults(home, rigName, &cell)
+		return rig.SaveCellConfig(rig.CellConfigPath(home, rigName, cell.Name), cell)
+	}
+	return c
+}
+
+// checkSecrets fails when a secret reference does not resolve and warns
+// when a credential-looking value is stored in plain text.
+func checkSecrets(home, name string, values map[string]string) doctorCheck {
+	c := doctorCheck{Name: name, Status: doctorOK}
+	var broken, plain []string
+	for field, v := range values {
+		if rig.IsSecretRef(v) {
+			if _, err := rig.ResolveSecret(home, v); err != nil {
+				broken = append(broken, err.Error())
+			}
+			continue
+		}
+		if looksSecret(field) {
+			plain = append(plain, field)
+		}
+	}
+	sort.Strings(broken)
+	sort.Strings(plain)
+	switch {
+	case len(broken) > 0:
+		c.Status = doctorFail
+		c.Detail = strings.Join(broken, "; ")
+		c.Hint = "set the variable, create the file, or `mforge secret set <name>` with " + rig.SecretsPassphraseEnv + " exported"
+	case len(plain) > 0:
+		c.Status = doctorWarn
+		c.Detail = "plaintext " + strings.Join(plain, ", ")
+		c.Hint = "replace with env:NAME, file:PATH, or secret:NAME"
+	default:
+		c.Detail = fmt.Sprintf("%d value(s) checked", len(values))
+	}
+	return c
+}
+
+func looksSecret(field string) bool {
+	f := strings.ToUpper(field)
+	for _, word := range []string{"TOKEN", "SECRET", "PASSWORD", "API_KEY", "ACCESS_KEY", "CREDENTIAL"} {
+		if strings.Contains(f, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkClaudeSettings validates the shape Claude Code expects and that the
+// microforge stop and guardrail hooks are wired.
+func checkClaudeSettings(prefix, cellName, path string) doctorCheck {
+	c := doctorCheck{Name: prefix + "claude settings", Status: doctorOK, Hint: "run `mforge cell bootstrap " + cellName + "` to regenerate it"}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		c.Status = doctorFail
+		c.Detail = err.Error()
+		return c
+	}
+	var settings struct {
+		Permissions struct {
+			Allow []string `json:"allow"`
+		} `json:"permissions"`
+		Hooks map[string][]struct {
+			Matcher string `json:"matcher"`
+			Hooks   []struct {
+				Type    string `json:"type"`
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		c.Status = doctorFail
+		c.Detail = "invalid settings.json: " + err.Error()
+		return c
+	}
+	var problems []string
+	commands := map[string]bool{}
+	guardAll := false
+	for event, entries := range settings.Hooks {
+		if !claudeHookEvents[event] {
+			problems = append(problems, "unknown hook event "+event)
+		}
+		for _, entry := range entries {
+			if len(entry.Hooks) == 0 {
+				problems = append(problems, event+" entry has no hooks")
+			}
+			for _, h := range entry.Hooks {
+				if h.Type != "command" || strings.TrimSpace(h.Command) == "" {
+					problems = append(problems, event+" hook needs type \"command\" and a command")
+					continue
+				}
+				commands[event+"|"+hookVerb(h.Command)] = true
+				if event == "PreToolUse" && hookVerb(h.Command) == "hook guardrails" && (entry.Matcher == "*" || entry.Matcher == "") {
+					guardAll = true
+				}
+			}
+		}
+	}
+	if !commands["Stop|hook stop"] {
+		problems = append(problems, "Stop does not run `mforge hook stop`")
+	}
+	if !commands["PreToolUse|hook guardrails"] {
+		problems = append(problems, "PreToolUse does not run `mforge hook guardrails`")
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		c.Status = doctorFail
+		c.Detail = strings.Join(problems, "; ")
+		return c
+	}
+	if !guardAll {
+		c.Status = doctorWarn
+		c.Detail = "guardrails do not run for every tool, so Bash and tool policy rules are skipped"
+		c.Hint = "re-run `mforge cell bootstrap " + cellName + "` to rewrite settings.json"
+	}
+	return c
+}
+
+// hookVerb returns "hook <verb>" from an mforge hook command line.
+func hookVerb(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) >= 3 && fields[1] == "hook" {
+		return "hook " + fields[2]
+	}
+	return ""
+}
+
+// checkActiveAgent verifies .mf/active-agent.json belongs to this rig and
+// cell and matches its role's identity file; --fix copies the role file back.
+func checkActiveAgent(prefix, rigName string, cell rig.CellConfig) doctorCheck {
+	c := doctorCheck{Name: prefix + "active agent", Status: doctorOK}
+	path := filepath.Join(cell.WorktreePath, ".mf", "active-agent.json")
+	identity, err := hooks.LoadIdentityFromCWD(cell.WorktreePath)
+	role := identity.Role
+	if err != nil || strings.TrimSpace(role) == "" {
+		role = defaultCellRole(cell.WorktreePath)
+	}
+	source := filepath.Join(cell.WorktreePath, ".mf", "active-agent-"+role+".json")
+	want, srcErr := os.ReadFile(source)
+	repair := func() error {
+		if srcErr != nil {
+			return srcErr
+		}
+		return util.AtomicWriteFile(path, want, 0o644)
+	}
+	switch {
+	case err != nil:
+		c.Status = doctorFail
+		c.Detail = err.Error()
+	case identity.RigName != rigName || identity.CellName != cell.Name:
+		c.Status = doctorFail
+		c.Detail = fmt.Sprintf("points at %s/%s", identity.RigName, identity.CellName)
+	case filepath.Clean(identity.Worktree) != filepath.Clean(cell.WorktreePath):
+		c.Status = doctorFail
+		c.Detail = "worktree_path " + identity.Worktree + " does not match cell.json"
+	case srcErr == nil && !sameIdentity(want, identity):
+		c.Status = doctorWarn
+		c.Detail = "stale copy of active-agent-" + role + ".json"
+	default:
+		c.Detail = role
+		return c
+	}
+	if srcErr == nil {
+		c.Repair = repair
+	} else {
+		c.Hint = "run `mforge cell bootstrap " + cell.Name + "`"
+	}
+	return c
+}
+
+func defaultCellRole(worktree string) string {
+	if _, err := os.Stat(filepath.Join(worktree, ".mf", "active-agent-builder.json")); err == nil {
+		return "builder"
+	}
+	return "cell"
+}
+
+func sameIdentity(raw []byte, identity hooks.AgentIdentity) bool {
+	raw, _, _, err := rig.MigrateBytes(rig.SchemaAgent, "", raw)
+	if err != nil {
+		return false
+	}
+	var want hooks.AgentIdentity
+	if err := json.Unmarshal(raw, &want); err != nil {
+		return false
+	}
+	return want == identity
+}
+
+func checkHeartbeats(home, rigName, cellName string, roles []string, staleAfter time.Duration) []doctorCheck {
+	var checks []doctorCheck
+	now := time.Now().UTC()
+	for _, role := range roles {
+		hb := readHeartbeat(agentObsDir(home, rigName, cellName, role))
+		ts, err := time.Parse(time.RFC3339, strings.TrimSpace(hb.Timestamp))
+		if err != nil {
+			continue
+		}
+		c := doctorCheck{Name: "cell " + cellName + ": heartbeat " + role, Status: doctorOK}
+		age := now.Sub(ts).Round(time.Second)
+		c.Detail = fmt.Sprintf("%s, %s ago", hb.Status, age)
+		if age > staleAfter && !strings.EqualFold(hb.Status, "idle") {
+			c.Status = doctorWarn
+			c.Hint = fmt.Sprintf("check the agent with `mforge agent logs %s %s`", cellName, role)
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func sameDir(a, b string) bool {
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return ra == rb
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
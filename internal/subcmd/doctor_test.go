@@ -0,0 +1,56 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+func TestDoctorRepairsCellFilesAndActiveAgent(t *testing.T) {
+	home := t.TempDir()
+	wt := rig.CellWorktreeDir(home, "rig", "api")
+	if err := os.MkdirAll(filepath.Join(wt, ".mf"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	builder := `{"rig_name":"rig","cell_name":"api","role":"builder","worktree_path":"` + wt + `"}`
+	if err := os.WriteFile(filepath.Join(wt, ".mf", "active-agent-builder.json"), []byte(builder), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stale := `{"rig_name":"old","cell_name":"api","role":"builder","worktree_path":"/gone"}`
+	if err := os.WriteFile(filepath.Join(wt, ".mf", "active-agent.json"), []byte(stale), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cell := rig.CellConfig{Name: "api", WorktreePath: wt}
+
+	byName := func(checks []doctorCheck) map[string]doctorCheck {
+		out := map[string]doctorCheck{}
+		for _, c := range checks {
+			out[strings.TrimPrefix(c.Name, "cell api: ")] = c
+		}
+		return out
+	}
+	checks := byName(checkCell(home, "rig", rig.RigConfig{}, cell))
+	for _, name := range []string{"hook config and mail", "active agent"} {
+		c := checks[name]
+		if c.Status != doctorFail || c.Repair == nil {
+			t.Fatalf("%s: expected a repairable failure, got %+v", name, c)
+		}
+		if err := c.Repair(); err != nil {
+			t.Fatalf("%s repair: %v", name, err)
+		}
+	}
+	checks = byName(checkCell(home, "rig", rig.RigConfig{}, cell))
+	if c := checks["hook config and mail"]; c.Status != doctorOK {
+		t.Fatalf("expected cell files repaired, got %+v", c)
+	}
+	if c := checks["active agent"]; c.Status != doctorOK {
+		t.Fatalf("expected active agent repaired, got %+v", c)
+	}
+	// migrate rig writes settings without hooks; doctor flags the missing wiring.
+	if c := checks["claude settings"]; c.Status != doctorFail || !strings.Contains(c.Detail, "hook stop") {
+		t.Fatalf("expected missing hook wiring, got %+v", c)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -110,7 +109,7 @@ func engineEmit(home, rigName string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Kind: eventType, Scope: scope, Title: title, SourceRole: source}
 	body := "{}"
 	if len(payload) > 0 {
@@ -146,7 +145,7 @@ func engineDrain(home, rigName string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -160,7 +159,7 @@ func engineDrain(home, rigName string, args []string) error {
 		ev := parseEngineEvent(issue)
 		fmt.Printf("%s\t%s\t%s\n", issue.ID, ev.Type, ev.Title)
 		if !keep {
-			_, _ = client.Close(nil, issue.ID, "event drained")
+			client.TryClose(nil, issue.ID, "event drained")
 		}
 	}
 	return nil
@@ -203,7 +202,7 @@ func engineRun(home, rigName string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -215,15 +214,14 @@ func engineRun(home, rigName string, args []string) error {
 	if err != nil {
 		return err
 	}
-	cellByScope := buildScopeIndex(cells)
 	for _, issue := range intake {
 		ev := parseEngineEvent(issue)
-		cmds, err := planFromEvent(home, rigName, client, cellByScope, ev)
+		cmds, err := planFromEvent(home, rigName, client, cells, ev)
 		if err != nil {
 			return err
 		}
 		planned = append(planned, cmds...)
-		_, _ = client.Close(nil, issue.ID, "event consumed")
+		client.TryClose(nil, issue.ID, "event consumed")
 	}
 	fmt.Printf("Plan: %d command(s)\n", len(planned))
 	for _, cmd := range planned {
@@ -251,6 +249,7 @@ func engineRunRounds(home, rigName string, rounds int, completionPromise string,
 		maxRounds = 1000
 	}
 	for i := 1; i <= maxRounds; i++ {
+		ResetBeadsCache()
 		startArgs := []string{"start", rigName}
 		if wait {
 			startArgs = append(startArgs, "--wait")
@@ -299,7 +298,7 @@ func completionPromiseMet(home, rigName, token string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return false, err
@@ -390,7 +389,7 @@ func parseEngineEvent(issue beads.Issue) engineEvent {
 	}
 }
 
-func planFromEvent(home, rigName string, client beads.Client, cellByScope map[string]string, ev engineEvent) ([]engineCommand, error) {
+func planFromEvent(home, rigName string, client beads.Client, cells []rig.CellConfig, ev engineEvent) ([]engineCommand, error) {
 	kind := eventKind(ev.Type)
 	role := defaultRole(ev.Type)
 	if v, ok := ev.Payload["role"].(string); ok && strings.TrimSpace(v) != "" {
@@ -401,7 +400,9 @@ func planFromEvent(home, rigName string, client beads.Client, cellByScope map[st
 		cell = v
 	}
 	if cell == "" {
-		cell = pickCellForScope(cellByScope, ev.Scope)
+		if match := matchCellByScope(cells, ev.Scope); match != nil {
+			cell = match.Name
+		}
 	}
 	if cell == "" {
 		return nil, fmt.Errorf("no cell match for scope %q", ev.Scope)
@@ -459,33 +460,3 @@ func defaultRole(eventType string) string {
 		return "builder"
 	}
 }
-
-func buildScopeIndex(cells []rig.CellConfig) map[string]string {
-	index := map[string]string{}
-	for _, cell := range cells {
-		scope := strings.TrimSpace(cell.ScopePrefix)
-		if scope == "" {
-			continue
-		}
-		index[scope] = cell.Name
-	}
-	return index
-}
-
-func pickCellForScope(index map[string]string, scope string) string {
-	scope = strings.TrimSpace(scope)
-	if scope == "" {
-		return ""
-	}
-	keys := make([]string, 0, len(index))
-	for k := range index {
-		keys = append(keys, k)
-	}
-	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
-	for _, prefix := range keys {
-		if strings.HasPrefix(scope, prefix) {
-			return index[prefix]
-		}
-	}
-	return ""
-}
@@ -51,7 +51,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		meta := beads.Meta{Title: title, Kind: "epic", ShortID: strings.TrimSpace(shortID)}
 		desc := beads.RenderMeta(meta)
 		if strings.TrimSpace(body) != "" {
@@ -97,7 +97,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		if err := client.DepAdd(nil, taskID, "related:"+epicID); err != nil {
 			return err
 		}
@@ -126,19 +126,16 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, err := client.List(nil)
 		if err != nil {
 			return err
 		}
 		counts := map[string]int{}
-		for _, issue := range issues {
+		for _, issue := range beads.NewGraph(issues).Children(epicID) {
 			if strings.ToLower(issue.Type) != "task" {
 				continue
 			}
-			if !hasDep(issue, epicID) {
-				continue
-			}
 			counts[issue.Status]++
 		}
 		for status, count := range counts {
@@ -174,7 +171,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, err := client.List(nil)
 		if err != nil {
 			return err
@@ -188,14 +185,11 @@ func Epic(home string, args []string) error {
 			turnID = strings.TrimSpace(state.ID)
 		}
 		assigned := 0
-		for _, issue := range issues {
+		for _, issue := range beads.NewGraph(issues).Children(epicID) {
 			if strings.ToLower(issue.Type) != "task" {
 				continue
 			}
-			if issue.Status == "closed" || issue.Status == "done" {
-				continue
-			}
-			if !hasDep(issue, epicID) {
+			if beads.IsClosed(issue.Status) {
 				continue
 			}
 			meta := beads.ParseMeta(issue.Description)
@@ -253,19 +247,16 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, err := client.List(nil)
 		if err != nil {
 			return err
 		}
 		hasReview := false
-		for _, issue := range issues {
+		for _, issue := range beads.NewGraph(issues).Children(epicID) {
 			if strings.ToLower(issue.Type) != "task" {
 				continue
 			}
-			if !hasDep(issue, epicID) {
-				continue
-			}
 			if issue.Status != "closed" && issue.Status != "done" {
 				return fmt.Errorf("epic has incomplete tasks")
 			}
@@ -316,7 +307,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		payload := fmt.Sprintf("{\"epic\":%q,\"details\":%q}", epicID, details)
 		meta := beads.Meta{Cell: cellName, SourceRole: "reviewer"}
 		_, err = client.Create(nil, beads.CreateRequest{
@@ -342,7 +333,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, err := client.List(nil)
 		if err != nil {
 			return err
@@ -391,7 +382,7 @@ func Epic(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issues, err := client.List(nil)
 		if err != nil {
 			return err
@@ -405,19 +396,34 @@ func Epic(home string, args []string) error {
 			label = fmt.Sprintf("%s (%s)", epic.ID, meta.ShortID)
 		}
 		fmt.Printf("Epic %s: %s [%s]\n", label, epic.Title, epic.Status)
-		for _, issue := range issues {
-			if strings.ToLower(issue.Type) != "task" {
-				continue
-			}
-			if !hasDep(issue, epic.ID) {
-				continue
+		graph := beads.NewGraph(issues)
+		ids := []string{}
+		for _, issue := range graph.Children(epic.ID) {
+			if strings.ToLower(issue.Type) == "task" {
+				ids = append(ids, issue.ID)
 			}
+		}
+		ordered, err := graph.TopoOrder(ids)
+		if err != nil {
+			fmt.Printf("  warning: %v\n", err)
+			ordered = ids
+		}
+		for _, id := range ordered {
+			issue, _ := graph.Issue(id)
 			assn := findAssignmentForTask(issues, issue.ID)
 			assnStatus := "-"
 			if assn.ID != "" {
 				assnStatus = assn.Status
 			}
-			fmt.Printf("  - %s [%s] (assignment=%s)\n", issue.ID, issue.Status, assnStatus)
+			line := fmt.Sprintf("  - %s [%s] (assignment=%s)", issue.ID, issue.Status, assnStatus)
+			if blockers := graph.Blockers(issue.ID); len(blockers) > 0 {
+				blockerIDs := make([]string, 0, len(blockers))
+				for _, b := range blockers {
+					blockerIDs = append(blockerIDs, b.ID)
+				}
+				line += " blocked by " + strings.Join(blockerIDs, ", ")
+			}
+			fmt.Println(line)
 		}
 		return nil
 
@@ -455,37 +461,13 @@ func findAssignmentForTask(issues []beads.Issue, taskID string) beads.Issue {
 		if strings.ToLower(issue.Type) != "assignment" {
 			continue
 		}
-		for _, dep := range issue.Deps {
-			if dep == "related:"+taskID {
-				return issue
-			}
+		if beads.HasDep(issue.Deps, beads.EdgeRelated, taskID) {
+			return issue
 		}
 	}
 	return beads.Issue{}
 }
 
-func hasDep(issue beads.Issue, id string) bool {
-	for _, dep := range issue.Deps {
-		if strings.Contains(dep, id) {
-			return true
-		}
-	}
-	return false
-}
-
 func matchCellByScope(cells []rig.CellConfig, scope string) *rig.CellConfig {
-	scope = strings.TrimSpace(scope)
-	if scope == "" {
-		return nil
-	}
-	var best *rig.CellConfig
-	for i := range cells {
-		c := cells[i]
-		if strings.HasPrefix(scope, c.ScopePrefix) {
-			if best == nil || len(c.ScopePrefix) > len(best.ScopePrefix) {
-				best = &c
-			}
-		}
-	}
-	return best
+	return rig.MatchCell(cells, strings.TrimSpace(scope))
 }
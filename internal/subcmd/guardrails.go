@@ -0,0 +1,171 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
+)
+
+// Guardrails reports on the rig's guardrail audit log.
+func Guardrails(home string, args []string) error {
+	if len(args) < 2 || args[0] != "report" {
+		return fmt.Errorf("usage: mforge guardrails report [--since <dur>] [--cell <cell>] [--role <role>] [--recent <n>] [--json]")
+	}
+	rigName := args[1]
+	var filter guardrailFilter
+	recent := 5
+	jsonOut := false
+	rest := args[2:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--json":
+			jsonOut = true
+		case "--since", "--cell", "--role", "--recent":
+			if i+1 >= len(rest) {
+				return fmt.Errorf("%s requires a value", rest[i])
+			}
+			val := rest[i+1]
+			switch rest[i] {
+			case "--since":
+				d, err := beads.ParseDuration(val)
+				if err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+				filter.Since = time.Now().UTC().Add(-d)
+			case "--cell":
+				filter.Cell = val
+			case "--role":
+				filter.Role = val
+			case "--recent":
+				n, err := strconv.Atoi(val)
+				if err != nil || n < 0 {
+					return fmt.Errorf("--recent must be a non-negative number")
+				}
+				recent = n
+			}
+			i++
+		}
+	}
+	records, err := hooks.ReadGuardrailAudit(home, rigName)
+	if err != nil {
+		return err
+	}
+	report := summarizeGuardrails(records, filter, recent)
+	if jsonOut {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	fmt.Printf("Audit log: %s\n", rig.GuardrailsAuditPath(home, rigName))
+	fmt.Printf("Decisions: %d  denied: %d\n", report.Decisions, report.Denials)
+	if report.Denials == 0 {
+		return nil
+	}
+	printDenialCounts("By cell", report.ByCell)
+	printDenialCounts("By role", report.ByRole)
+	printDenialCounts("By rule", report.ByRule)
+	printDenialCounts("By agent", report.ByAgent)
+	if len(report.Recent) > 0 {
+		fmt.Println("Recent denials:")
+		for _, r := range report.Recent {
+			fmt.Printf("  %s %s/%s %s %s: %s\n", r.Timestamp, r.Cell, r.Role, r.Tool, r.Rule, r.Input)
+		}
+	}
+	return nil
+}
+
+type guardrailFilter struct {
+	Since time.Time
+	Cell  string
+	Role  string
+}
+
+type denialCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+type guardrailReport struct {
+	Decisions int                     `json:"decisions"`
+	Denials   int                     `json:"denials"`
+	ByCell    []denialCount           `json:"by_cell"`
+	ByRole    []denialCount           `json:"by_role"`
+	ByRule    []denialCount           `json:"by_rule"`
+	ByAgent   []denialCount           `json:"by_agent"`
+	Recent    []hooks.GuardrailRecord `json:"recent"`
+}
+
+// summarizeGuardrails counts decisions matching filter and groups the
+// denials by cell, role, rule, and agent (cell/role), most frequent first.
+func summarizeGuardrails(records []hooks.GuardrailRecord, filter guardrailFilter, recent int) guardrailReport {
+	var report guardrailReport
+	byCell, byRole, byRule, byAgent := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	var denied []hooks.GuardrailRecord
+	for _, r := range records {
+		if filter.Cell != "" && r.Cell != filter.Cell {
+			continue
+		}
+		if filter.Role != "" && r.Role != filter.Role {
+			continue
+		}
+		if !filter.Since.IsZero() {
+			ts, err := time.Parse(time.RFC3339, r.Timestamp)
+			if err != nil || ts.Before(filter.Since) {
+				continue
+			}
+		}
+		report.Decisions++
+		if r.Decision != "deny" {
+			continue
+		}
+		report.Denials++
+		rule := r.Rule
+		if rule == "" {
+			rule = "-"
+		}
+		byCell[r.Cell]++
+		byRole[r.Role]++
+		byRule[rule]++
+		byAgent[r.Cell+"/"+r.Role]++
+		denied = append(denied, r)
+	}
+	report.ByCell = sortedDenialCounts(byCell)
+	report.ByRole = sortedDenialCounts(byRole)
+	report.ByRule = sortedDenialCounts(byRule)
+	report.ByAgent = sortedDenialCounts(byAgent)
+	if recent > len(denied) {
+		recent = len(denied)
+	}
+	report.Recent = denied[len(denied)-recent:]
+	return report
+}
+
+func sortedDenialCounts(m map[string]int) []denialCount {
+	out := make([]denialCount, 0, len(m))
+	for k, v := range m {
+		out = append(out, denialCount{Key: k, Count: v})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+func printDenialCounts(title string, counts []denialCount) {
+	fmt.Println(title + ":")
+	for _, c := range counts {
+		fmt.Printf("  %-32s %d\n", c.Key, c.Count)
+	}
+}
@@ -0,0 +1,40 @@
+package subcmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/microforge/internal/hooks"
+)
+
+func TestSummarizeGuardrails(t *testing.T) {
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour).Format(time.RFC3339)
+	ts := now.Format(time.RFC3339)
+	records := []hooks.GuardrailRecord{
+		{Timestamp: old, Cell: "api", Role: "builder", Decision: "deny", Rule: "scope"},
+		{Timestamp: ts, Cell: "api", Role: "builder", Decision: "allow"},
+		{Timestamp: ts, Cell: "api", Role: "builder", Decision: "deny", Rule: "scope"},
+		{Timestamp: ts, Cell: "api", Role: "builder", Decision: "deny", Rule: "default:no-force-push"},
+		{Timestamp: ts, Cell: "web", Role: "reviewer", Decision: "deny", Rule: "read-only"},
+		{Timestamp: ts, Cell: "web", Role: "builder", Decision: "deny", Rule: "scope"},
+	}
+	r := summarizeGuardrails(records, guardrailFilter{Since: now.Add(-time.Hour)}, 2)
+	if r.Decisions != 5 || r.Denials != 4 {
+		t.Fatalf("got %d decisions, %d denials", r.Decisions, r.Denials)
+	}
+	if r.ByRule[0] != (denialCount{Key: "scope", Count: 2}) {
+		t.Fatalf("by rule: %+v", r.ByRule)
+	}
+	if r.ByAgent[0] != (denialCount{Key: "api/builder", Count: 2}) {
+		t.Fatalf("by agent: %+v", r.ByAgent)
+	}
+	if len(r.Recent) != 2 || r.Recent[1].Cell != "web" || r.Recent[1].Role != "builder" {
+		t.Fatalf("recent: %+v", r.Recent)
+	}
+
+	r = summarizeGuardrails(records, guardrailFilter{Cell: "web", Role: "reviewer"}, 5)
+	if r.Denials != 1 || r.ByRule[0].Key != "read-only" {
+		t.Fatalf("filtered: %+v", r)
+	}
+}
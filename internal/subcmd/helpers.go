@@ -6,12 +6,75 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/context"
+	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/util"
 )
 
+var (
+	beadsClientsMu sync.Mutex
+	beadsClients   = map[string]beads.Client{}
+)
+
+// beadsClient returns the issue client for a rig, honoring beads_backend in rig.json.
+// Clients are shared for the lifetime of the invocation and wrap the backend in a
+// write-through snapshot cache, so repeated listings do not re-run `bd list`.
+func beadsClient(home string, cfg rig.RigConfig) beads.Client {
+	path := cfg.BeadsPath
+	if strings.TrimSpace(path) == "" {
+		path = rig.BeadsStorePath(home, cfg.Name)
+	}
+	key := cfg.BeadsBackend + "|" + cfg.RepoPath + "|" + path
+	beadsClientsMu.Lock()
+	defer beadsClientsMu.Unlock()
+	if client, ok := beadsClients[key]; ok {
+		return client
+	}
+	client, err := beads.Open(cfg.BeadsBackend, cfg.RepoPath, path)
+	if err != nil {
+		// LoadRigConfig rejects unknown backends; only hand-built configs land here.
+		client = beads.Client{RepoPath: cfg.RepoPath, Store: beads.BDStore{RepoPath: cfg.RepoPath}}
+	}
+	if bd, ok := client.Store.(beads.BDStore); ok {
+		bd.CachePath = rig.BeadsCapabilitiesPath(home, cfg.Name)
+		client.Store = bd
+	}
+	client.Store = beads.NewCachedStore(client.Store)
+	client.HistoryLog = &beads.HistoryLog{Path: rig.BeadsHistoryPath(home, cfg.Name)}
+	client.Failures = &beads.FailureLog{Path: rig.BeadsFailuresPath(home, cfg.Name)}
+	client.Actor = callerActor()
+	client = client.MemoizeCapabilities()
+	beadsClients[key] = client
+	return client
+}
+
+// callerActor names whoever is running mforge for comments and status
+// history: the agent's cell/role when run from an agent worktree, otherwise
+// the local user.
+func callerActor() string {
+	if cwd, err := os.Getwd(); err == nil {
+		if identity, err := hooks.LoadIdentityFromCWD(cwd); err == nil && identity.CellName != "" {
+			return hooks.ClaimOwner(identity)
+		}
+	}
+	if user := strings.TrimSpace(os.Getenv("USER")); user != "" {
+		return "human:" + user
+	}
+	return "human"
+}
+
+// ResetBeadsCache drops the per-invocation beads snapshots so the next command
+// starts from the backend's current state.
+func ResetBeadsCache() {
+	beadsClientsMu.Lock()
+	beadsClients = map[string]beads.Client{}
+	beadsClientsMu.Unlock()
+}
+
 func defaultIfEmpty(val, def string) string {
 	if strings.TrimSpace(val) == "" {
 		return def
@@ -19,7 +82,17 @@ func defaultIfEmpty(val, def string) string {
 	return val
 }
 
-func writeAssignmentInbox(worktree, inboxRel, outboxRel, promise string, issue beads.Issue) (string, error) {
+// issueThread renders a bead's comment thread for inbox mail. Backends
+// without comments yield an empty thread.
+func issueThread(client beads.Client, id string) string {
+	comments, err := client.Comments(nil, id)
+	if err != nil {
+		return ""
+	}
+	return beads.FormatThread(comments)
+}
+
+func writeAssignmentInbox(worktree, inboxRel, outboxRel, promise string, issue beads.Issue, thread string) (string, error) {
 	if strings.TrimSpace(worktree) == "" {
 		return "", nil
 	}
@@ -33,6 +106,9 @@ func writeAssignmentInbox(worktree, inboxRel, outboxRel, promise string, issue b
 	if body == "" {
 		body = "_(no additional body provided)_"
 	}
+	if strings.TrimSpace(thread) != "" {
+		body += "\n\n# Discussion\n" + strings.TrimSpace(thread)
+	}
 	metaLines := []string{
 		"---",
 		"task_id: " + issue.ID,
@@ -59,8 +135,7 @@ func writeAssignmentInbox(worktree, inboxRel, outboxRel, promise string, issue b
 	return mail, util.AtomicWriteFile(inboxAbs, []byte(mail), 0o644)
 }
 
-func ensureAgentBead(repo string, spec AgentSpec) (string, error) {
-	client := beads.Client{RepoPath: repo}
+func ensureAgentBead(client beads.Client, spec AgentSpec) (string, error) {
 	issues, err := client.List(nil)
 	if err != nil {
 		return "", err
@@ -198,11 +273,10 @@ func createMailBead(client beads.Client, meta beads.Meta, title, body string, de
 	return err
 }
 
-func emitOrchestrationEvent(repo string, meta beads.Meta, title string, deps []string) {
-	if strings.TrimSpace(repo) == "" {
+func emitOrchestrationEvent(client beads.Client, meta beads.Meta, title string, deps []string) {
+	if strings.TrimSpace(client.RepoPath) == "" && client.Store == nil {
 		return
 	}
-	client := beads.Client{RepoPath: repo}
 	if strings.TrimSpace(meta.Kind) == "" {
 		meta.Kind = "orchestration"
 	}
@@ -217,13 +291,17 @@ func emitOrchestrationEvent(repo string, meta beads.Meta, title string, deps []s
 	})
 }
 
+// requiredBeadTypes are the bead types microforge creates; bd must list them
+// in types.custom.
+var requiredBeadTypes = []string{
+	"assignment", "plan", "improve", "fix", "review", "monitor", "doc",
+	"turn", "epic", "event", "request", "observation", "decision",
+	"contract", "pr", "build", "deploy", "task",
+	"agent", "role", "mailbox", "hook", "convoy", "mail",
+}
+
 func ensureBeadsTypes(repo string) error {
-	required := []string{
-		"assignment", "plan", "improve", "fix", "review", "monitor", "doc",
-		"turn", "epic", "event", "request", "observation", "decision",
-		"contract", "pr", "build", "deploy", "task",
-		"agent", "role", "mailbox", "hook", "convoy", "mail",
-	}
+	required := requiredBeadTypes
 	configPath := filepath.Join(repo, ".beads", "config.yaml")
 	b, err := os.ReadFile(configPath)
 	if err != nil {
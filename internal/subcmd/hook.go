@@ -10,11 +10,12 @@ import (
 
 	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
 )
 
 func Hook(home string, args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: mforge hook <stop|guardrails|emit> ...")
+		return fmt.Errorf("usage: mforge hook <stop|guardrails|heartbeat|emit> ...")
 	}
 	op := args[0]
 	rest := args[1:]
@@ -37,13 +38,20 @@ func Hook(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: identity.RepoPath}
+		client := identityBeadsClient(home, identity)
 		resp, err := hooks.StopHook(context.Background(), client, identity)
 		if err != nil {
 			return err
 		}
 		return json.NewEncoder(os.Stdout).Encode(resp)
 
+	case "heartbeat":
+		identity, err := hooks.LoadIdentityFromCWD(cwd)
+		if err != nil {
+			return err
+		}
+		return hooks.Heartbeat(context.Background(), identityBeadsClient(home, identity), identity)
+
 	case "guardrails":
 		identity, err := hooks.LoadIdentityFromCWD(cwd)
 		if err != nil {
@@ -53,6 +61,9 @@ func Hook(home string, args []string) error {
 		if err != nil {
 			return err
 		}
+		if err := hooks.AuditGuardrailDecision(context.Background(), identityBeadsClient(home, identity), identity, in, dec); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: guardrail audit log: %v\n", err)
+		}
 		return json.NewEncoder(os.Stdout).Encode(dec)
 
 	case "emit":
@@ -83,3 +94,18 @@ func Hook(home string, args []string) error {
 		return fmt.Errorf("unknown hook subcommand: %s", op)
 	}
 }
+
+// identityBeadsClient resolves the rig's beads backend for a hook, falling back
+// to bd in the agent's repo when the rig config is unavailable.
+func identityBeadsClient(home string, identity hooks.AgentIdentity) beads.Client {
+	if strings.TrimSpace(identity.RigHome) != "" {
+		home = identity.RigHome
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, identity.RigName))
+	if err != nil {
+		return beads.Client{RepoPath: identity.RepoPath}
+	}
+	client := beadsClient(home, cfg)
+	client.Actor = hooks.ClaimOwner(identity)
+	return client
+}
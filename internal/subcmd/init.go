@@ -13,39 +13,58 @@ import (
 
 func Init(home string, args []string) error {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: mforge init <rig> --repo <path>")
+		return fmt.Errorf("usage: mforge init <rig> --repo <path> [--beads bd|jsonl]")
 	}
 	rigName := args[0]
 	repo := ""
+	backend := ""
 	for i := 1; i < len(args); i++ {
-		if args[i] == "--repo" && i+1 < len(args) {
-			repo = args[i+1]
-			i++
+		switch args[i] {
+		case "--repo":
+			if i+1 < len(args) {
+				repo = args[i+1]
+				i++
+			}
+		case "--beads":
+			if i+1 < len(args) {
+				backend = args[i+1]
+				i++
+			}
 		}
 	}
 	if strings.TrimSpace(repo) == "" {
 		return fmt.Errorf("--repo is required")
 	}
+	if !beads.ValidBackend(backend) {
+		return fmt.Errorf("unknown beads backend %q (want bd or jsonl)", backend)
+	}
 	rdir := rig.RigDir(home, rigName)
 	if err := util.EnsureDir(rdir); err != nil {
 		return err
 	}
 	cfg := rig.DefaultRigConfig(rigName, repo)
+	cfg.BeadsBackend = backend
 	if err := rig.SaveRigConfig(rig.RigConfigPath(home, rigName), cfg); err != nil {
 		return err
 	}
 
-	client := beads.Client{RepoPath: repo}
+	client := beadsClient(home, cfg)
 	if err := client.Init(nil); err != nil {
 		return err
 	}
-	if err := ensureBeadsTypes(repo); err != nil {
-		return err
+	if backend != beads.BackendJSONL {
+		if err := ensureBeadsTypes(repo); err != nil {
+			return err
+		}
 	}
 
 	_ = util.EnsureDir(filepath.Join(rdir, "cells"))
 	fmt.Printf("Initialized rig %q at %s\n", rigName, rdir)
-	fmt.Printf("Beads repo: %s\n", filepath.Join(repo, ".beads"))
+	if backend == beads.BackendJSONL {
+		fmt.Printf("Beads store: %s\n", rig.BeadsStorePath(home, rigName))
+	} else {
+		fmt.Printf("Beads repo: %s\n", filepath.Join(repo, ".beads"))
+	}
 	warnDuplicateRepo(home, repo, rigName)
 	return nil
 }
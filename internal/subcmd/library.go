@@ -41,7 +41,11 @@ func Library(home string, args []string) error {
 		if addr == "" {
 			addr = cfg.LibraryAddr
 		}
-		lib, err := library.New(library.Config{Docs: cfg.LibraryDocs, Context7URL: cfg.LibraryContext7URL, Context7Token: cfg.LibraryContext7Token})
+		token, err := rig.ResolveSecret(home, cfg.LibraryContext7Token)
+		if err != nil {
+			return fmt.Errorf("library_context7_token: %w", err)
+		}
+		lib, err := library.New(library.Config{Docs: cfg.LibraryDocs, Context7URL: cfg.LibraryContext7URL, Context7Token: token})
 		if err != nil {
 			return err
 		}
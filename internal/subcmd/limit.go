@@ -6,7 +6,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/turn"
 )
@@ -37,15 +36,13 @@ func beadLimit(home, rigName, cellName, turnID string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
-	issues, err := client.List(nil)
+	snap, err := beadsClient(home, cfg).Snapshot(nil)
 	if err != nil {
 		return err
 	}
 	count := 0
-	for _, issue := range issues {
-		meta := beads.ParseMeta(issue.Description)
-		if meta.Cell == cellName && meta.TurnID == turnID {
+	for _, issue := range snap.ByTurn(turnID) {
+		if snap.Meta(issue.ID).Cell == cellName {
 			count++
 		}
 	}
@@ -5,20 +5,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/hooks"
 	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/util"
 )
 
 type reconcileSummary struct {
-	AssignmentsClosed int
-	TasksUnblocked    int
-	AgentsStale       int
-	AgentsIdle        int
-	AgentsDown        int
+	AssignmentsClosed   int
+	AssignmentsInvalid  int
+	AssignmentsOffScope int
+	WritesReplayed      int
+	WritesPending       int
+	WritesDead          int
+	LeasesReleased      int
+	TasksUnblocked      int
+	AgentsStale         int
+	AgentsIdle          int
+	AgentsDown          int
+	// CellErrors holds cells whose config could not be resolved; their
+	// agents were skipped by the health check.
+	CellErrors map[string]error
 }
 
 func Manager(home string, args []string) error {
@@ -55,16 +66,40 @@ func Manager(home string, args []string) error {
 		if summary.AssignmentsClosed > 0 {
 			fmt.Printf("Reconciled %d assignment(s) to done\n", summary.AssignmentsClosed)
 		}
+		if summary.AssignmentsInvalid > 0 {
+			fmt.Printf("Skipped %d assignment(s) failing schema checks (see `mforge bead lint --type assignment`)\n", summary.AssignmentsInvalid)
+		}
+		if summary.AssignmentsOffScope > 0 {
+			fmt.Printf("Held %d assignment(s) whose last commit touches files outside the cell scope\n", summary.AssignmentsOffScope)
+		}
+		if summary.WritesReplayed > 0 || summary.WritesPending > 0 {
+			fmt.Printf("Replayed %d failed bead write(s); %d still pending\n", summary.WritesReplayed, summary.WritesPending)
+		}
+		if summary.WritesDead > 0 {
+			fmt.Printf("Gave up on %d failed bead write(s) after %d attempts (marked dead in bead-failures.jsonl)\n", summary.WritesDead, beads.MaxReplayAttempts)
+		}
+		if summary.LeasesReleased > 0 {
+			fmt.Printf("Released %d expired assignment lease(s)\n", summary.LeasesReleased)
+		}
 		if summary.TasksUnblocked > 0 {
 			fmt.Printf("Unblocked %d task(s)\n", summary.TasksUnblocked)
 		}
 		if summary.AgentsDown > 0 || summary.AgentsStale > 0 || summary.AgentsIdle > 0 {
 			fmt.Printf("Agent health: down=%d stale=%d idle=%d\n", summary.AgentsDown, summary.AgentsStale, summary.AgentsIdle)
 		}
+		var skipped []string
+		for cell := range summary.CellErrors {
+			skipped = append(skipped, cell)
+		}
+		sort.Strings(skipped)
+		for _, cell := range skipped {
+			fmt.Fprintf(os.Stderr, "warning: skipped agent health for cell %s: %v\n", cell, summary.CellErrors[cell])
+		}
 		if !watch {
 			return nil
 		}
 		time.Sleep(2 * time.Second)
+		ResetBeadsCache()
 	}
 }
 
@@ -73,13 +108,20 @@ func reconcile(home, rigName string, stopIdle bool) (reconcileSummary, error) {
 	if err != nil {
 		return reconcileSummary{}, err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
+	// Replay writes that hooks and commands swallowed before reading state,
+	// so this pass sees them.
+	replay, err := client.ReplayFailures(nil)
+	if err != nil {
+		return reconcileSummary{}, fmt.Errorf("replaying failed bead writes: %w", err)
+	}
 	issues, err := client.List(nil)
 	if err != nil {
 		return reconcileSummary{}, err
 	}
 	eventGate := eventKinds(issues)
-	summary := reconcileSummary{}
+	schemas := beadSchemas(cfg)
+	summary := reconcileSummary{WritesReplayed: replay.Replayed, WritesPending: replay.Remaining, WritesDead: replay.DeadLettered}
 	for _, issue := range issues {
 		if strings.ToLower(issue.Type) != "assignment" {
 			continue
@@ -89,6 +131,18 @@ func reconcile(home, rigName string, stopIdle bool) (reconcileSummary, error) {
 		}
 		meta := beads.ParseMeta(issue.Description)
 		if strings.TrimSpace(meta.Worktree) == "" || strings.TrimSpace(meta.Outbox) == "" || strings.TrimSpace(meta.Promise) == "" {
+			key := "assignment_invalid|" + issue.ID
+			if !eventGate[key] {
+				var missing []string
+				for _, v := range beads.Validate(schemas, issue) {
+					missing = append(missing, v.String())
+				}
+				event := beads.Meta{Cell: meta.Cell, Role: meta.Role, Kind: "assignment_invalid", Title: issue.Title}
+				event.SetList("violations", missing)
+				emitOrchestrationEvent(client, event, fmt.Sprintf("Assignment invalid %s", issue.ID), []string{"related:" + issue.ID})
+				eventGate[key] = true
+			}
+			summary.AssignmentsInvalid++
 			continue
 		}
 		outAbs := filepath.Join(meta.Worktree, meta.Outbox)
@@ -102,22 +156,42 @@ func reconcile(home, rigName string, stopIdle bool) (reconcileSummary, error) {
 				if !eventGate[key] {
 					meta.Kind = "assignment_missing_commit"
 					meta.Title = issue.Title
-					emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Assignment missing commit %s", issue.ID), []string{"related:" + issue.ID})
+					emitOrchestrationEvent(client, meta, fmt.Sprintf("Assignment missing commit %s", issue.ID), []string{"related:" + issue.ID})
+					eventGate[key] = true
+				}
+				continue
+			}
+			if outside, err := commitOutsideScope(home, rigName, meta); err == nil && len(outside) > 0 {
+				key := "assignment_out_of_scope|" + issue.ID
+				if !eventGate[key] {
+					event := beads.Meta{Cell: meta.Cell, Role: meta.Role, Kind: "assignment_out_of_scope", Title: issue.Title}
+					event.SetList("files", outside)
+					emitOrchestrationEvent(client, event, fmt.Sprintf("Assignment out of scope %s", issue.ID), []string{"related:" + issue.ID})
 					eventGate[key] = true
 				}
+				summary.AssignmentsOffScope++
 				continue
 			}
-			_, _ = client.Close(nil, issue.ID, "assignment complete")
+			client.TryClose(nil, issue.ID, "assignment complete")
 			archiveMail(meta.Worktree, meta.Inbox)
 			archiveMail(meta.Worktree, meta.Outbox)
 			summary.AssignmentsClosed++
 			meta.Kind = "assignment_complete"
 			meta.Title = issue.Title
-			emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Assignment complete %s", issue.ID), []string{"related:" + issue.ID})
+			emitOrchestrationEvent(client, meta, fmt.Sprintf("Assignment complete %s", issue.ID), []string{"related:" + issue.ID})
 			writeTaskCompleteSignal(meta, issue)
 		}
 	}
-	unblocked, err := reconcileBlockedTasks(client, issues, cfg.RepoPath)
+	released, err := hooks.ReleaseExpiredLeases(nil, client, home, rigName, issues)
+	if err != nil {
+		return summary, err
+	}
+	for _, id := range released {
+		meta := beads.Meta{Kind: "assignment_lease_expired"}
+		emitOrchestrationEvent(client, meta, fmt.Sprintf("Assignment lease expired %s", id), []string{"related:" + id})
+	}
+	summary.LeasesReleased = len(released)
+	unblocked, err := reconcileBlockedTasks(client, issues)
 	if err != nil {
 		return summary, err
 	}
@@ -129,6 +203,7 @@ func reconcile(home, rigName string, stopIdle bool) (reconcileSummary, error) {
 	summary.AgentsStale = health.Stale
 	summary.AgentsIdle = health.Idle
 	summary.AgentsDown = health.Down
+	summary.CellErrors = health.CellErrors
 	return summary, nil
 }
 
@@ -151,11 +226,8 @@ func writeTaskCompleteSignal(meta beads.Meta, issue beads.Issue) {
 		return
 	}
 	taskID := ""
-	for _, dep := range issue.Deps {
-		if strings.HasPrefix(dep, "related:") {
-			taskID = strings.TrimPrefix(dep, "related:")
-			break
-		}
+	if related := beads.DepTargets(issue.Deps, beads.EdgeRelated); len(related) > 0 {
+		taskID = related[0]
 	}
 	payload := map[string]string{
 		"type":         "task_complete",
@@ -175,19 +247,19 @@ func writeTaskCompleteSignal(meta beads.Meta, issue beads.Issue) {
 }
 
 type agentHealthSummary struct {
-	Stale int
-	Idle  int
-	Down  int
+	Stale      int
+	Idle       int
+	Down       int
+	CellErrors map[string]error
 }
 
 func reconcileAgentHealth(home string, cfg rig.RigConfig, rigName string, issues []beads.Issue, stopIdle bool) (agentHealthSummary, error) {
-	const staleThreshold = 15 * time.Minute
-	const idleThreshold = 5 * time.Minute
 	cells, err := rig.ListCellConfigs(home, rigName)
 	if err != nil {
 		return agentHealthSummary{}, err
 	}
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	client := beadsClient(home, cfg)
+	roles := cfg.RoleNames()
 	eventGate := map[string]bool{}
 	for _, issue := range issues {
 		if strings.ToLower(issue.Type) != "event" {
@@ -205,6 +277,14 @@ func reconcileAgentHealth(home string, cfg rig.RigConfig, rigName string, issues
 	now := time.Now().UTC()
 	summary := agentHealthSummary{}
 	for _, cell := range cells {
+		resolved, err := rig.Resolve(cfg, cell)
+		if err != nil {
+			if summary.CellErrors == nil {
+				summary.CellErrors = map[string]error{}
+			}
+			summary.CellErrors[cell.Name] = err
+			continue
+		}
 		for _, role := range roles {
 			hb := readHeartbeat(agentObsDir(home, rigName, cell.Name, role))
 			if strings.TrimSpace(hb.Timestamp) == "" {
@@ -225,10 +305,10 @@ func reconcileAgentHealth(home string, cfg rig.RigConfig, rigName string, issues
 				Kind:  "",
 			}
 			if !running {
-				if age > staleThreshold {
+				if age > resolved.StaleAfter {
 					meta.Kind = "agent_down"
 					if !eventGate[meta.Kind+"|"+cell.Name+"|"+role] {
-						emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Agent down %s/%s", cell.Name, role), nil)
+						emitOrchestrationEvent(client, meta, fmt.Sprintf("Agent down %s/%s", cell.Name, role), nil)
 						eventGate[meta.Kind+"|"+cell.Name+"|"+role] = true
 					}
 					summary.Down++
@@ -236,25 +316,25 @@ func reconcileAgentHealth(home string, cfg rig.RigConfig, rigName string, issues
 				continue
 			}
 			if strings.EqualFold(hb.Status, "idle") {
-				if age > idleThreshold {
+				if age > resolved.IdleAfter {
 					meta.Kind = "agent_idle"
 					if !eventGate[meta.Kind+"|"+cell.Name+"|"+role] {
-						emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Agent idle %s/%s", cell.Name, role), nil)
+						emitOrchestrationEvent(client, meta, fmt.Sprintf("Agent idle %s/%s", cell.Name, role), nil)
 						eventGate[meta.Kind+"|"+cell.Name+"|"+role] = true
 					}
 					summary.Idle++
 					if stopIdle {
 						_, _ = runTmux(cfg, false, false, "kill-session", "-t", session)
 						meta.Kind = "agent_idle_exit"
-						emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Agent idle exit %s/%s", cell.Name, role), nil)
+						emitOrchestrationEvent(client, meta, fmt.Sprintf("Agent idle exit %s/%s", cell.Name, role), nil)
 					}
 				}
 				continue
 			}
-			if age > staleThreshold {
+			if age > resolved.StaleAfter {
 				meta.Kind = "agent_stale"
 				if !eventGate[meta.Kind+"|"+cell.Name+"|"+role] {
-					emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Agent stale %s/%s", cell.Name, role), nil)
+					emitOrchestrationEvent(client, meta, fmt.Sprintf("Agent stale %s/%s", cell.Name, role), nil)
 					eventGate[meta.Kind+"|"+cell.Name+"|"+role] = true
 				}
 				summary.Stale++
@@ -264,11 +344,8 @@ func reconcileAgentHealth(home string, cfg rig.RigConfig, rigName string, issues
 	return summary, nil
 }
 
-func reconcileBlockedTasks(client beads.Client, issues []beads.Issue, repo string) (int, error) {
-	issueByID := map[string]beads.Issue{}
-	for _, issue := range issues {
-		issueByID[issue.ID] = issue
-	}
+func reconcileBlockedTasks(client beads.Client, issues []beads.Issue) (int, error) {
+	graph := beads.NewGraph(issues)
 	unblocked := 0
 	for _, issue := range issues {
 		if strings.ToLower(issue.Status) != "blocked" {
@@ -277,50 +354,21 @@ func reconcileBlockedTasks(client beads.Client, issues []beads.Issue, repo strin
 		if strings.ToLower(issue.Type) == "assignment" {
 			continue
 		}
-		if len(issue.Deps) == 0 {
-			continue
-		}
-		if !depsSatisfied(issue.Deps, issueByID) {
+		if !graph.DepsClosed(issue.ID) {
 			continue
 		}
-		if _, err := client.UpdateStatus(nil, issue.ID, "open"); err != nil {
+		if _, err := client.Update(nil, issue.ID, beads.UpdateRequest{Status: "open", Reason: "blockers closed", From: issue.Status}); err != nil {
 			return unblocked, err
 		}
 		unblocked++
 		meta := beads.ParseMeta(issue.Description)
 		meta.Kind = "task_unblocked"
 		meta.Title = issue.Title
-		emitOrchestrationEvent(repo, meta, fmt.Sprintf("Task unblocked %s", issue.ID), []string{"related:" + issue.ID})
+		emitOrchestrationEvent(client, meta, fmt.Sprintf("Task unblocked %s", issue.ID), []string{"related:" + issue.ID})
 	}
 	return unblocked, nil
 }
 
-func depsSatisfied(deps []string, issues map[string]beads.Issue) bool {
-	for _, dep := range deps {
-		id := depID(dep)
-		if strings.TrimSpace(id) == "" {
-			continue
-		}
-		issue, ok := issues[id]
-		if !ok {
-			return false
-		}
-		status := strings.ToLower(strings.TrimSpace(issue.Status))
-		if status != "done" && status != "closed" {
-			return false
-		}
-	}
-	return true
-}
-
-func depID(dep string) string {
-	if strings.Contains(dep, ":") {
-		parts := strings.SplitN(dep, ":", 2)
-		return strings.TrimSpace(parts[1])
-	}
-	return strings.TrimSpace(dep)
-}
-
 func eventKinds(issues []beads.Issue) map[string]bool {
 	out := map[string]bool{}
 	for _, issue := range issues {
@@ -339,10 +387,38 @@ func eventKinds(issues []beads.Issue) map[string]bool {
 			key = key + "|" + meta.Cell + "|" + meta.Role
 		}
 		out[key] = true
+		// Events about a bead are gated on kind|<bead id>.
+		for _, id := range beads.DepTargets(issue.Deps, beads.EdgeRelated) {
+			out[meta.Kind+"|"+id] = true
+		}
 	}
 	return out
 }
 
+// commitOutsideScope lists the files changed by the worktree's last commit
+// that fall outside the assignment cell's scope.
+func commitOutsideScope(home, rigName string, meta beads.Meta) ([]string, error) {
+	if strings.TrimSpace(meta.Cell) == "" || strings.TrimSpace(meta.Worktree) == "" {
+		return nil, nil
+	}
+	cell, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, meta.Cell))
+	if err != nil {
+		return nil, err
+	}
+	res, err := util.Run(nil, "git", "-C", meta.Worktree, "show", "--name-only", "--pretty=format:", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	scope := cell.Scope()
+	var outside []string
+	for _, line := range strings.Split(res.Stdout, "\n") {
+		if f := strings.TrimSpace(line); f != "" && !scope.Match(f) {
+			outside = append(outside, f)
+		}
+	}
+	return outside, nil
+}
+
 func assignmentHasCommit(worktree string, issue beads.Issue) (bool, error) {
 	if strings.TrimSpace(worktree) == "" {
 		return true, nil
@@ -370,12 +446,9 @@ func assignmentHasCommit(worktree string, issue beads.Issue) (bool, error) {
 			return true, nil
 		}
 	}
-	for _, dep := range issue.Deps {
-		if strings.HasPrefix(dep, "related:") {
-			taskID := strings.TrimPrefix(dep, "related:")
-			if strings.TrimSpace(taskID) != "" && strings.Contains(msg, strings.ToLower(taskID)) {
-				return true, nil
-			}
+	for _, taskID := range beads.DepTargets(issue.Deps, beads.EdgeRelated) {
+		if strings.Contains(msg, strings.ToLower(taskID)) {
+			return true, nil
 		}
 	}
 	return false, nil
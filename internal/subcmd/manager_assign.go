@@ -22,7 +22,7 @@ func ManagerAssign(home, rigName string, rest []string) error {
 		return err
 	}
 	warnContextMismatch(home, rigName, "manager assign")
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -78,10 +78,10 @@ func ManagerAssign(home, rigName string, rest []string) error {
 		if err != nil {
 			return err
 		}
-		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", issue)
+		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", issue, issueThread(client, issue.ID))
 		_ = createMailBead(client, assnMeta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
-		_, _ = client.UpdateStatus(nil, issue.ID, "in_progress")
-		emitOrchestrationEvent(cfg.RepoPath, beads.Meta{
+		client.TryUpdate(nil, issue.ID, beads.UpdateRequest{Status: "in_progress", From: issue.Status})
+		emitOrchestrationEvent(client, beads.Meta{
 			Cell:   cell.Name,
 			Role:   role,
 			Scope:  cell.ScopePrefix,
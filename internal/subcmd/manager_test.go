@@ -0,0 +1,125 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+func TestReconcileEmitsAssignmentEventsOnce(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	if err := Init(home, []string{"rig", "--repo", repo, "--beads", "jsonl"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, "rig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt := t.TempDir()
+	if err := util.EnsureDir(rig.CellDir(home, "rig", "api")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.SaveCellConfig(rig.CellConfigPath(home, "rig", "api"), rig.CellConfig{Name: "api", ScopePrefix: "svc/api", WorktreePath: wt}); err != nil {
+		t.Fatal(err)
+	}
+	client := beadsClient(home, cfg)
+	invalid := beads.Meta{Cell: "api", Role: "builder", Worktree: wt, Outbox: "mail/outbox/a.md"}
+	if _, err := client.Create(nil, beads.CreateRequest{Title: "Assignment bad", Type: "assignment", Status: "open", Description: beads.RenderMeta(invalid)}); err != nil {
+		t.Fatal(err)
+	}
+	valid := invalid
+	valid.Promise = "DONE"
+	offScope, err := client.Create(nil, beads.CreateRequest{Title: "Assignment off", Type: "assignment", Status: "open", Description: beads.RenderMeta(valid)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := util.EnsureDir(filepath.Join(wt, "mail", "outbox")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "mail", "outbox", "a.md"), []byte("DONE\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "other.go"), []byte("package x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "other.go"},
+		{"-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "work for " + offScope.ID},
+	} {
+		if _, err := util.Run(nil, "git", append([]string{"-C", wt}, args...)...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	for pass := 0; pass < 2; pass++ {
+		ResetBeadsCache()
+		summary, err := reconcile(home, "rig", false)
+		if err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if summary.AssignmentsInvalid != 1 || summary.AssignmentsOffScope != 1 {
+			t.Fatalf("pass %d: unexpected summary %+v", pass, summary)
+		}
+	}
+	issues, err := client.List(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, issue := range issues {
+		if issue.Type == "event" {
+			kinds[beads.ParseMeta(issue.Description).Kind]++
+		}
+	}
+	for _, kind := range []string{"assignment_invalid", "assignment_out_of_scope"} {
+		if kinds[kind] != 1 {
+			t.Fatalf("expected one %s event after two passes, got %v", kind, kinds)
+		}
+	}
+}
+
+func TestReconcileAgentHealthSkipsUnresolvableCells(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("MF_BEAD_LIMIT_PER_TURN", "")
+	if err := Init(home, []string{"rig", "--repo", repo, "--beads", "jsonl"}); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	cells := []rig.CellConfig{
+		{Name: "api", ScopePrefix: "svc/api", WorktreePath: t.TempDir()},
+		{Name: "bad", ScopePrefix: "svc/bad", WorktreePath: t.TempDir(), Overrides: &rig.CellOverrides{Health: &rig.HealthThresholds{StaleAfter: "soon"}}},
+	}
+	for _, cell := range cells {
+		if err := util.EnsureDir(rig.CellDir(home, "rig", cell.Name)); err != nil {
+			t.Fatal(err)
+		}
+		if err := rig.SaveCellConfig(rig.CellConfigPath(home, "rig", cell.Name), cell); err != nil {
+			t.Fatal(err)
+		}
+		dir := agentObsDir(home, "rig", cell.Name, "builder")
+		if err := util.EnsureDir(dir); err != nil {
+			t.Fatal(err)
+		}
+		hb := `{"timestamp":"2000-01-01T00:00:00Z","status":"working"}`
+		if err := os.WriteFile(filepath.Join(dir, "heartbeat.json"), []byte(hb), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	summary, err := reconcile(home, "rig", false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if summary.CellErrors["bad"] == nil || len(summary.CellErrors) != 1 {
+		t.Fatalf("expected only the bad cell recorded, got %v", summary.CellErrors)
+	}
+	if summary.AgentsDown != 1 {
+		t.Fatalf("expected the api agent still checked, got %+v", summary)
+	}
+}
@@ -59,15 +59,12 @@ func Merge(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
 	}
-	issueMap := map[string]beads.Issue{}
-	for _, issue := range issues {
-		issueMap[issue.ID] = issue
-	}
+	graph := beads.NewGraph(issues)
 	ready, err := client.Ready(nil)
 	if err != nil {
 		return err
@@ -89,14 +86,14 @@ func Merge(home string, args []string) error {
 			conflicts++
 			continue
 		}
-		if !reviewDepsSatisfied(issue, issueMap) {
+		if len(graph.Blockers(issue.ID)) > 0 {
 			continue
 		}
 		if dryRun {
 			merged = append(merged, issue.ID)
 			continue
 		}
-		_, _ = client.Close(nil, issue.ID, "merged")
+		client.TryClose(nil, issue.ID, "merged")
 		merged = append(merged, issue.ID)
 	}
 	if len(merged) > 0 {
@@ -116,22 +113,6 @@ func Merge(home string, args []string) error {
 	return nil
 }
 
-func reviewDepsSatisfied(issue beads.Issue, issueMap map[string]beads.Issue) bool {
-	for _, dep := range issue.Deps {
-		if strings.HasPrefix(dep, "review:") {
-			revID := strings.TrimPrefix(dep, "review:")
-			rev, ok := issueMap[revID]
-			if !ok {
-				return false
-			}
-			if rev.Status != "closed" && rev.Status != "done" {
-				return false
-			}
-		}
-	}
-	return true
-}
-
 func emitConflict(client beads.Client, cell, sourceID, turnID, details string) error {
 	meta := beads.Meta{Cell: cell, TurnID: turnID, Severity: "high"}
 	_, err := client.Create(nil, beads.CreateRequest{
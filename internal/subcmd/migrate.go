@@ -103,17 +103,7 @@ func migrateRig(home, rigName string) error {
 	if err != nil {
 		return err
 	}
-	updated := false
-	cfg.RuntimeArgs, updated = normalizeRuntimeArgs(cfg.RuntimeProvider, cfg.RuntimeCmd, cfg.RuntimeArgs)
-	for role, spec := range cfg.RuntimeRoles {
-		args, changed := normalizeRuntimeArgs(cfg.RuntimeProvider, cfg.RuntimeCmd, spec.Args)
-		if changed {
-			spec.Args = args
-			cfg.RuntimeRoles[role] = spec
-			updated = true
-		}
-	}
-	if updated {
+	if normalizeRigRuntime(&cfg) {
 		if err := rig.SaveRigConfig(rig.RigConfigPath(home, rigName), cfg); err != nil {
 			return err
 		}
@@ -123,54 +113,51 @@ func migrateRig(home, rigName string) error {
 		return err
 	}
 	for _, cell := range cells {
-		wt := cell.WorktreePath
-		if err := ensureHookConfig(wt); err != nil {
+		if err := repairCellFiles(cell.WorktreePath); err != nil {
 			return err
 		}
-		if _, err := os.Stat(filepath.Join(wt, ".claude", "settings.json")); os.IsNotExist(err) {
-			_ = util.EnsureDir(filepath.Join(wt, ".claude"))
-			settings := `{
-  "permissions": { "allow": ["Bash", "Read", "Write", "Edit"] }
-}`
-			_ = util.AtomicWriteFile(filepath.Join(wt, ".claude", "settings.json"), []byte(settings+"\n"), 0o644)
-		}
-		for _, p := range []string{"mail/inbox", "mail/outbox", "mail/archive"} {
-			_ = util.EnsureDir(filepath.Join(wt, p))
+		agents, _ := filepath.Glob(filepath.Join(cell.WorktreePath, ".mf", "active-agent*.json"))
+		for _, p := range agents {
+			if _, err := rig.MigrateFile(rig.SchemaAgent, p); err != nil {
+				return err
+			}
 		}
 	}
 	fmt.Printf("Rig migrated %s\n", rigName)
 	return nil
 }
 
-func normalizeRuntimeArgs(provider, cmd string, args []string) ([]string, bool) {
-	changed := false
-	out := make([]string, 0, len(args))
-	for i := 0; i < len(args); i++ {
-		if args[i] == "--resume" || args[i] == "--continue" || args[i] == "--fork-session" {
-			changed = true
-			continue
-		}
-		if args[i] == "--session-id" {
-			changed = true
-			if i+1 < len(args) {
-				i++
-			}
-			continue
+// normalizeRigRuntime rewrites the rig and per-role runtime args in place
+// and reports whether anything changed.
+func normalizeRigRuntime(cfg *rig.RigConfig) bool {
+	updated := false
+	cfg.RuntimeArgs, updated = rig.NormalizeRuntimeArgs(cfg.RuntimeProvider, cfg.RuntimeCmd, cfg.RuntimeArgs)
+	for role, spec := range cfg.RuntimeRoles {
+		args, changed := rig.NormalizeRuntimeArgs(cfg.RuntimeProvider, cfg.RuntimeCmd, spec.Args)
+		if changed {
+			spec.Args = args
+			cfg.RuntimeRoles[role] = spec
+			updated = true
 		}
-		out = append(out, args[i])
 	}
-	isClaude := strings.EqualFold(provider, "claude") || strings.Contains(strings.ToLower(cmd), "claude")
-	if isClaude {
-		if !hasArg(out, "--dangerously-skip-permissions") {
-			out = append(out, "--dangerously-skip-permissions")
-			changed = true
-		}
-		if hasArg(out, "--resume") || hasArg(out, "--continue") {
-			if !hasArg(out, "--fork-session") {
-				out = append(out, "--fork-session")
-				changed = true
-			}
-		}
+	return updated
+}
+
+// repairCellFiles restores the hook config, a minimal Claude settings file,
+// and the mail directories in a cell worktree. Existing files are kept.
+func repairCellFiles(wt string) error {
+	if err := ensureHookConfig(wt); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(wt, ".claude", "settings.json")); os.IsNotExist(err) {
+		_ = util.EnsureDir(filepath.Join(wt, ".claude"))
+		settings := `{
+  "permissions": { "allow": ["Bash", "Read", "Write", "Edit"] }
+}`
+		_ = util.AtomicWriteFile(filepath.Join(wt, ".claude", "settings.json"), []byte(settings+"\n"), 0o644)
 	}
-	return out, changed
+	for _, p := range []string{"mail/inbox", "mail/outbox", "mail/archive"} {
+		_ = util.EnsureDir(filepath.Join(wt, p))
+	}
+	return nil
 }
@@ -73,18 +73,18 @@ func monitorRunTests(home string, rest []string) error {
 			}
 		}
 	}
-	if len(cmdParts) == 0 {
-		return fmt.Errorf("--cmd is required")
-	}
-	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
+	resolved, err := resolveCell(home, rigName, cellName)
 	if err != nil {
 		return err
 	}
-	cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName))
-	if err != nil {
-		return err
+	cfg, cellCfg := resolved.Rig, resolved.Cell
+	if len(cmdParts) == 0 {
+		cmdParts = resolved.TestCmd
+	}
+	if len(cmdParts) == 0 {
+		return fmt.Errorf("--cmd is required (or set test_cmd in the cell overrides)")
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 
 	cmd := cmdParts[0]
 	cmdArgs := cmdParts[1:]
@@ -181,7 +181,7 @@ func monitorRun(home string, rest []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	if strings.TrimSpace(scope) == "" {
 		scope = cellCfg.ScopePrefix
 	}
@@ -0,0 +1,354 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/example/microforge/internal/beads"
+	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
+	"github.com/example/microforge/internal/util"
+)
+
+// Plan action markers: create, update, and flag (reported, never applied).
+const (
+	planCreate = "+"
+	planUpdate = "~"
+	planFlag   = "!"
+)
+
+// planAction is one step towards the manifest. Flagged actions have no
+// apply func.
+type planAction struct {
+	Op     string `json:"op"`
+	Target string `json:"target"`
+	Detail string `json:"detail"`
+	apply  func() error
+}
+
+// Plan prints what `mforge apply` would change to converge on the manifest.
+func Plan(home string, args []string) error {
+	path, jsonOut, err := parseManifestFlags("plan", args)
+	if err != nil {
+		return err
+	}
+	m, err := rig.LoadManifest(path)
+	if err != nil {
+		return err
+	}
+	actions, err := buildPlan(home, m)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		b, err := json.MarshalIndent(actions, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	printPlan(m, actions)
+	return nil
+}
+
+// Apply converges the rig on the manifest. It is idempotent: a second run
+// with an unchanged manifest has nothing to do.
+func Apply(home string, args []string) error {
+	path, _, err := parseManifestFlags("apply", args)
+	if err != nil {
+		return err
+	}
+	m, err := rig.LoadManifest(path)
+	if err != nil {
+		return err
+	}
+	actions, err := buildPlan(home, m)
+	if err != nil {
+		return err
+	}
+	applied, flagged := 0, 0
+	for _, a := range actions {
+		fmt.Printf("%s %s: %s\n", a.Op, a.Target, a.Detail)
+		if a.apply == nil {
+			flagged++
+			continue
+		}
+		if err := a.apply(); err != nil {
+			return fmt.Errorf("applying %s: %w", a.Target, err)
+		}
+		applied++
+		ResetBeadsCache()
+	}
+	if applied == 0 && flagged == 0 {
+		fmt.Printf("Rig %s matches %s\n", m.Rig, filepath.Base(m.Path))
+		return nil
+	}
+	fmt.Printf("Applied %d change(s); %d flagged for manual review\n", applied, flagged)
+	return nil
+}
+
+func parseManifestFlags(op string, args []string) (string, bool, error) {
+	path := rig.ManifestFile
+	jsonOut := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--file", "-f":
+			if i+1 >= len(args) {
+				return "", false, fmt.Errorf("usage: mforge %s [--file <path>] [--json]", op)
+			}
+			path = args[i+1]
+			i++
+		case "--json":
+			jsonOut = true
+		default:
+			return "", false, fmt.Errorf("unknown flag %q (usage: mforge %s [--file <path>] [--json])", args[i], op)
+		}
+	}
+	return path, jsonOut, nil
+}
+
+func printPlan(m rig.Manifest, actions []planAction) {
+	if len(actions) == 0 {
+		fmt.Printf("Rig %s matches %s; nothing to do\n", m.Rig, filepath.Base(m.Path))
+		return
+	}
+	changes, flagged := 0, 0
+	for _, a := range actions {
+		fmt.Printf("  %s %s: %s\n", a.Op, a.Target, a.Detail)
+		if a.apply == nil {
+			flagged++
+		} else {
+			changes++
+		}
+	}
+	fmt.Printf("Plan: %d change(s), %d flagged. Run `mforge apply` to converge.\n", changes, flagged)
+}
+
+// buildPlan diffs the manifest against the rig directory, each cell's
+// worktree, and the role beads in the store.
+func buildPlan(home string, m rig.Manifest) ([]planAction, error) {
+	var actions []planAction
+	cfgPath := rig.RigConfigPath(home, m.Rig)
+	target := "rig " + m.Rig
+	cfg, err := rig.LoadRigConfig(cfgPath)
+	exists := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var issues []beads.Issue
+	var existing []rig.CellConfig
+	if !exists {
+		actions = append(actions, planAction{
+			Op: planCreate, Target: target,
+			Detail: fmt.Sprintf("init at %s (beads=%s)", m.Repo, backendName(m.Beads)),
+			apply: func() error {
+				if err := Init(home, []string{m.Rig, "--repo", m.Repo, "--beads", m.Beads}); err != nil {
+					return err
+				}
+				cfg, err := rig.LoadRigConfig(cfgPath)
+				if err != nil {
+					return err
+				}
+				return rig.SaveRigConfig(cfgPath, m.Apply(cfg))
+			},
+		})
+	} else {
+		if m.Beads != "" && m.Beads != cfg.BeadsBackend {
+			actions = append(actions, planAction{Op: planFlag, Target: target,
+				Detail: fmt.Sprintf("beads backend is %s but the manifest asks for %s; switching backends is not automatic", backendName(cfg.BeadsBackend), m.Beads)})
+		}
+		want := m.Apply(cfg)
+		if changed := jsonFieldChanges(cfg, want); len(changed) > 0 {
+			actions = append(actions, planAction{Op: planUpdate, Target: target,
+				Detail: "set " + strings.Join(changed, ", "),
+				apply:  func() error { return rig.SaveRigConfig(cfgPath, want) },
+			})
+		}
+		issues, err = beadsClient(home, cfg).List(nil)
+		if err != nil {
+			return nil, fmt.Errorf("listing beads: %w", err)
+		}
+		existing, err = rig.ListCellConfigs(home, m.Rig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byName := map[string]rig.CellConfig{}
+	for _, c := range existing {
+		byName[c.Name] = c
+	}
+	for _, mc := range m.Cells {
+		mc := mc
+		cellTarget := "cell " + mc.Name
+		cellPath := rig.CellConfigPath(home, m.Rig, mc.Name)
+		current, ok := byName[mc.Name]
+		if !ok {
+			desired := mc.CellConfig(rig.CellConfig{
+				WorktreePath: rig.CellWorktreeDir(home, m.Rig, mc.Name),
+				CreatedAt:    time.Now().UTC().Format(time.RFC3339),
+			})
+			actions = append(actions, planAction{Op: planCreate, Target: cellTarget,
+				Detail: "add with scope " + desired.Scope().String(),
+				apply: func() error {
+					if err := util.EnsureDir(rig.CellDir(home, m.Rig, mc.Name)); err != nil {
+						return err
+					}
+					return rig.SaveCellConfig(cellPath, desired)
+				},
+			})
+			current = desired
+		} else if desired, changed := mc.CellConfig(current), jsonFieldChanges(current, mc.CellConfig(current)); len(changed) > 0 {
+			actions = append(actions, planAction{Op: planUpdate, Target: cellTarget,
+				Detail: "set " + strings.Join(changed, ", "),
+				apply:  func() error { return rig.SaveCellConfig(cellPath, desired) },
+			})
+		}
+		if missing := missingRoles(current, mc, issues); len(missing) > 0 {
+			flags := []string{"bootstrap", m.Rig, mc.Name}
+			if mc.HasRole("cell") {
+				flags = append(flags, "--single")
+			}
+			if mc.HasRole("architect") {
+				flags = append(flags, "--architect")
+			}
+			for _, role := range mc.CellRoles() {
+				if !hasArg(rig.BuiltinRoles, role) {
+					flags = append(flags, "--role", role)
+				}
+			}
+			actions = append(actions, planAction{Op: planCreate, Target: cellTarget,
+				Detail: "bootstrap " + strings.Join(missing, ", "),
+				apply:  func() error { return Cell(home, flags) },
+			})
+		}
+		if m.Hooks != nil {
+			if hooksDiffer(current.WorktreePath, m.Hooks) {
+				wt := current.WorktreePath
+				actions = append(actions, planAction{Op: planUpdate, Target: cellTarget,
+					Detail: "write .mf/hooks.json",
+					apply:  func() error { return writeManifestHooks(wt, m.Hooks) },
+				})
+			}
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, mc := range m.Cells {
+		declared[mc.Name] = true
+	}
+	for _, c := range existing {
+		if !declared[c.Name] {
+			actions = append(actions, planAction{Op: planFlag, Target: "cell " + c.Name,
+				Detail: "not in the manifest; stop its agents and remove " + rig.CellDir(home, m.Rig, c.Name) + " to drop it"})
+		}
+	}
+	return actions, nil
+}
+
+func backendName(b string) string {
+	if strings.TrimSpace(b) == "" {
+		return beads.BackendBD
+	}
+	return b
+}
+
+// jsonFieldChanges compares two values by their JSON encoding, field by
+// field, so the plan speaks in the config file's own key names.
+func jsonFieldChanges(a, b any) []string {
+	var ma, mb map[string]json.RawMessage
+	ba, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	_ = json.Unmarshal(ba, &ma)
+	_ = json.Unmarshal(bb, &mb)
+	keys := map[string]bool{}
+	for k := range ma {
+		keys[k] = true
+	}
+	for k := range mb {
+		keys[k] = true
+	}
+	var out []string
+	for k := range keys {
+		if string(ma[k]) != string(mb[k]) {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// missingRoles lists the manifest roles that have no active-agent file in
+// the worktree or no role bead in the store.
+func missingRoles(cell rig.CellConfig, mc rig.ManifestCell, issues []beads.Issue) []string {
+	haveBead := map[string]bool{}
+	for _, issue := range issues {
+		if strings.ToLower(issue.Type) != "role" {
+			continue
+		}
+		meta := beads.ParseMeta(issue.Description)
+		if strings.EqualFold(meta.Cell, cell.Name) {
+			haveBead[strings.ToLower(meta.Role)] = true
+		}
+	}
+	var missing []string
+	for _, role := range mc.CellRoles() {
+		_, err := os.Stat(filepath.Join(cell.WorktreePath, ".mf", "active-agent-"+role+".json"))
+		if err != nil || !haveBead[role] {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// manifestHookConfig is the hooks.json a manifest asks for: the events
+// `cell bootstrap` always declares plus the manifest's actions.
+func manifestHookConfig(spec map[string][]rig.HookSpec) hooks.HookConfig {
+	cfg := hooks.HookConfig{Events: map[string][]hooks.HookAction{}}
+	for _, ev := range []string{"claude_stop", "claude_pre_tool", "claude_permission", "turn_start", "turn_end", "turn_report"} {
+		cfg.Events[ev] = []hooks.HookAction{}
+	}
+	for ev, list := range spec {
+		actions := []hooks.HookAction{}
+		for _, h := range list {
+			actions = append(actions, hooks.HookAction{
+				Command:      h.Command,
+				OnlyRoles:    h.OnlyRoles,
+				OnlyCells:    h.OnlyCells,
+				TimeoutSec:   h.TimeoutSec,
+				ContinueOnEr: h.ContinueOnError,
+			})
+		}
+		cfg.Events[ev] = actions
+	}
+	return cfg
+}
+
+func hooksDiffer(worktree string, spec map[string][]rig.HookSpec) bool {
+	current, err := hooks.LoadHookConfig(worktree)
+	if err != nil {
+		return true
+	}
+	want := manifestHookConfig(spec)
+	a, _ := json.Marshal(current)
+	b, _ := json.Marshal(want)
+	return string(a) != string(b)
+}
+
+func writeManifestHooks(worktree string, spec map[string][]rig.HookSpec) error {
+	b, err := json.MarshalIndent(manifestHookConfig(spec), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := util.EnsureDir(filepath.Join(worktree, ".mf")); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(filepath.Join(worktree, ".mf", "hooks.json"), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,72 @@
+package subcmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+func TestApplyConvergesOnManifest(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+	manifest := `rig: demo
+beads: jsonl
+library:
+  docs: [docs/]
+hooks:
+  turn_end:
+    - command: make lint
+cells:
+  - name: api
+    scope: services/api
+    exclude: ["services/api/vendor/**"]
+    overrides:
+      test_cmd: [go, test, ./services/api/...]
+`
+	path := filepath.Join(repo, rig.ManifestFile)
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ResetBeadsCache()
+	defer ResetBeadsCache()
+	if err := Apply(home, []string{"--file", path}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	m, err := rig.LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := buildPlan(home, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 0 {
+		t.Fatalf("expected a converged rig, got %+v", actions)
+	}
+	cell, err := rig.LoadCellConfig(rig.CellConfigPath(home, "demo", "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell.Overrides == nil || strings.Join(cell.Overrides.TestCmd, " ") != "go test ./services/api/..." {
+		t.Fatalf("expected overrides applied, got %+v", cell.Overrides)
+	}
+
+	// Dropping a cell from the manifest flags it instead of deleting it.
+	m.Cells = nil
+	m.Library.Docs = []string{"docs/", "adr/"}
+	actions, err = buildPlan(home, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ops []string
+	for _, a := range actions {
+		ops = append(ops, a.Op+" "+a.Target+": "+a.Detail)
+	}
+	got := strings.Join(ops, "\n")
+	if !strings.Contains(got, "~ rig demo: set library_docs") || !strings.Contains(got, "! cell api: not in the manifest") {
+		t.Fatalf("unexpected plan:\n%s", got)
+	}
+}
@@ -0,0 +1,101 @@
+package subcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/example/microforge/internal/hooks"
+	"github.com/example/microforge/internal/rig"
+)
+
+// Policy shows the effective guardrail policy and dry-runs tool calls
+// against it.
+func Policy(home string, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: mforge policy <show|check> ...")
+	}
+	op, rigName := args[0], args[1]
+	var cellName, role, tool, command, path string
+	jsonOut := false
+	rest := args[2:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--json":
+			jsonOut = true
+		case "--cell", "--role", "--tool", "--command", "--path":
+			if i+1 >= len(rest) {
+				return fmt.Errorf("%s requires a value", rest[i])
+			}
+			val := rest[i+1]
+			switch rest[i] {
+			case "--cell":
+				cellName = val
+			case "--role":
+				role = val
+			case "--tool":
+				tool = val
+			case "--command":
+				command = val
+			case "--path":
+				path = val
+			}
+			i++
+		}
+	}
+	switch op {
+	case "show":
+		p, err := hooks.LoadPolicy(home, rigName, cellName)
+		if err != nil {
+			return err
+		}
+		if jsonOut {
+			b, err := json.MarshalIndent(p.Rules, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+			return nil
+		}
+		fmt.Printf("Rig policy:  %s\n", rig.RigPolicyPath(home, rigName))
+		if cellName != "" {
+			fmt.Printf("Cell policy: %s\n", rig.CellPolicyPath(home, rigName, cellName))
+		}
+		for i, r := range p.Rules {
+			fmt.Printf("%2d. %s\n", i+1, r.Describe())
+		}
+		return nil
+
+	case "check":
+		if cellName == "" || role == "" || tool == "" {
+			return fmt.Errorf("usage: mforge policy check --cell <cell> --role <role> --tool <tool> [--command <cmd>] [--path <path>]")
+		}
+		cell, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName))
+		if err != nil {
+			return fmt.Errorf("loading cell %s: %w", cellName, err)
+		}
+		identity := hooks.AgentIdentity{RigHome: home, RigName: rigName, CellName: cellName, Role: role, Scope: cell.ScopePrefix, Worktree: cell.WorktreePath}
+		input := map[string]any{}
+		if command != "" {
+			input["command"] = command
+		}
+		if path != "" {
+			input["file_path"] = path
+		}
+		dec, err := hooks.GuardrailsHook(hooks.ClaudeHookInput{ToolName: tool, ToolInput: input}, identity)
+		if err != nil {
+			return err
+		}
+		if jsonOut {
+			return json.NewEncoder(os.Stdout).Encode(dec)
+		}
+		fmt.Println(dec.Decision)
+		if dec.Reason != "" {
+			fmt.Println("  " + dec.Reason)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown policy subcommand: %s", op)
+	}
+}
@@ -77,7 +77,7 @@ func prCreate(home string, rest []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Cell: cell, TurnID: turnID}
 	body := ""
 	if strings.TrimSpace(url) != "" {
@@ -106,7 +106,7 @@ func prReady(home string, rest []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	_, err = client.UpdateStatus(nil, id, "ready")
 	return err
 }
@@ -120,6 +120,6 @@ func prLinkReview(home string, rest []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	return client.DepAdd(nil, prID, "review:"+reviewID)
 }
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/rig"
 )
 
@@ -38,7 +37,7 @@ func QuickAssign(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issue, err := client.Show(nil, beadID)
 	if err != nil {
 		return err
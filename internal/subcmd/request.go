@@ -3,6 +3,7 @@ package subcmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/example/microforge/internal/beads"
@@ -75,7 +76,12 @@ func Request(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		if strings.TrimSpace(scope) == "" {
+			if cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, cellName)); err == nil {
+				scope = cellCfg.ScopePrefix
+			}
+		}
+		client := beadsClient(home, cfg)
 		turnID := ""
 		if state, err := turn.Load(rig.TurnStatePath(home, rigName)); err == nil {
 			turnID = strings.TrimSpace(state.ID)
@@ -99,6 +105,9 @@ func Request(home string, args []string) error {
 				title = payloadData.Title
 			}
 		}
+		if err := checkBeadSchema(cfg, beads.Issue{Type: "request", Priority: priority, Description: desc}); err != nil {
+			return err
+		}
 		req := beads.CreateRequest{
 			Title:       title,
 			Type:        "request",
@@ -115,10 +124,11 @@ func Request(home string, args []string) error {
 
 	case "list":
 		if len(rest) < 1 {
-			return fmt.Errorf("usage: mforge request list <rig> [--cell <cell>] [--status <status>] [--priority <p>]")
+			return fmt.Errorf("usage: mforge request list <rig> [--cell <cell>] [--status <status>] [--priority <p>] " + listQueryUsage)
 		}
 		rigName := rest[0]
 		var cellName, status, priority string
+		var query listQuery
 		for i := 1; i < len(rest); i++ {
 			switch rest[i] {
 			case "--cell":
@@ -136,17 +146,24 @@ func Request(home string, args []string) error {
 					priority = rest[i+1]
 					i++
 				}
+			default:
+				next, err := query.consume(rest, i)
+				if err != nil {
+					return fmt.Errorf("%w (usage: mforge request list <rig> [--cell <cell>] [--status <status>] [--priority <p>] %s)", err, listQueryUsage)
+				}
+				i = next
 			}
 		}
 		cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		list, err := client.List(nil)
 		if err != nil {
 			return err
 		}
+		requests := make([]beads.Issue, 0, len(list))
 		for _, r := range list {
 			if strings.ToLower(r.Type) != "request" {
 				continue
@@ -157,10 +174,20 @@ func Request(home string, args []string) error {
 			if strings.TrimSpace(priority) != "" && r.Priority != priority {
 				continue
 			}
-			meta := beads.ParseMeta(r.Description)
-			if strings.TrimSpace(cellName) != "" && meta.Cell != cellName {
+			if strings.TrimSpace(cellName) != "" && beads.ParseMeta(r.Description).Cell != cellName {
 				continue
 			}
+			requests = append(requests, r)
+		}
+		requests, err = query.apply(requests)
+		if err != nil {
+			return err
+		}
+		if query.custom() {
+			return query.print(os.Stdout, requests)
+		}
+		for _, r := range requests {
+			meta := beads.ParseMeta(r.Description)
 			scope := meta.Scope
 			fmt.Printf("%s\t%s\t%s\t%s\t%s", r.ID, r.Status, r.Priority, meta.SourceRole, meta.Cell)
 			if scope != "" {
@@ -197,7 +224,7 @@ func Request(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		reqIssue, err := client.Show(nil, reqID)
 		if err != nil {
 			return err
@@ -223,23 +250,10 @@ func Request(home string, args []string) error {
 			}
 			taskMeta := beads.Meta{Cell: meta.Cell, Scope: scope, Kind: kind, Title: title}
 			taskDesc := beads.RenderMeta(taskMeta) + "\n\n" + body
-			taskIssue, err := client.Create(nil, beads.CreateRequest{
-				Title:       title,
-				Type:        "task",
-				Priority:    reqIssue.Priority,
-				Status:      "open",
-				Description: taskDesc,
-				Deps:        []string{"related:" + reqIssue.ID},
-			})
-			if err != nil {
-				return err
-			}
 			cellCfg, err := rig.LoadCellConfig(rig.CellConfigPath(home, rigName, meta.Cell))
 			if err != nil {
 				return err
 			}
-			inboxRel := fmt.Sprintf("mail/inbox/%s.md", taskIssue.ID)
-			outboxRel := fmt.Sprintf("mail/outbox/%s.md", taskIssue.ID)
 			turnID := ""
 			if state, err := turn.Load(rig.TurnStatePath(home, rigName)); err == nil {
 				turnID = strings.TrimSpace(state.ID)
@@ -247,35 +261,56 @@ func Request(home string, args []string) error {
 			if err := beadLimit(home, rigName, meta.Cell, turnID); err != nil {
 				return err
 			}
-			assnMeta := beads.Meta{
-				Cell:     meta.Cell,
-				Role:     "builder",
-				Scope:    scope,
-				Inbox:    inboxRel,
-				Outbox:   outboxRel,
-				Promise:  "DONE",
-				TurnID:   turnID,
-				Worktree: cellCfg.WorktreePath,
+			assnDesc := func(taskID string) string {
+				assnMeta := beads.Meta{
+					Cell:     meta.Cell,
+					Role:     "builder",
+					Scope:    scope,
+					Inbox:    fmt.Sprintf("mail/inbox/%s.md", taskID),
+					Outbox:   fmt.Sprintf("mail/outbox/%s.md", taskID),
+					Promise:  "DONE",
+					TurnID:   turnID,
+					Worktree: cellCfg.WorktreePath,
+				}
+				return beads.RenderMeta(assnMeta) + "\n\n" + title
+			}
+			// Check both beads before creating either, so a schema failure
+			// does not leave a task without its assignment.
+			if err := checkBeadSchema(cfg, beads.Issue{Type: "task", Priority: reqIssue.Priority, Description: taskDesc}); err != nil {
+				return err
+			}
+			if err := checkBeadSchema(cfg, beads.Issue{Type: "assignment", Priority: reqIssue.Priority, Description: assnDesc(reqIssue.ID)}); err != nil {
+				return err
+			}
+			taskIssue, err := client.Create(nil, beads.CreateRequest{
+				Title:       title,
+				Type:        "task",
+				Priority:    reqIssue.Priority,
+				Status:      "open",
+				Description: taskDesc,
+				Deps:        []string{"related:" + reqIssue.ID},
+			})
+			if err != nil {
+				return err
 			}
-			assnDesc := beads.RenderMeta(assnMeta) + "\n\n" + title
 			if _, err := client.Create(nil, beads.CreateRequest{
 				Title:       "Assignment " + taskIssue.ID,
 				Type:        "assignment",
 				Priority:    reqIssue.Priority,
 				Status:      "open",
-				Description: assnDesc,
+				Description: assnDesc(taskIssue.ID),
 				Deps:        []string{"related:" + taskIssue.ID},
 			}); err != nil {
 				return err
 			}
-			_, _ = client.UpdateStatus(nil, reqIssue.ID, "in_progress")
+			client.TryUpdate(nil, reqIssue.ID, beads.UpdateRequest{Status: "in_progress", From: reqIssue.Status})
 			fmt.Printf("Triaged request %s -> task %s\n", reqIssue.ID, taskIssue.ID)
 			return nil
 		case "merge":
-			_, err := client.Close(nil, reqIssue.ID, "merged")
+			_, err := client.CloseWithOptions(nil, reqIssue.ID, beads.CloseOptions{Reason: "merged", From: reqIssue.Status})
 			return err
 		case "block":
-			_, err := client.UpdateStatus(nil, reqIssue.ID, "blocked")
+			_, err := client.Update(nil, reqIssue.ID, beads.UpdateRequest{Status: "blocked", From: reqIssue.Status})
 			return err
 		default:
 			return fmt.Errorf("unknown action: %s", action)
@@ -62,7 +62,7 @@ func Review(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	meta := beads.Meta{Cell: cell, Scope: scope, TurnID: turnID, Role: "reviewer"}
 	reviewIssue, err := client.Create(nil, beads.CreateRequest{
 		Title:       title,
@@ -101,7 +101,7 @@ func Review(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, "DONE", reviewIssue)
+	mail, _ := writeAssignmentInbox(cellCfg.WorktreePath, inboxRel, outboxRel, "DONE", reviewIssue, issueThread(client, reviewIssue.ID))
 	_ = createMailBead(client, assnMeta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
 	fmt.Printf("Created review %s\n", reviewIssue.ID)
 	return nil
@@ -154,6 +154,9 @@ func rigBackup(home string, args []string) error {
 		ts := time.Now().UTC().Format("20060102T150405Z")
 		out = filepath.Join(backupDir, fmt.Sprintf("rig-%s-%s.tar.gz", rigName, ts))
 	}
+	if cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName)); err == nil && strings.TrimSpace(cfg.LibraryContext7Token) != "" && !rig.IsSecretRef(cfg.LibraryContext7Token) {
+		fmt.Fprintln(os.Stderr, "warning: rig.json stores library_context7_token in plain text and it will be in this backup; use an env:, file:, or secret: reference instead")
+	}
 	if err := createTarGz(out, rdir); err != nil {
 		return err
 	}
@@ -256,7 +259,7 @@ func rigMessage(home string, args []string) error {
 			return fmt.Errorf("unknown cell: %s", cellName)
 		}
 	}
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	roles := cfg.RoleNames()
 	if roleName != "" {
 		roles = []string{roleName}
 	}
@@ -292,7 +295,7 @@ func rigMessage(home string, args []string) error {
 				Kind:  "rig_message",
 				Title: "Rig message",
 			}
-			emitOrchestrationEvent(cfg.RepoPath, meta, text, nil)
+			emitOrchestrationEvent(beadsClient(home, cfg), meta, text, nil)
 			sent++
 		}
 	}
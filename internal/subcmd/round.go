@@ -72,7 +72,7 @@ func roundStart(home, rigName string, wait bool) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -103,7 +103,7 @@ func roundStart(home, rigName string, wait bool) error {
 		if err := beadLimit(home, rigName, cell.Name, turnID); err != nil {
 			return err
 		}
-		role := roleForTask(*cell, meta)
+		role := roleForTask(cfg, *cell, meta)
 		if err := ensureCellBootstrapped(home, rigName, cell.Name, role, true); err != nil {
 			return err
 		}
@@ -130,9 +130,9 @@ func roundStart(home, rigName string, wait bool) error {
 		if err != nil {
 			return err
 		}
-		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", issue)
+		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", issue, issueThread(client, issue.ID))
 		_ = createMailBead(client, assnMeta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
-		_, _ = client.UpdateStatus(nil, issue.ID, "in_progress")
+		client.TryUpdate(nil, issue.ID, beads.UpdateRequest{Status: "in_progress", From: issue.Status})
 		wakeSet[cell.Name+"|"+role] = struct{}{}
 		assigned++
 	}
@@ -151,7 +151,7 @@ func roundStart(home, rigName string, wait bool) error {
 	if _, err := reconcile(home, rigName, false); err != nil {
 		return err
 	}
-	emitOrchestrationEvent(cfg.RepoPath, beads.Meta{Kind: "round_start"}, fmt.Sprintf("Round start %s", rigName), nil)
+	emitOrchestrationEvent(client, beads.Meta{Kind: "round_start"}, fmt.Sprintf("Round start %s", rigName), nil)
 	fmt.Printf("Round start: assigned %d task(s)\n", assigned)
 	return nil
 }
@@ -169,7 +169,7 @@ func roundReview(home, rigName string, wait bool, base string, all bool, changes
 	if base == "" {
 		base = detectBaseBranch(cfg.RepoPath)
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	issues, err := client.List(nil)
 	if err != nil {
 		return err
@@ -195,7 +195,7 @@ func roundReview(home, rigName string, wait bool, base string, all bool, changes
 				continue
 			}
 		}
-		role := roleForReview(cell)
+		role := roleForReview(cfg, cell)
 		if err := ensureCellBootstrapped(home, rigName, cell.Name, role, true); err != nil {
 			return err
 		}
@@ -237,7 +237,7 @@ func roundReview(home, rigName string, wait bool, base string, all bool, changes
 		if err != nil {
 			return err
 		}
-		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", reviewIssue)
+		mail, _ := writeAssignmentInbox(cell.WorktreePath, inboxRel, outboxRel, "DONE", reviewIssue, issueThread(client, reviewIssue.ID))
 		_ = createMailBead(client, assnMeta, "Mail "+assn.ID, mail, []string{"related:" + assn.ID})
 		if err := Agent(home, []string{"spawn", rigName, cell.Name, role}); err != nil {
 			fmt.Printf("Spawn skipped for %s/%s: %v\n", cell.Name, role, err)
@@ -260,7 +260,7 @@ func roundReview(home, rigName string, wait bool, base string, all bool, changes
 	if _, err := reconcile(home, rigName, false); err != nil {
 		return err
 	}
-	emitOrchestrationEvent(cfg.RepoPath, beads.Meta{Kind: "round_review"}, fmt.Sprintf("Round review %s", rigName), nil)
+	emitOrchestrationEvent(client, beads.Meta{Kind: "round_review"}, fmt.Sprintf("Round review %s", rigName), nil)
 	if skipped > 0 {
 		fmt.Printf("Round review: created %d review task(s); skipped %d (no changes)\n", created, skipped)
 	} else {
@@ -383,13 +383,8 @@ func existingAssignments(issues []beads.Issue) map[string]bool {
 		if strings.ToLower(issue.Type) != "assignment" {
 			continue
 		}
-		for _, dep := range issue.Deps {
-			if strings.HasPrefix(dep, "related:") {
-				taskID := strings.TrimPrefix(dep, "related:")
-				if strings.TrimSpace(taskID) != "" {
-					out[taskID] = true
-				}
-			}
+		for _, taskID := range beads.DepTargets(issue.Deps, beads.EdgeRelated) {
+			out[taskID] = true
 		}
 	}
 	return out
@@ -416,12 +411,14 @@ func reviewByCell(issues []beads.Issue, turnID string) map[string]bool {
 	return out
 }
 
-func roleForTask(cell rig.CellConfig, meta beads.Meta) string {
+// roleForTask routes a task to the first registered role in the cell that
+// declares the task's kind, falling back to the cell or builder agent.
+func roleForTask(cfg rig.RigConfig, cell rig.CellConfig, meta beads.Meta) string {
 	if strings.TrimSpace(meta.Role) != "" {
 		return meta.Role
 	}
-	if isDocKind(meta.Kind) && roleExists(cell.WorktreePath, "architect") {
-		return "architect"
+	if role := roleForKind(cfg, cell, meta.Kind); role != "" {
+		return role
 	}
 	if roleExists(cell.WorktreePath, "cell") {
 		return "cell"
@@ -429,10 +426,13 @@ func roleForTask(cell rig.CellConfig, meta beads.Meta) string {
 	return "builder"
 }
 
-func roleForReview(cell rig.CellConfig) string {
+func roleForReview(cfg rig.RigConfig, cell rig.CellConfig) string {
 	if roleExists(cell.WorktreePath, "cell") {
 		return "cell"
 	}
+	if role := roleForKind(cfg, cell, "review"); role != "" {
+		return role
+	}
 	if roleExists(cell.WorktreePath, "reviewer") {
 		return "reviewer"
 	}
@@ -442,6 +442,18 @@ func roleForReview(cell rig.CellConfig) string {
 	return "builder"
 }
 
+func roleForKind(cfg rig.RigConfig, cell rig.CellConfig, kind string) string {
+	if strings.TrimSpace(kind) == "" {
+		return ""
+	}
+	for _, spec := range cfg.RoleSpecs() {
+		if spec.HandlesKind(kind) && roleExists(cell.WorktreePath, spec.Name) {
+			return spec.Name
+		}
+	}
+	return ""
+}
+
 func roleExists(worktree, role string) bool {
 	if strings.TrimSpace(worktree) == "" {
 		return false
@@ -449,12 +461,3 @@ func roleExists(worktree, role string) bool {
 	_, err := os.Stat(filepath.Join(worktree, ".mf", "active-agent-"+role+".json"))
 	return err == nil
 }
-
-func isDocKind(kind string) bool {
-	switch strings.ToLower(strings.TrimSpace(kind)) {
-	case "doc", "docs", "plan", "design":
-		return true
-	default:
-		return false
-	}
-}
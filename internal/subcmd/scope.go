@@ -27,7 +27,7 @@ func Scope(home string, args []string) error {
 		}
 		scopes := map[string][]string{}
 		for _, cell := range cells {
-			scope := strings.TrimSpace(cell.ScopePrefix)
+			scope := cell.Scope().String()
 			if scope == "" {
 				continue
 			}
@@ -43,10 +43,11 @@ func Scope(home string, args []string) error {
 			sort.Strings(cells)
 			fmt.Printf("%s\t%s\n", scope, strings.Join(cells, ","))
 		}
+		printOverlaps(cells, "")
 		return nil
 	case "show":
 		if len(rest) < 1 {
-			return fmt.Errorf("usage: mforge scope show <rig> --scope <path-prefix>")
+			return fmt.Errorf("usage: mforge scope show <rig> --scope <path>")
 		}
 		rigName := rest[0]
 		var scope string
@@ -66,18 +67,34 @@ func Scope(home string, args []string) error {
 		if err != nil {
 			return err
 		}
-		matched := make([]string, 0)
+		pick := rig.MatchCell(cells, scope)
+		matched := make([]rig.CellConfig, 0)
 		for _, cell := range cells {
-			if strings.TrimSpace(cell.ScopePrefix) == scope {
-				matched = append(matched, cell.Name)
+			if cell.Scope().Match(scope) {
+				matched = append(matched, cell)
 			}
 		}
-		sort.Strings(matched)
+		sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
 		for _, cell := range matched {
-			fmt.Println(cell)
+			marker := ""
+			if pick != nil && pick.Name == cell.Name {
+				marker = "\t(routed)"
+			}
+			fmt.Printf("%s\t%d\t%s%s\n", cell.Name, cell.Scope().Specificity(scope), cell.Scope().String(), marker)
 		}
 		return nil
 	default:
 		return fmt.Errorf("unknown scope subcommand: %s", op)
 	}
 }
+
+// printOverlaps warns about paths that more than one cell's scope claims,
+// limited to overlaps involving cell when it is set.
+func printOverlaps(cells []rig.CellConfig, cell string) {
+	for _, o := range rig.Overlaps(cells) {
+		if cell != "" && o.A != cell && o.B != cell {
+			continue
+		}
+		fmt.Printf("Warning: cells %q and %q both own %s\n", o.A, o.B, o.Path)
+	}
+}
@@ -0,0 +1,63 @@
+package subcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/example/microforge/internal/rig"
+)
+
+// Secret manages the local encrypted secrets file read by secret:NAME
+// references. Values are read from stdin so they never appear in argv.
+func Secret(home string, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: mforge secret <set|list|rm> [<name>]")
+	}
+	op := args[0]
+	rest := args[1:]
+	store, err := rig.OpenSecretStore(home)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case "list":
+		for _, name := range store.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	case "set":
+		if len(rest) < 1 || strings.TrimSpace(rest[0]) == "" {
+			return fmt.Errorf("usage: mforge secret set <name> < value")
+		}
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("reading secret from stdin: %w", err)
+		}
+		value := strings.TrimRight(string(b), "\r\n")
+		if value == "" {
+			return fmt.Errorf("empty secret on stdin")
+		}
+		store.Set(rest[0], value)
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Stored %s; reference it as %s%s\n", rest[0], rig.SecretRefStore, rest[0])
+		return nil
+	case "rm":
+		if len(rest) < 1 {
+			return fmt.Errorf("usage: mforge secret rm <name>")
+		}
+		if !store.Delete(rest[0]) {
+			return fmt.Errorf("no secret named %s", rest[0])
+		}
+		if err := store.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s\n", rest[0])
+		return nil
+	default:
+		return fmt.Errorf("unknown secret subcommand: %s", op)
+	}
+}
@@ -3,6 +3,7 @@ package subcmd
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/example/microforge/internal/beads"
@@ -54,12 +55,15 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		meta := beads.Meta{Scope: scope, Kind: kind, Title: title}
 		desc := beads.RenderMeta(meta)
 		if strings.TrimSpace(body) != "" {
 			desc += "\n\n" + body
 		}
+		if err := checkBeadSchema(cfg, beads.Issue{Type: "task", Priority: "p2", Description: desc}); err != nil {
+			return err
+		}
 		issue, err := client.Create(nil, beads.CreateRequest{
 			Title:       title,
 			Type:        "task",
@@ -100,7 +104,7 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issue, err := client.Show(nil, taskID)
 		if err != nil {
 			return fmt.Errorf("showing task %s: %w", taskID, err)
@@ -117,16 +121,24 @@ func Task(home string, args []string) error {
 		if body := strings.TrimSpace(beads.StripMeta(issue.Description)); body != "" {
 			desc += "\n\n" + body
 		}
-		updated, err := client.UpdateDescription(nil, issue.ID, desc)
+		if err := checkBeadSchema(cfg, beads.Issue{ID: issue.ID, Type: issue.Type, Priority: issue.Priority, Description: desc}); err != nil {
+			return err
+		}
+		err = client.Require(nil, beads.CapUpdateDescription)
 		if err == nil {
+			updated, err := client.UpdateDescription(nil, issue.ID, desc)
+			if err != nil {
+				return fmt.Errorf("updating task %s description: %w", taskID, err)
+			}
 			fmt.Printf("Updated task %s\n", updated.ID)
 			return nil
 		}
 		if !errors.Is(err, beads.ErrUpdateDescriptionUnsupported) {
 			return fmt.Errorf("updating task %s description: %w", taskID, err)
 		}
+		// This bd cannot rewrite descriptions; supersede the task instead.
 		deps := append([]string{}, issue.Deps...)
-		if !hasDepPrefix(deps, "related:"+issue.ID) {
+		if !beads.HasDep(deps, beads.EdgeRelated, issue.ID) {
 			deps = append(deps, "related:"+issue.ID)
 		}
 		replacement, err := client.Create(nil, beads.CreateRequest{
@@ -140,20 +152,28 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("creating replacement task: %w", err)
 		}
-		_, _ = client.Close(nil, issue.ID, "superseded by "+replacement.ID)
+		client.TryClose(nil, issue.ID, "superseded by "+replacement.ID)
 		fmt.Printf("Updated task %s -> %s\n", issue.ID, replacement.ID)
 		return nil
 
 	case "list":
-		if len(rest) != 1 {
-			return fmt.Errorf("usage: mforge task list <rig>")
+		if len(rest) < 1 {
+			return fmt.Errorf("usage: mforge task list <rig> " + listQueryUsage)
 		}
 		rigName := rest[0]
+		var query listQuery
+		for i := 1; i < len(rest); i++ {
+			next, err := query.consume(rest, i)
+			if err != nil {
+				return fmt.Errorf("%w (usage: mforge task list <rig> %s)", err, listQueryUsage)
+			}
+			i = next
+		}
 		cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		tasks, err := client.List(nil)
 		if err != nil {
 			return fmt.Errorf("listing tasks: %w", err)
@@ -166,16 +186,24 @@ func Task(home string, args []string) error {
 			if issue.Status == "closed" || issue.Status == "done" {
 				continue
 			}
-			for _, dep := range issue.Deps {
-				if strings.HasPrefix(dep, "related:") {
-					activeAssignments[strings.TrimPrefix(dep, "related:")] = true
-				}
+			for _, taskID := range beads.DepTargets(issue.Deps, beads.EdgeRelated) {
+				activeAssignments[taskID] = true
 			}
 		}
+		listed := make([]beads.Issue, 0, len(tasks))
 		for _, t := range tasks {
-			if strings.ToLower(t.Type) != "task" {
-				continue
+			if strings.ToLower(t.Type) == "task" {
+				listed = append(listed, t)
 			}
+		}
+		listed, err = query.apply(listed)
+		if err != nil {
+			return err
+		}
+		if query.custom() {
+			return query.print(os.Stdout, listed)
+		}
+		for _, t := range listed {
 			meta := beads.ParseMeta(t.Description)
 			scope := meta.Scope
 			kind := meta.Kind
@@ -217,7 +245,7 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		parent, err := client.Show(nil, taskID)
 		if err != nil {
 			return fmt.Errorf("showing parent task %s: %w", taskID, err)
@@ -288,7 +316,7 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issue, err := client.Show(nil, taskID)
 		if err != nil {
 			return fmt.Errorf("showing task %s: %w", taskID, err)
@@ -347,7 +375,7 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issue, err := client.Show(nil, taskID)
 		if err != nil {
 			return fmt.Errorf("showing task %s: %w", taskID, err)
@@ -358,6 +386,7 @@ func Task(home string, args []string) error {
 		updated, err := client.CloseWithOptions(nil, issue.ID, beads.CloseOptions{
 			Force:  force,
 			Reason: reason,
+			From:   issue.Status,
 		})
 		if err != nil {
 			return fmt.Errorf("completing task %s: %w", taskID, err)
@@ -365,7 +394,7 @@ func Task(home string, args []string) error {
 		meta := beads.ParseMeta(issue.Description)
 		meta.Kind = "task_complete"
 		meta.Title = issue.Title
-		emitOrchestrationEvent(cfg.RepoPath, meta, fmt.Sprintf("Task completed %s", updated.ID), []string{"related:" + updated.ID})
+		emitOrchestrationEvent(client, meta, fmt.Sprintf("Task completed %s", updated.ID), []string{"related:" + updated.ID})
 		fmt.Printf("Completed task %s\n", updated.ID)
 		return nil
 
@@ -411,7 +440,7 @@ func Task(home string, args []string) error {
 		if err != nil {
 			return fmt.Errorf("loading rig %s: %w", rigName, err)
 		}
-		client := beads.Client{RepoPath: cfg.RepoPath}
+		client := beadsClient(home, cfg)
 		issue, err := client.Show(nil, taskID)
 		if err != nil {
 			return fmt.Errorf("showing task %s: %w", taskID, err)
@@ -453,12 +482,3 @@ func yesNo(v bool) string {
 	}
 	return "no"
 }
-
-func hasDepPrefix(deps []string, needle string) bool {
-	for _, dep := range deps {
-		if dep == needle {
-			return true
-		}
-	}
-	return false
-}
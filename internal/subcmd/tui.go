@@ -59,12 +59,15 @@ type tuiModel struct {
 	followAll  bool
 	watchMode  bool
 	watchRole  string
+	query      *beads.Query
+	beads      []beads.Issue
 }
 
 type dataMsg struct {
 	rows  []agentRow
 	stats roundStats
 	turn  turn.State
+	beads []beads.Issue
 	err   error
 	when  time.Time
 }
@@ -85,6 +88,7 @@ func TUI(home string, args []string) error {
 	rigName := ""
 	watchMode := false
 	watchRole := ""
+	queryExpr := ""
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--interval":
@@ -103,6 +107,11 @@ func TUI(home string, args []string) error {
 				watchRole = args[i+1]
 				i++
 			}
+		case "--query", "--where":
+			if i+1 < len(args) {
+				queryExpr = args[i+1]
+				i++
+			}
 		default:
 			if !strings.HasPrefix(args[i], "-") && rigName == "" {
 				rigName = args[i]
@@ -110,7 +119,11 @@ func TUI(home string, args []string) error {
 		}
 	}
 	if strings.TrimSpace(rigName) == "" {
-		return fmt.Errorf("usage: mforge tui [--interval <seconds>] [--remote]")
+		return fmt.Errorf("usage: mforge tui [--interval <seconds>] [--remote] [--query <expr>]")
+	}
+	query, err := beads.ParseQuery(queryExpr)
+	if err != nil {
+		return err
 	}
 	model := tuiModel{
 		home:      home,
@@ -121,14 +134,15 @@ func TUI(home string, args []string) error {
 		selected:  0,
 		watchMode: watchMode,
 		watchRole: watchRole,
+		query:     query,
 	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	_, err := p.Run()
+	_, err = p.Run()
 	return err
 }
 
 func (m tuiModel) Init() tea.Cmd {
-	cmds := []tea.Cmd{loadDataCmd(m.home, m.rigName, m.remote), tickCmd(m.interval), loadLogsCmd(m.home, m.rigName, nil)}
+	cmds := []tea.Cmd{loadDataCmd(m.home, m.rigName, m.remote, m.query), tickCmd(m.interval), loadLogsCmd(m.home, m.rigName, nil)}
 	if m.watchMode {
 		cmds = append(cmds, watchCmd(m.home, m.rigName, m.watchRole))
 	}
@@ -176,14 +190,14 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, loadLogsCmd(m.home, m.rigName, m)
 			}
 		case "r":
-			return m, tea.Batch(loadDataCmd(m.home, m.rigName, m.remote), loadLogsCmd(m.home, m.rigName, m))
+			return m, tea.Batch(loadDataCmd(m.home, m.rigName, m.remote, m.query), loadLogsCmd(m.home, m.rigName, m))
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 	case tickMsg:
-		cmds := []tea.Cmd{loadDataCmd(m.home, m.rigName, m.remote), loadLogsCmd(m.home, m.rigName, m), tickCmd(m.interval)}
+		cmds := []tea.Cmd{loadDataCmd(m.home, m.rigName, m.remote, m.query), loadLogsCmd(m.home, m.rigName, m), tickCmd(m.interval)}
 		if m.watchMode {
 			cmds = append(cmds, watchCmd(m.home, m.rigName, m.watchRole))
 		}
@@ -196,6 +210,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.rows = msg.rows
 			m.stats = msg.stats
 			m.turn = msg.turn
+			m.beads = msg.beads
 			m.lastUpdated = msg.when
 			if m.selected >= len(m.rows) {
 				m.selected = maxInt(0, len(m.rows)-1)
@@ -247,7 +262,7 @@ func (m tuiModel) View() string {
 	case 1:
 		body = renderTurnRound(m.turn, m.stats)
 	case 2:
-		body = renderBeadsSummary(m.stats)
+		body = renderBeadsSummary(m.stats, m.beads)
 	case 3:
 		body = renderLogsWithMode(m.rows, m.selected, m.logLines, m.logErr, m.logUpdated, m.followAll)
 	}
@@ -315,15 +330,27 @@ func renderTurnRound(state turn.State, stats roundStats) string {
 	return strings.Join(lines, "\n")
 }
 
-func renderBeadsSummary(stats roundStats) string {
-	return strings.Join([]string{
+func renderBeadsSummary(stats roundStats, matched []beads.Issue) string {
+	lines := []string{
 		"Open work:",
 		fmt.Sprintf("- tasks open: %d", stats.TasksOpen),
 		fmt.Sprintf("- tasks in_progress: %d", stats.TasksInProgress),
 		fmt.Sprintf("- assignments open: %d", stats.AssignmentsOpen),
 		fmt.Sprintf("- assignments in_progress: %d", stats.AssignmentsIP),
 		fmt.Sprintf("- reviews open: %d", stats.ReviewsOpen),
-	}, "\n")
+	}
+	if matched == nil {
+		return strings.Join(lines, "\n")
+	}
+	lines = append(lines, "", fmt.Sprintf("Query matches: %d", len(matched)))
+	table := [][]string{{"id", "type", "status", "priority", "title"}}
+	for i, issue := range matched {
+		if i >= 20 {
+			break
+		}
+		table = append(table, []string{issue.ID, issue.Type, issue.Status, issue.Priority, issue.Title})
+	}
+	return strings.Join(lines, "\n") + "\n" + renderTable(table)
 }
 
 func renderLogs(rows []agentRow, selected int, lines []string, logErr string, updated time.Time) string {
@@ -395,13 +422,14 @@ func tickCmd(interval time.Duration) tea.Cmd {
 	})
 }
 
-func loadDataCmd(home, rigName string, remote bool) tea.Cmd {
+func loadDataCmd(home, rigName string, remote bool, query *beads.Query) tea.Cmd {
 	return func() tea.Msg {
-		rows, stats, state, err := loadTUIData(home, rigName, remote)
+		rows, stats, state, matched, err := loadTUIData(home, rigName, remote, query)
 		return dataMsg{
 			rows:  rows,
 			stats: stats,
 			turn:  state,
+			beads: matched,
 			err:   err,
 			when:  time.Now(),
 		}
@@ -434,28 +462,33 @@ func loadLogsCmd(home, rigName string, model any) tea.Cmd {
 	}
 }
 
-func loadTUIData(home, rigName string, remote bool) ([]agentRow, roundStats, turn.State, error) {
+func loadTUIData(home, rigName string, remote bool, query *beads.Query) ([]agentRow, roundStats, turn.State, []beads.Issue, error) {
 	cfg, err := rig.LoadRigConfig(rig.RigConfigPath(home, rigName))
 	if err != nil {
-		return nil, roundStats{}, turn.State{}, err
+		return nil, roundStats{}, turn.State{}, nil, err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
+	client.Invalidate()
 	issues, err := client.List(nil)
 	if err != nil {
-		return nil, roundStats{}, turn.State{}, err
+		return nil, roundStats{}, turn.State{}, nil, err
 	}
 	cells, err := rig.ListCellConfigs(home, rigName)
 	if err != nil {
-		return nil, roundStats{}, turn.State{}, err
+		return nil, roundStats{}, turn.State{}, nil, err
 	}
 	rows := collectAgentRows(home, rigName, cfg, cells, remote)
 	stats := collectRoundStats(issues)
 	state, _ := turn.Load(rig.TurnStatePath(home, rigName))
-	return rows, stats, state, nil
+	var matched []beads.Issue
+	if query != nil && query.Active() {
+		matched = query.Filter(issues)
+	}
+	return rows, stats, state, matched, nil
 }
 
 func collectAgentRows(home, rigName string, cfg rig.RigConfig, cells []rig.CellConfig, remote bool) []agentRow {
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	roles := cfg.RoleNames()
 	rows := make([]agentRow, 0)
 	for _, cell := range cells {
 		for _, role := range roles {
@@ -27,7 +27,7 @@ func Turn(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	statePath := rig.TurnStatePath(home, rigName)
 	switch op {
 	case "start":
@@ -129,7 +129,7 @@ func closeActiveTurn(home, rigName string, cfg rig.RigConfig, client beads.Clien
 		return err
 	}
 	if strings.TrimSpace(state.ID) != "" {
-		_, _ = client.Close(nil, state.ID, "turn complete")
+		client.TryClose(nil, state.ID, "turn complete")
 	}
 	ended := time.Now().UTC().Format(time.RFC3339)
 	rec := turn.Record{ID: state.ID, Name: state.Name, StartedAt: state.StartedAt, EndedAt: ended}
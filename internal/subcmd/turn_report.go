@@ -13,7 +13,7 @@ import (
 )
 
 func computeTurnSummary(home, rigName string, cfg rig.RigConfig, state turn.State) (turn.Summary, error) {
-	return turn.Compute(nil, home, rigName, cfg, state)
+	return turn.Compute(nil, home, rigName, cfg, beadsClient(home, cfg), state)
 }
 
 func printTurnSummary(summary turn.Summary) {
@@ -35,6 +35,9 @@ func printTurnSummary(summary turn.Summary) {
 	fmt.Printf("  Commits: %d (+%d / -%d)\n", summary.Commits, summary.Added, summary.Removed)
 	fmt.Printf("  Tasks: %d completed, %d open\n", summary.TasksDone, summary.TasksOpen)
 	fmt.Printf("  Reviews: %d\n", summary.Reviews)
+	if summary.CycleTime > 0 || summary.BlockedTime > 0 {
+		fmt.Printf("  Cycle time: %s avg, %s blocked\n", humanDuration(summary.CycleTime), humanDuration(summary.BlockedTime))
+	}
 	if len(summary.Cells) > 0 {
 		fmt.Printf("\nBy Cell:\n")
 		cells := make([]string, 0, len(summary.Cells))
@@ -63,6 +66,9 @@ func writeTurnReport(repo string, summary turn.Summary) (string, error) {
 	fmt.Fprintf(buf, "**Commits**: %d (+%d / -%d)\n\n", summary.Commits, summary.Added, summary.Removed)
 	fmt.Fprintf(buf, "**Tasks**: %d completed, %d open\n\n", summary.TasksDone, summary.TasksOpen)
 	fmt.Fprintf(buf, "**Reviews**: %d\n\n", summary.Reviews)
+	if summary.CycleTime > 0 || summary.BlockedTime > 0 {
+		fmt.Fprintf(buf, "**Cycle time**: %s avg, %s blocked\n\n", summary.CycleTime.Round(time.Second), summary.BlockedTime.Round(time.Second))
+	}
 	if len(summary.Cells) > 0 {
 		fmt.Fprintf(buf, "## Cells\n")
 		cells := make([]string, 0, len(summary.Cells))
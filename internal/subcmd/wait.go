@@ -5,7 +5,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/example/microforge/internal/beads"
 	"github.com/example/microforge/internal/rig"
 	"github.com/example/microforge/internal/turn"
 )
@@ -42,18 +41,16 @@ func Wait(home string, args []string) error {
 	if err != nil {
 		return err
 	}
-	client := beads.Client{RepoPath: cfg.RepoPath}
+	client := beadsClient(home, cfg)
 	for {
-		issues, err := client.List(nil)
+		client.Invalidate()
+		snap, err := client.Snapshot(nil)
 		if err != nil {
 			return err
 		}
 		open := 0
-		for _, issue := range issues {
-			if strings.ToLower(issue.Type) != "assignment" {
-				continue
-			}
-			meta := beads.ParseMeta(issue.Description)
+		for _, issue := range snap.ByType("assignment") {
+			meta := snap.Meta(issue.ID)
 			if turnID != "" && meta.TurnID != "" && meta.TurnID != turnID {
 				continue
 			}
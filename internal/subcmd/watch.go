@@ -75,7 +75,7 @@ func watchOnce(home, rigName, role string) error {
 	if err != nil {
 		return err
 	}
-	roles := []string{"builder", "monitor", "reviewer", "architect", "cell"}
+	roles := cfg.RoleNames()
 	if strings.TrimSpace(role) != "" {
 		roles = []string{role}
 	}
@@ -24,11 +24,16 @@ type Summary struct {
 	TasksDone int
 	TasksOpen int
 	Reviews   int
-	Cells     map[string]int
-	Recent    []string
+	// CycleTime is the mean time from in_progress to closed over tasks
+	// closed during the turn; BlockedTime is the total time those and the
+	// turn's open tasks spent blocked. Both need recorded status history.
+	CycleTime   time.Duration
+	BlockedTime time.Duration
+	Cells       map[string]int
+	Recent      []string
 }
 
-func Compute(ctx context.Context, home, rigName string, cfg rig.RigConfig, state State) (Summary, error) {
+func Compute(ctx context.Context, home, rigName string, cfg rig.RigConfig, client beads.Client, state State) (Summary, error) {
 	start := parseRFC3339(state.StartedAt)
 	end := time.Now().UTC()
 	if state.EndedAt != "" {
@@ -47,7 +52,7 @@ func Compute(ctx context.Context, home, rigName string, cfg rig.RigConfig, state
 	if err := fillGitSummary(ctx, cfg.RepoPath, &summary, start, end); err != nil {
 		return summary, err
 	}
-	if err := fillBeadsSummary(ctx, cfg.RepoPath, &summary, start, end); err != nil {
+	if err := fillBeadsSummary(ctx, client, &summary, start, end); err != nil {
 		return summary, err
 	}
 	if err := fillCellSummary(ctx, home, rigName, cfg.RepoPath, &summary, start, end); err != nil {
@@ -82,18 +87,36 @@ func fillGitSummary(ctx context.Context, repo string, summary *Summary, start, e
 	return nil
 }
 
-func fillBeadsSummary(ctx context.Context, repo string, summary *Summary, start, end time.Time) error {
-	client := beads.Client{RepoPath: repo}
+func fillBeadsSummary(ctx context.Context, client beads.Client, summary *Summary, start, end time.Time) error {
 	issues, err := client.List(ctx)
 	if err != nil {
 		return err
 	}
+	// Beads without recorded history fall back to their creation time.
+	history, _ := client.Histories(ctx)
+	within := func(t time.Time) bool { return !t.IsZero() && !t.Before(start) && !t.After(end) }
+	var cycle time.Duration
+	cycled := 0
 	for _, issue := range issues {
 		created := parseRFC3339(issue.CreatedAt)
-		if created.IsZero() {
+		transitions := history[issue.ID]
+		if strings.ToLower(issue.Type) == "task" && len(transitions) > 0 {
+			tl := beads.NewTimeline(issue, transitions, end)
+			switch {
+			case beads.IsClosed(issue.Status) && within(tl.Closed):
+				summary.TasksDone++
+				if c := tl.Cycle(); c > 0 {
+					cycle += c
+					cycled++
+				}
+				summary.BlockedTime += tl.Blocked
+			case !beads.IsClosed(issue.Status) && within(tl.Created):
+				summary.TasksOpen++
+				summary.BlockedTime += tl.Blocked
+			}
 			continue
 		}
-		if created.Before(start) || created.After(end) {
+		if !within(created) {
 			continue
 		}
 		switch strings.ToLower(issue.Type) {
@@ -107,6 +130,9 @@ func fillBeadsSummary(ctx context.Context, repo string, summary *Summary, start,
 			summary.Reviews++
 		}
 	}
+	if cycled > 0 {
+		summary.CycleTime = cycle / time.Duration(cycled)
+	}
 	return nil
 }
 
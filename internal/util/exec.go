@@ -4,8 +4,13 @@ package util
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,28 +18,44 @@ import (
 // CmdResult holds the captured stdout and stderr from a command execution.
 type CmdResult struct{ Stdout, Stderr string }
 
+// Error kinds reported by ExecError. Match them with errors.Is.
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrLockContention = errors.New("lock contention")
+	ErrTimeout        = errors.New("timed out")
+	ErrUnsupported    = errors.New("unsupported")
+)
+
+// ExecError is returned when a command fails. Kind is one of the sentinels
+// above, or nil when the failure could not be classified.
+type ExecError struct {
+	Name     string
+	Args     []string
+	Stderr   string
+	ExitCode int
+	Kind     error
+	Err      error
+}
+
+func (e *ExecError) Error() string {
+	trim := strings.TrimSpace(e.Stderr)
+	if trim == "" {
+		trim = e.Err.Error()
+	}
+	return fmt.Sprintf("%s %s: %s", e.Name, strings.Join(e.Args, " "), trim)
+}
+
+func (e *ExecError) Unwrap() error { return e.Err }
+
+// Is matches the error's Kind so callers can test errors.Is(err, ErrTimeout).
+func (e *ExecError) Is(target error) bool {
+	return e.Kind != nil && target == e.Kind
+}
+
 // Run executes a command and captures its output. If ctx is nil, a 30-second timeout is used.
-// Returns an error containing the command, args, and stderr on failure.
+// Failures are returned as *ExecError.
 func Run(ctx context.Context, name string, args ...string) (CmdResult, error) {
-	if ctx == nil {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-	}
-	cmd := exec.CommandContext(ctx, name, args...)
-	var outb, errb bytes.Buffer
-	cmd.Stdout = &outb
-	cmd.Stderr = &errb
-	err := cmd.Run()
-	res := CmdResult{Stdout: outb.String(), Stderr: errb.String()}
-	if err != nil {
-		trim := strings.TrimSpace(res.Stderr)
-		if trim == "" {
-			trim = err.Error()
-		}
-		return res, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), trim)
-	}
-	return res, nil
+	return RunInDir(ctx, "", name, args...)
 }
 
 // RunInDir executes a command in the specified directory and captures its output.
@@ -53,11 +74,127 @@ func RunInDir(ctx context.Context, dir string, name string, args ...string) (Cmd
 	err := cmd.Run()
 	res := CmdResult{Stdout: outb.String(), Stderr: errb.String()}
 	if err != nil {
-		trim := strings.TrimSpace(res.Stderr)
-		if trim == "" {
-			trim = err.Error()
+		execErr := &ExecError{Name: name, Args: args, Stderr: res.Stderr, ExitCode: -1, Err: err}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			execErr.ExitCode = exitErr.ExitCode()
 		}
-		return res, fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), trim)
+		execErr.Kind = classify(ctx, err, res.Stderr)
+		return res, execErr
 	}
 	return res, nil
 }
+
+// lockMarkers are stderr fragments that mean another process holds the
+// store; the command did not run and is safe to repeat.
+var lockMarkers = []string{"database is locked", "sqlite_busy", "resource temporarily unavailable", "lock held", "locked by another", "could not acquire lock"}
+
+// notFoundPattern matches stderr reporting a missing issue or bead, not any
+// "not found" (a missing binary or config file is not a missing record).
+var notFoundPattern = regexp.MustCompile(`\b(issue|bead)\b[^\n]*\b(not found|does not exist)\b|\bno (issue|bead)s? (found|with|matching)\b`)
+
+func classify(ctx context.Context, err error, stderr string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrUnsupported
+	}
+	low := strings.ToLower(stderr)
+	for _, m := range lockMarkers {
+		if strings.Contains(low, m) {
+			return ErrLockContention
+		}
+	}
+	switch {
+	case strings.Contains(low, "unknown flag"), strings.Contains(low, "unknown command"), strings.Contains(low, "unknown shorthand flag"):
+		return ErrUnsupported
+	case notFoundPattern.MatchString(low):
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RetryPolicy controls RunRetry. Attempts counts the first try; the wait
+// before retry n is a random duration up to min(Max, Base*2^n).
+type RetryPolicy struct {
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+}
+
+// DefaultRetry returns the policy used for idempotent commands.
+// MF_EXEC_RETRIES overrides the attempt count and MF_EXEC_BACKOFF the base
+// delay (a Go duration, e.g. "200ms").
+func DefaultRetry() RetryPolicy {
+	p := RetryPolicy{Attempts: 4, Base: 100 * time.Millisecond, Max: 2 * time.Second}
+	if v := strings.TrimSpace(os.Getenv("MF_EXEC_RETRIES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			p.Attempts = n
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("MF_EXEC_BACKOFF")); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			p.Base = d
+			if p.Max < d {
+				p.Max = d
+			}
+		}
+	}
+	return p
+}
+
+// Retryable reports whether a failed command may be repeated: the store was
+// locked, or a single attempt timed out.
+func Retryable(err error) bool {
+	return errors.Is(err, ErrLockContention) || errors.Is(err, ErrTimeout)
+}
+
+// RunRetry runs an idempotent command under policy, retrying Retryable
+// failures with jittered exponential backoff. Each attempt gets its own
+// 30-second timeout when ctx is nil; a done ctx stops retrying.
+func RunRetry(ctx context.Context, policy RetryPolicy, dir string, name string, args ...string) (CmdResult, error) {
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var res CmdResult
+	var err error
+	for n := 0; n < attempts; n++ {
+		if n > 0 {
+			wait := policy.Backoff(n)
+			if ctx != nil {
+				select {
+				case <-ctx.Done():
+					return res, err
+				case <-time.After(wait):
+				}
+			} else {
+				time.Sleep(wait)
+			}
+		}
+		res, err = RunInDir(ctx, dir, name, args...)
+		if err == nil || !Retryable(err) {
+			return res, err
+		}
+		if ctx != nil && ctx.Err() != nil {
+			return res, err
+		}
+	}
+	return res, err
+}
+
+// Backoff returns the jittered wait before retry n (n >= 1).
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	if p.Base <= 0 {
+		return 0
+	}
+	ceiling := p.Base << uint(n-1)
+	if ceiling <= 0 || (p.Max > 0 && ceiling > p.Max) {
+		ceiling = p.Max
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling))) + time.Millisecond
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunInDirClassifiesFailures(t *testing.T) {
+	cases := []struct {
+		script string
+		kind   error
+	}{
+		{"echo 'Error: database is locked' >&2; exit 1", ErrLockContention},
+		{"echo 'Error: unknown flag: --description' >&2; exit 1", ErrUnsupported},
+		{"echo 'Error: issue bd-9 not found' >&2; exit 1", ErrNotFound},
+		{"echo 'Error: no issue found matching bd-9' >&2; exit 1", ErrNotFound},
+		{"echo 'sh: 1: jq: not found' >&2; exit 1", nil},
+		{"echo 'Error: config file not found' >&2; exit 1", nil},
+	}
+	for _, tc := range cases {
+		_, err := RunInDir(nil, t.TempDir(), "sh", "-c", tc.script)
+		var execErr *ExecError
+		if !errors.As(err, &execErr) || execErr.ExitCode != 1 {
+			t.Fatalf("%q: expected ExecError with exit 1, got %#v", tc.script, err)
+		}
+		if execErr.Kind != tc.kind {
+			t.Fatalf("%q: expected %v, got %v", tc.script, tc.kind, err)
+		}
+	}
+	if _, err := Run(nil, "mforge-no-such-binary"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected missing binary to be unsupported, got %v", err)
+	}
+}
+
+func TestRunRetryStopsOnNonRetryable(t *testing.T) {
+	dir := t.TempDir()
+	policy := RetryPolicy{Attempts: 3, Base: time.Millisecond, Max: 2 * time.Millisecond}
+	script := "echo x >> attempts; echo 'database is locked' >&2; exit 1"
+	if _, err := RunRetry(nil, policy, dir, "sh", "-c", script); !errors.Is(err, ErrLockContention) {
+		t.Fatalf("expected lock contention, got %v", err)
+	}
+	res, _ := RunInDir(nil, dir, "sh", "-c", "wc -l < attempts")
+	if got := strings.TrimSpace(res.Stdout); got != "3" {
+		t.Fatalf("expected 3 attempts, got %q", got)
+	}
+	if _, err := RunRetry(nil, policy, dir, "sh", "-c", "echo x >> once; exit 2"); err == nil || Retryable(err) {
+		t.Fatalf("expected a non-retryable failure, got %v", err)
+	}
+	res, _ = RunInDir(nil, dir, "sh", "-c", "wc -l < once")
+	if got := strings.TrimSpace(res.Stdout); got != "1" {
+		t.Fatalf("expected 1 attempt, got %q", got)
+	}
+}
@@ -9,6 +9,25 @@ import (
 // EnsureDir creates the directory and all parent directories if they don't exist.
 func EnsureDir(path string) error { return os.MkdirAll(path, 0o755) }
 
+// RotateFile renames path to path+".1", replacing any previous generation,
+// once path has grown to limit bytes. The size is rechecked under a lock so
+// concurrent writers rotate at most once. Append-only logs call it before
+// each write to stay under twice limit on disk.
+func RotateFile(path string, limit int64) error {
+	if info, err := os.Stat(path); err != nil || info.Size() < limit {
+		return nil
+	}
+	unlock, err := LockFile(nil, path+".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if info, err := os.Stat(path); err != nil || info.Size() < limit {
+		return nil
+	}
+	return os.Rename(path, path+".1")
+}
+
 // AtomicWriteFile writes data to a temporary file and atomically renames it to path.
 // This ensures the file is either fully written or not modified at all.
 func AtomicWriteFile(path string, data []byte, perm os.FileMode) error {
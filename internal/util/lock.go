@@ -0,0 +1,88 @@
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// staleLockAge is how old a lock file may get before another process assumes
+// its holder died and takes it over.
+const staleLockAge = 30 * time.Second
+
+// LockFile takes an exclusive advisory lock by creating path with O_EXCL,
+// retrying until ctx is done. The returned func releases the lock.
+// If ctx is nil, a 10-second timeout is used.
+func LockFile(ctx context.Context, path string) (func(), error) {
+	if ctx == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+	}
+	token := lockToken()
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, _ = fmt.Fprintln(f, token)
+			_ = f.Close()
+			return func() { releaseLock(path, token) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("creating lock %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			takeOverLock(path, stillStale)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for lock %s: %w", path, ctx.Err())
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+// lockToken identifies one LockFile call in the lock's contents, so a holder
+// whose lock was taken over as stale does not release its successor's.
+func lockToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%d %s", os.Getpid(), hex.EncodeToString(b))
+}
+
+// stillStale approves taking over a lock that is stale once moved aside.
+// A fresh holder's lock, created after the caller saw the stale one, keeps
+// its recent mtime across the rename and is put back.
+func stillStale(aside os.FileInfo, _ string) bool {
+	return time.Since(aside.ModTime()) > staleLockAge
+}
+
+func releaseLock(path, token string) {
+	takeOverLock(path, func(_ os.FileInfo, contents string) bool {
+		return strings.TrimSpace(contents) == token
+	})
+}
+
+// takeOverLock renames the lock at path to a name private to this call, so
+// of several processes racing for it only one moves a given file. The file is
+// removed if ours approves it; a lock taken since the caller looked (a fresh
+// holder's) is linked back in place, unless yet another lock already took it.
+func takeOverLock(path string, ours func(aside os.FileInfo, contents string) bool) {
+	aside := fmt.Sprintf("%s.%s.takeover", path, strings.ReplaceAll(lockToken(), " ", "-"))
+	if err := os.Rename(path, aside); err != nil {
+		return
+	}
+	defer os.Remove(aside)
+	info, err := os.Stat(aside)
+	if err != nil {
+		return
+	}
+	b, _ := os.ReadFile(aside)
+	if !ours(info, string(b)) {
+		_ = os.Link(aside, path)
+	}
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLockFileTakesOverStaleLockOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	if err := os.WriteFile(path, []byte("1 dead\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := LockFile(nil, path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+	if maxActive != 1 {
+		t.Fatalf("expected one holder at a time, saw %d", maxActive)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock released, got %v", err)
+	}
+}
+
+func TestLockFileReleaseKeepsSuccessorsLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	unlock, err := LockFile(nil, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another process took the lock over as stale and holds it now.
+	if err := os.WriteFile(path, []byte("2 successor\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if b, err := os.ReadFile(path); err != nil || string(b) != "2 successor\n" {
+		t.Fatalf("expected the successor's lock kept, got %q (%v)", b, err)
+	}
+}
+
+func TestLockTakeoverPutsBackFreshLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	// A fresh holder replaced the stale lock after the racers below saw it.
+	if err := os.WriteFile(path, []byte("2 fresh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			takeOverLock(path, stillStale)
+		}()
+	}
+	wg.Wait()
+	if b, err := os.ReadFile(path); err != nil || string(b) != "2 fresh\n" {
+		t.Fatalf("expected the fresh lock put back, got %q (%v)", b, err)
+	}
+	if matches, _ := filepath.Glob(path + ".*.takeover"); len(matches) != 0 {
+		t.Fatalf("expected no takeover files left, got %v", matches)
+	}
+}